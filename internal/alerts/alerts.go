@@ -0,0 +1,344 @@
+// Package alerts tells users when a newly saved post matches one of their
+// alert patterns, by printing to stdout, appending to a file, running a
+// local command or sending email. Alerts are rate limited, and a burst of
+// matches is folded into a single digest.
+package alerts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/filters"
+	"github.com/sanntintdev/gator/internal/mail"
+)
+
+const (
+	SinkStdout  = "stdout"
+	SinkFile    = "file"
+	SinkCommand = "command"
+	SinkSMTP    = "smtp"
+
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+
+	// MaxAttempts is how many times a notification is tried before its
+	// events are marked failed.
+	MaxAttempts = 5
+
+	// RateWindow is the period an alert's max_per_hour applies to.
+	RateWindow = time.Hour
+
+	// MaxDigestPosts caps the posts in one digest. Larger bursts are split
+	// over several digests, each counting against the rate limit.
+	MaxDigestPosts = 50
+
+	commandTimeout = 30 * time.Second
+)
+
+var Sinks = []string{SinkStdout, SinkFile, SinkCommand, SinkSMTP}
+
+// Policy limits the command and file sinks, which run commands and write
+// files as whoever runs agg. Alert targets are stored in the database by
+// any user, so both sinks are refused unless the config file opts in.
+type Policy struct {
+	// Commands lists the exact command lines the command sink may run.
+	Commands []string
+	// FileDir is the directory file sinks must write inside.
+	FileDir string
+}
+
+// Check reports why sink may not be used with target, or nil if it may.
+func (p Policy) Check(sink, target string) error {
+	switch sink {
+	case SinkCommand:
+		if !slices.Contains(p.Commands, target) {
+			return fmt.Errorf("command %q is not listed in alert_commands in the config file", target)
+		}
+	case SinkFile:
+		if p.FileDir == "" {
+			return errors.New("file alerts are disabled; set alert_file_dir in the config file")
+		}
+		if !inDir(p.FileDir, target) {
+			return fmt.Errorf("%s is outside alert_file_dir %s", target, p.FileDir)
+		}
+	}
+	return nil
+}
+
+// inDir reports whether path is inside dir once both are made absolute
+// and any symlinks in dir and path's parent directory are resolved.
+func inDir(dir, path string) bool {
+	resolve := func(p string) string {
+		p, err := filepath.Abs(p)
+		if err != nil {
+			return ""
+		}
+		if real, err := filepath.EvalSymlinks(p); err == nil {
+			return real
+		}
+		return p
+	}
+	dir = resolve(dir)
+	path = filepath.Join(resolve(filepath.Dir(path)), filepath.Base(path))
+	if dir == "" || !filepath.IsAbs(path) {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Compile parses an alert pattern. Patterns use the filter expression
+// syntax, so a plain word alerts on posts mentioning it anywhere.
+func Compile(pattern string) (filters.Matcher, error) {
+	return filters.Compile(filters.FieldAny, filters.MatchExpr, pattern)
+}
+
+// Enqueue records a pending event for every alert matching a newly saved
+// post. It returns the number of events recorded.
+func Enqueue(ctx context.Context, db database.Querier, feed database.Feed, post filters.Post) (int, error) {
+	alerts, err := db.RetrieveAlertsForFeed(ctx, sql.NullInt32{Int32: feed.ID, Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve alerts: %w", err)
+	}
+
+	queued := 0
+	for _, alert := range alerts {
+		// As with filter rules, an alert whose pattern no longer parses
+		// is skipped rather than failing the post.
+		m, err := Compile(alert.Pattern)
+		if err != nil || !m.Match(post) {
+			continue
+		}
+		err = db.CreateAlertEvent(ctx, database.CreateAlertEventParams{
+			AlertID: alert.ID,
+			PostID:  post.ID,
+		})
+		if err != nil {
+			return queued, fmt.Errorf("failed to queue alert %d: %w", alert.ID, err)
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// Notification is one message sent to a sink: a single post, or a digest
+// of several.
+type Notification struct {
+	Alert   database.Alert
+	Subject string
+	Text    string
+	Posts   []database.RetrievePendingAlertEventsRow
+}
+
+func newNotification(alert database.Alert, posts []database.RetrievePendingAlertEventsRow) Notification {
+	n := Notification{Alert: alert, Posts: posts}
+	if len(posts) == 1 {
+		p := posts[0]
+		n.Subject = fmt.Sprintf("[gator] %q: %s", alert.Pattern, p.Title)
+		n.Text = fmt.Sprintf("%s\n%s\nFeed: %s\n", p.Title, p.Url, p.FeedName)
+		if p.PublishedAt.Valid {
+			n.Text += fmt.Sprintf("Published: %s\n", p.PublishedAt.Time.Format(time.RFC1123))
+		}
+		return n
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d new posts match %q:\n\n", len(posts), alert.Pattern)
+	for _, p := range posts {
+		fmt.Fprintf(&b, "- %s (%s)\n  %s\n", p.Title, p.FeedName, p.Url)
+	}
+	n.Subject = fmt.Sprintf("[gator] %q: %d new posts", alert.Pattern, len(posts))
+	n.Text = b.String()
+	return n
+}
+
+type Notifier struct {
+	db     database.Querier
+	mail   mail.Sender
+	out    io.Writer
+	policy Policy
+	now    func() time.Time
+}
+
+// NewNotifier returns a notifier that prints stdout alerts to out, sends
+// email alerts through sender and only uses the command and file sinks
+// as policy allows.
+func NewNotifier(db database.Querier, sender mail.Sender, out io.Writer, policy Policy) *Notifier {
+	return &Notifier{db: db, mail: sender, out: out, policy: policy, now: time.Now}
+}
+
+// DeliverPending sends the pending events of every alert, within each
+// alert's rate limit. It returns how many events were sent and how many
+// failed.
+func (n *Notifier) DeliverPending(ctx context.Context) (sent, failed int, err error) {
+	alerts, err := n.db.RetrieveAlertsWithPendingEvents(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to retrieve alerts: %w", err)
+	}
+
+	for _, alert := range alerts {
+		s, f, err := n.deliver(ctx, alert)
+		sent += s
+		failed += f
+		if err != nil {
+			return sent, failed, fmt.Errorf("alert %d: %w", alert.ID, err)
+		}
+	}
+	return sent, failed, nil
+}
+
+// deliver sends alert's pending events. Each is sent on its own unless
+// there are at least digest_after of them, or more than the notifications
+// left in the current window, in which case they go out in digests of up
+// to MaxDigestPosts. Once the window's notifications are used up, events
+// wait for the next.
+func (n *Notifier) deliver(ctx context.Context, alert database.Alert) (sent, failed int, err error) {
+	events, err := n.db.RetrievePendingAlertEvents(ctx, alert.ID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to retrieve events: %w", err)
+	}
+
+	now := n.now()
+	start, count := alert.WindowStart, alert.WindowCount
+	if !start.Valid || now.Sub(start.Time) >= RateWindow {
+		start, count = sql.NullTime{Time: now, Valid: true}, 0
+	}
+	remaining := int(alert.MaxPerHour - count)
+	if len(events) == 0 || remaining <= 0 {
+		return 0, 0, nil
+	}
+
+	var batches [][]database.RetrievePendingAlertEventsRow
+	if (alert.DigestAfter > 0 && len(events) >= int(alert.DigestAfter)) || len(events) > remaining {
+		batches = slices.Collect(slices.Chunk(events, MaxDigestPosts))
+	} else {
+		for _, e := range events {
+			batches = append(batches, []database.RetrievePendingAlertEventsRow{e})
+		}
+	}
+	batches = batches[:min(len(batches), remaining)]
+
+	for _, batch := range batches {
+		ids := make([]int32, len(batch))
+		attempts := int32(0)
+		for i, e := range batch {
+			ids[i] = e.ID
+			attempts = max(attempts, e.Attempts)
+		}
+
+		record := database.RecordAlertEventsParams{Status: StatusSent, Ids: ids}
+		if sendErr := n.Send(ctx, newNotification(alert, batch)); sendErr != nil {
+			record.Status = StatusPending
+			if attempts+1 >= MaxAttempts {
+				record.Status = StatusFailed
+			}
+			record.LastError = sql.NullString{String: sendErr.Error(), Valid: true}
+			failed += len(batch)
+		} else {
+			count++
+			sent += len(batch)
+		}
+		if err := n.db.RecordAlertEvents(ctx, record); err != nil {
+			return sent, failed, fmt.Errorf("failed to record events: %w", err)
+		}
+	}
+
+	err = n.db.RecordAlertWindow(ctx, database.RecordAlertWindowParams{
+		ID:          alert.ID,
+		WindowStart: start,
+		WindowCount: count,
+	})
+	if err != nil {
+		return sent, failed, fmt.Errorf("failed to record rate limit window: %w", err)
+	}
+	return sent, failed, nil
+}
+
+// Send delivers a notification to its alert's sink.
+func (n *Notifier) Send(ctx context.Context, note Notification) error {
+	alert := note.Alert
+	// The target may have been stored under another config, so it is
+	// checked against this process's policy every time.
+	if err := n.policy.Check(alert.Sink, alert.Target); err != nil {
+		return err
+	}
+	switch alert.Sink {
+	case SinkStdout:
+		_, err := fmt.Fprintf(n.out, "Alert %d: %s\n%s\n", alert.ID, note.Subject, note.Text)
+		return err
+	case SinkFile:
+		return appendToFile(alert.Target, n.now(), note)
+	case SinkCommand:
+		return runCommand(ctx, note)
+	case SinkSMTP:
+		return n.mail.Send(mail.Message{
+			To:      []string{alert.Target},
+			Subject: note.Subject,
+			Text:    note.Text,
+		})
+	}
+	return fmt.Errorf("unknown sink %q", alert.Sink)
+}
+
+func appendToFile(path string, now time.Time, note Notification) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "=== %s ===\n%s\n\n%s\n", now.Format(time.RFC3339), note.Subject, note.Text)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// runCommand runs the alert's target with sh -c, passing the message on
+// stdin and its details in GATOR_ALERT_* environment variables.
+func runCommand(ctx context.Context, note Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", note.Alert.Target)
+	cmd.Stdin = strings.NewReader(note.Text)
+	cmd.Env = append(os.Environ(),
+		"GATOR_ALERT_ID="+strconv.Itoa(int(note.Alert.ID)),
+		"GATOR_ALERT_PATTERN="+note.Alert.Pattern,
+		"GATOR_ALERT_SUBJECT="+note.Subject,
+		"GATOR_ALERT_COUNT="+strconv.Itoa(len(note.Posts)),
+	)
+	if len(note.Posts) > 0 {
+		cmd.Env = append(cmd.Env, "GATOR_ALERT_URL="+note.Posts[0].Url)
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// TestNotification builds a notification for alert about a made-up post,
+// to check that its sink works.
+func TestNotification(alert database.Alert) Notification {
+	return newNotification(alert, []database.RetrievePendingAlertEventsRow{{
+		Title:       "Test alert from gator",
+		Url:         "https://example.com/",
+		PublishedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		FeedName:    "gator",
+	}})
+}
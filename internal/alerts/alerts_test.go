@@ -0,0 +1,197 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/mail"
+	"github.com/sanntintdev/gator/internal/memstore"
+)
+
+type testEnv struct {
+	t     *testing.T
+	ctx   context.Context
+	db    *memstore.Store
+	out   bytes.Buffer
+	n     *Notifier
+	now   time.Time
+	user  database.User
+	feed  database.Feed
+	posts int
+}
+
+func newTestEnv(t *testing.T, policy Policy) *testEnv {
+	e := &testEnv{
+		t:   t,
+		ctx: context.Background(),
+		db:  memstore.New(),
+		now: time.Date(2024, 9, 3, 12, 0, 0, 0, time.UTC),
+	}
+	var err error
+	e.user, err = e.db.CreateUser(e.ctx, database.CreateUserParams{ID: uuid.New(), Name: "ann"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.feed, err = e.db.CreateFeed(e.ctx, database.CreateFeedParams{Url: "https://example.com/feed", Name: "News", UserID: e.user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.n = NewNotifier(e.db, mail.Sender{}, &e.out, policy)
+	e.n.now = func() time.Time { return e.now }
+	return e
+}
+
+func (e *testEnv) addAlert(sink, target string, maxPerHour, digestAfter int32) database.Alert {
+	e.t.Helper()
+	alert, err := e.db.CreateAlert(e.ctx, database.CreateAlertParams{
+		UserID:      e.user.ID,
+		Pattern:     "golang",
+		Sink:        sink,
+		Target:      target,
+		MaxPerHour:  maxPerHour,
+		DigestAfter: digestAfter,
+	})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return alert
+}
+
+// match records n new posts as matching alert.
+func (e *testEnv) match(alert database.Alert, n int) {
+	e.t.Helper()
+	for range n {
+		e.posts++
+		id, err := e.db.CreatePost(e.ctx, database.CreatePostParams{
+			Title:  fmt.Sprintf("post %d", e.posts),
+			Url:    fmt.Sprintf("https://example.com/%d", e.posts),
+			FeedID: e.feed.ID,
+		})
+		if err != nil {
+			e.t.Fatal(err)
+		}
+		if err := e.db.CreateAlertEvent(e.ctx, database.CreateAlertEventParams{AlertID: alert.ID, PostID: id}); err != nil {
+			e.t.Fatal(err)
+		}
+	}
+}
+
+// deliver runs DeliverPending and returns the number of events sent and
+// the notifications printed.
+func (e *testEnv) deliver() (int, []string) {
+	e.t.Helper()
+	e.out.Reset()
+	sent, failed, err := e.n.DeliverPending(e.ctx)
+	if err != nil || failed != 0 {
+		e.t.Fatalf("DeliverPending: %d failed, %v", failed, err)
+	}
+	var subjects []string
+	for _, line := range strings.Split(e.out.String(), "\n") {
+		if strings.HasPrefix(line, "Alert ") {
+			subjects = append(subjects, line)
+		}
+	}
+	return sent, subjects
+}
+
+func TestDeliverRateWindow(t *testing.T) {
+	e := newTestEnv(t, Policy{})
+	alert := e.addAlert(SinkStdout, "", 2, 0)
+
+	e.match(alert, 2)
+	if sent, notes := e.deliver(); sent != 2 || len(notes) != 2 {
+		t.Errorf("first two matches: sent %d in %d notifications, want 2 in 2", sent, len(notes))
+	}
+
+	e.now = e.now.Add(30 * time.Minute)
+	e.match(alert, 1)
+	if sent, notes := e.deliver(); sent != 0 || len(notes) != 0 {
+		t.Errorf("over the hourly limit: sent %d in %v", sent, notes)
+	}
+
+	e.now = e.now.Add(30 * time.Minute)
+	if sent, notes := e.deliver(); sent != 1 || len(notes) != 1 {
+		t.Errorf("in the next window: sent %d in %d notifications, want 1 in 1", sent, len(notes))
+	}
+}
+
+func TestDeliverDigests(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxPerHour  int32
+		digestAfter int32
+		matches     int
+		want        []string
+	}{
+		{"below digest_after", 10, 3, 2, []string{`"golang": post 1`, `"golang": post 2`}},
+		{"digest_after reached", 10, 3, 3, []string{`"golang": 3 new posts`}},
+		{"more than the window allows", 2, 0, 3, []string{`"golang": 3 new posts`}},
+		{"large burst split", 2, 5, 120, []string{`"golang": 50 new posts`, `"golang": 50 new posts`}},
+	}
+	for _, tt := range tests {
+		e := newTestEnv(t, Policy{})
+		alert := e.addAlert(SinkStdout, "", tt.maxPerHour, tt.digestAfter)
+		e.match(alert, tt.matches)
+
+		_, notes := e.deliver()
+		if len(notes) != len(tt.want) {
+			t.Errorf("%s: got notifications %v, want %v", tt.name, notes, tt.want)
+			continue
+		}
+		for i, want := range tt.want {
+			if !strings.HasSuffix(notes[i], want) {
+				t.Errorf("%s: notification %q, want one ending in %q", tt.name, notes[i], want)
+			}
+		}
+	}
+
+	// The rest of a split burst waits for the next window.
+	e := newTestEnv(t, Policy{})
+	alert := e.addAlert(SinkStdout, "", 2, 5)
+	e.match(alert, 120)
+	e.deliver()
+	e.now = e.now.Add(RateWindow)
+	if sent, notes := e.deliver(); sent != 20 || len(notes) != 1 {
+		t.Errorf("next window: sent %d in %v, want the last 20 in one digest", sent, notes)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "alerts.log")
+	e := newTestEnv(t, Policy{FileDir: dir})
+	alert := e.addAlert(SinkFile, path, 10, 0)
+	e.match(alert, 2)
+	if sent, _ := e.deliver(); sent != 2 {
+		t.Fatalf("sent %d, want 2", sent)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "=== 2024-09-03T12:00:00Z ==="); n != 2 {
+		t.Errorf("file has %d entries, want 2:\n%s", n, data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("alert file mode %o, want 600", perm)
+	}
+
+	outside := e.addAlert(SinkFile, filepath.Join(t.TempDir(), "alerts.log"), 10, 0)
+	if err := e.n.Send(e.ctx, TestNotification(outside)); err == nil {
+		t.Error("file sink wrote outside the allowed directory")
+	}
+}
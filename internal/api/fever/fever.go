@@ -0,0 +1,460 @@
+// Package fever implements the Fever API (version 3) so that readers which
+// only speak Fever can sync against gator.
+package fever
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/richtext"
+)
+
+const (
+	apiVersion = 3
+
+	// allGroupID is the single group every followed feed belongs to when
+	// the user has no folders. Otherwise each folder is a group.
+	allGroupID = 1
+
+	maxItems = 50
+)
+
+type Server struct {
+	db     database.Querier
+	logger *slog.Logger
+}
+
+func New(db database.Querier, logger *slog.Logger) *Server {
+	return &Server{db: db, logger: logger.With("api", "fever")}
+}
+
+// Register mounts the Fever endpoint on mux.
+func (s *Server) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/fever/", s.handle)
+}
+
+type group struct {
+	ID    int32  `json:"id"`
+	Title string `json:"title"`
+}
+
+type feedsGroup struct {
+	GroupID int32  `json:"group_id"`
+	FeedIDs string `json:"feed_ids"`
+}
+
+type feed struct {
+	ID                int32  `json:"id"`
+	FaviconID         int32  `json:"favicon_id"`
+	Title             string `json:"title"`
+	URL               string `json:"url"`
+	SiteURL           string `json:"site_url"`
+	IsSpark           int    `json:"is_spark"`
+	LastUpdatedOnTime int64  `json:"last_updated_on_time"`
+}
+
+type item struct {
+	ID            int32  `json:"id"`
+	FeedID        int32  `json:"feed_id"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	HTML          string `json:"html"`
+	URL           string `json:"url"`
+	IsSaved       int    `json:"is_saved"`
+	IsRead        int    `json:"is_read"`
+	CreatedOnTime int64  `json:"created_on_time"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if !r.URL.Query().Has("api") {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	response := map[string]any{
+		"api_version": apiVersion,
+		"auth":        0,
+	}
+
+	apiKey := strings.ToLower(r.Form.Get("api_key"))
+	user, err := s.db.GetUserByFeverAPIKey(ctx, sql.NullString{String: apiKey, Valid: apiKey != ""})
+	if err != nil {
+		s.writeJSON(w, response)
+		return
+	}
+	response["auth"] = 1
+
+	feeds, err := s.db.RetrieveFollowedFeedsForUser(ctx, user.ID)
+	if err != nil {
+		s.writeError(w, fmt.Errorf("failed to retrieve feeds: %w", err))
+		return
+	}
+	response["last_refreshed_on_time"] = lastRefreshed(feeds)
+
+	if r.Form.Has("mark") {
+		if err := s.mark(ctx, user, r.Form); err != nil {
+			s.writeError(w, err)
+			return
+		}
+	}
+
+	if r.Form.Has("groups") || r.Form.Has("feeds") {
+		follows, err := s.db.RetrieveFeedFollowsForUser(ctx, user.ID)
+		if err != nil {
+			s.writeError(w, fmt.Errorf("failed to retrieve followed feeds: %w", err))
+			return
+		}
+		groups, feedsGroups, err := s.groups(ctx, user, feeds, follows)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+		if r.Form.Has("groups") {
+			response["groups"] = groups
+		}
+		if r.Form.Has("feeds") {
+			response["feeds"] = newFeeds(feeds, follows)
+		}
+		response["feeds_groups"] = feedsGroups
+	}
+
+	if r.Form.Has("favicons") {
+		response["favicons"] = []any{}
+	}
+
+	if r.Form.Has("links") {
+		response["links"] = []any{}
+	}
+
+	if r.Form.Has("items") {
+		items, err := s.items(ctx, user, r.Form)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+		total, err := s.db.CountPostsForUser(ctx, user.ID)
+		if err != nil {
+			s.writeError(w, fmt.Errorf("failed to count items: %w", err))
+			return
+		}
+		response["items"] = items
+		response["total_items"] = total
+	}
+
+	if r.Form.Has("unread_item_ids") || r.Form.Get("as") == "read" || r.Form.Get("as") == "unread" {
+		ids, err := s.db.RetrieveUnreadPostIDsForUser(ctx, user.ID)
+		if err != nil {
+			s.writeError(w, fmt.Errorf("failed to retrieve unread items: %w", err))
+			return
+		}
+		response["unread_item_ids"] = joinIDs(ids)
+	}
+
+	if r.Form.Has("saved_item_ids") || r.Form.Get("as") == "saved" || r.Form.Get("as") == "unsaved" {
+		ids, err := s.db.RetrieveStarredPostIDsForUser(ctx, user.ID)
+		if err != nil {
+			s.writeError(w, fmt.Errorf("failed to retrieve saved items: %w", err))
+			return
+		}
+		response["saved_item_ids"] = joinIDs(ids)
+	}
+
+	s.writeJSON(w, response)
+}
+
+func (s *Server) items(ctx context.Context, user database.User, form url.Values) ([]item, error) {
+	items := []item{}
+
+	if withIDs := form.Get("with_ids"); withIDs != "" {
+		ids, err := parseIDs(withIDs)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > maxItems {
+			ids = ids[:maxItems]
+		}
+
+		posts, err := s.db.RetrievePostsForUserByIDs(ctx, database.RetrievePostsForUserByIDsParams{
+			UserID: user.ID,
+			Ids:    ids,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve items: %w", err)
+		}
+		for _, post := range posts {
+			items = append(items, newItem(database.RetrievePostsForUserSinceIDRow{
+				ID:          post.ID,
+				Title:       post.Title,
+				Url:         post.Url,
+				Description: post.Description,
+				PublishedAt: post.PublishedAt,
+				FeedID:      post.FeedID,
+				CreatedAt:   post.CreatedAt,
+				UpdatedAt:   post.UpdatedAt,
+				Author:      post.Author,
+				IsRead:      post.IsRead,
+				IsStarred:   post.IsStarred,
+			}))
+		}
+		return items, nil
+	}
+
+	if maxID := form.Get("max_id"); maxID != "" {
+		id, err := strconv.ParseInt(maxID, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_id: %s", maxID)
+		}
+
+		posts, err := s.db.RetrievePostsForUserBeforeID(ctx, database.RetrievePostsForUserBeforeIDParams{
+			UserID: user.ID,
+			ID:     int32(id),
+			Limit:  maxItems,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve items: %w", err)
+		}
+		for _, post := range posts {
+			items = append(items, newItem(database.RetrievePostsForUserSinceIDRow(post)))
+		}
+		return items, nil
+	}
+
+	var sinceID int64
+	if since := form.Get("since_id"); since != "" {
+		var err error
+		sinceID, err = strconv.ParseInt(since, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since_id: %s", since)
+		}
+	}
+
+	posts, err := s.db.RetrievePostsForUserSinceID(ctx, database.RetrievePostsForUserSinceIDParams{
+		UserID: user.ID,
+		ID:     int32(sinceID),
+		Limit:  maxItems,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve items: %w", err)
+	}
+	for _, post := range posts {
+		items = append(items, newItem(post))
+	}
+	return items, nil
+}
+
+func (s *Server) mark(ctx context.Context, user database.User, form url.Values) error {
+	id, err := strconv.ParseInt(form.Get("id"), 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid id: %s", form.Get("id"))
+	}
+
+	switch form.Get("mark") {
+	case "item":
+		switch form.Get("as") {
+		case "read", "unread":
+			err = s.db.SetPostRead(ctx, database.SetPostReadParams{
+				UserID: user.ID,
+				PostID: int32(id),
+				IsRead: form.Get("as") == "read",
+			})
+		case "saved", "unsaved":
+			err = s.db.SetPostStarred(ctx, database.SetPostStarredParams{
+				UserID:    user.ID,
+				PostID:    int32(id),
+				IsStarred: form.Get("as") == "saved",
+			})
+		default:
+			return fmt.Errorf("unsupported mark: %s", form.Get("as"))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to mark item %d: %w", id, err)
+		}
+		return nil
+
+	case "feed", "group":
+		if form.Get("as") != "read" {
+			return fmt.Errorf("unsupported mark: %s", form.Get("as"))
+		}
+
+		before := time.Now()
+		if b := form.Get("before"); b != "" {
+			secs, err := strconv.ParseInt(b, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid before: %s", b)
+			}
+			before = time.Unix(secs, 0)
+		}
+
+		var feedID, folderID sql.NullInt32
+		if form.Get("mark") == "feed" {
+			feedID = sql.NullInt32{Int32: int32(id), Valid: true}
+		} else if id != 0 {
+			// Group 0 is every feed. Without folders so is allGroupID.
+			folders, err := s.db.RetrieveFoldersForUser(ctx, user.ID)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve folders: %w", err)
+			}
+			if len(folders) > 0 {
+				folderID = sql.NullInt32{Int32: int32(id), Valid: true}
+			}
+		}
+
+		err = s.db.MarkPostsReadForUser(ctx, database.MarkPostsReadForUserParams{
+			UserID:   user.ID,
+			FeedID:   feedID,
+			FolderID: folderID,
+			Before:   before,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark %s %d read: %w", form.Get("mark"), id, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported mark: %s", form.Get("mark"))
+}
+
+// newFeeds lists feeds under the titles the user gave them in follows.
+func newFeeds(feeds []database.Feed, follows []database.RetrieveFeedFollowsForUserRow) []feed {
+	titles := map[int32]string{}
+	for _, ff := range follows {
+		if ff.Title.Valid {
+			titles[ff.FeedID] = ff.Title.String
+		}
+	}
+
+	result := make([]feed, 0, len(feeds))
+	for _, f := range feeds {
+		title := f.Name
+		if t, ok := titles[f.ID]; ok {
+			title = t
+		}
+		var lastUpdated int64
+		if f.LastFetchedAt.Valid {
+			lastUpdated = f.LastFetchedAt.Time.Unix()
+		}
+		result = append(result, feed{
+			ID:                f.ID,
+			Title:             title,
+			URL:               f.Url,
+			SiteURL:           f.Url,
+			LastUpdatedOnTime: lastUpdated,
+		})
+	}
+	return result
+}
+
+func newItem(post database.RetrievePostsForUserSinceIDRow) item {
+	created := post.CreatedAt
+	if post.PublishedAt.Valid {
+		created = post.PublishedAt.Time
+	}
+
+	return item{
+		ID:            post.ID,
+		FeedID:        post.FeedID,
+		Title:         post.Title,
+		Author:        post.Author,
+		HTML:          richtext.Sanitize(post.Description),
+		URL:           post.Url,
+		IsSaved:       boolInt(post.IsStarred),
+		IsRead:        boolInt(post.IsRead),
+		CreatedOnTime: created.Unix(),
+	}
+}
+
+// groups returns the user's folders as Fever groups, or the single "All"
+// group if they have none. Feeds outside any folder belong to no group.
+func (s *Server) groups(ctx context.Context, user database.User, feeds []database.Feed, follows []database.RetrieveFeedFollowsForUserRow) ([]group, []feedsGroup, error) {
+	folders, err := s.db.RetrieveFoldersForUser(ctx, user.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve folders: %w", err)
+	}
+
+	if len(folders) == 0 {
+		ids := make([]int32, 0, len(feeds))
+		for _, f := range feeds {
+			ids = append(ids, f.ID)
+		}
+		return []group{{ID: allGroupID, Title: "All"}},
+			[]feedsGroup{{GroupID: allGroupID, FeedIDs: joinIDs(ids)}}, nil
+	}
+
+	members := map[int32][]int32{}
+	for _, ff := range follows {
+		if ff.FolderID.Valid {
+			members[ff.FolderID.Int32] = append(members[ff.FolderID.Int32], ff.FeedID)
+		}
+	}
+	groups := make([]group, 0, len(folders))
+	feedsGroups := make([]feedsGroup, 0, len(folders))
+	for _, folder := range folders {
+		groups = append(groups, group{ID: folder.ID, Title: folder.Name})
+		feedsGroups = append(feedsGroups, feedsGroup{GroupID: folder.ID, FeedIDs: joinIDs(members[folder.ID])})
+	}
+	return groups, feedsGroups, nil
+}
+
+func lastRefreshed(feeds []database.Feed) int64 {
+	var latest int64
+	for _, f := range feeds {
+		if f.LastFetchedAt.Valid && f.LastFetchedAt.Time.Unix() > latest {
+			latest = f.LastFetchedAt.Time.Unix()
+		}
+	}
+	return latest
+}
+
+func parseIDs(value string) ([]int32, error) {
+	var ids []int32
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id: %s", part)
+		}
+		ids = append(ids, int32(id))
+	}
+	return ids, nil
+}
+
+func joinIDs(ids []int32) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(int64(id), 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Error("failed to write response", "error", err)
+	}
+}
+
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	s.logger.Error("request failed", "error", err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
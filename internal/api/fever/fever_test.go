@@ -0,0 +1,221 @@
+package fever
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/auth"
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/memstore"
+)
+
+type testServer struct {
+	t   *testing.T
+	ctx context.Context
+	db  *memstore.Store
+	mux *http.ServeMux
+	now time.Time
+}
+
+func newTestServer(t *testing.T) *testServer {
+	db := memstore.New()
+	mux := http.NewServeMux()
+	New(db, slog.New(slog.DiscardHandler)).Register(mux)
+	return &testServer{
+		t:   t,
+		ctx: context.Background(),
+		db:  db,
+		mux: mux,
+		now: time.Date(2024, 9, 3, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// addUser creates a user with password and returns them with their Fever
+// API key.
+func (ts *testServer) addUser(name, password string) (database.User, string) {
+	ts.t.Helper()
+	user, err := ts.db.CreateUser(ts.ctx, database.CreateUserParams{
+		ID: uuid.New(), CreatedAt: ts.now, UpdatedAt: ts.now, Name: name,
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	key := auth.FeverAPIKey(name, password)
+	err = ts.db.SetUserPassword(ts.ctx, database.SetUserPasswordParams{
+		UserID:       user.ID,
+		PasswordHash: hash,
+		FeverApiKey:  sql.NullString{String: key, Valid: true},
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return user, key
+}
+
+func (ts *testServer) addFeed(owner database.User, name string) database.Feed {
+	ts.t.Helper()
+	feed, err := ts.db.CreateFeed(ts.ctx, database.CreateFeedParams{
+		Url: "https://example.com/" + name, Name: name, UserID: owner.ID, CreatedAt: ts.now, UpdatedAt: ts.now,
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return feed
+}
+
+func (ts *testServer) follow(user database.User, feed database.Feed) {
+	ts.t.Helper()
+	if _, err := ts.db.CreateFeedFollow(ts.ctx, database.CreateFeedFollowParams{UserID: user.ID, FeedID: feed.ID}); err != nil {
+		ts.t.Fatal(err)
+	}
+}
+
+func (ts *testServer) addPost(feed database.Feed, title string) int32 {
+	ts.t.Helper()
+	ts.now = ts.now.Add(time.Hour)
+	id, err := ts.db.CreatePost(ts.ctx, database.CreatePostParams{
+		Title:       title,
+		Url:         "https://example.com/" + title,
+		PublishedAt: sql.NullTime{Time: ts.now, Valid: true},
+		FeedID:      feed.ID,
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return id
+}
+
+type response struct {
+	Auth          int    `json:"auth"`
+	Items         []item `json:"items"`
+	TotalItems    int64  `json:"total_items"`
+	UnreadItemIDs string `json:"unread_item_ids"`
+}
+
+// call posts key to the Fever endpoint with query as the request's query
+// string and decodes the response.
+func (ts *testServer) call(key, query string) response {
+	ts.t.Helper()
+	form := url.Values{"api_key": {key}}
+	req := httptest.NewRequest(http.MethodPost, "/fever/?api&"+query, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	ts.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		ts.t.Fatalf("%s: status %d: %s", query, w.Code, w.Body)
+	}
+	var resp response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		ts.t.Fatal(err)
+	}
+	return resp
+}
+
+func titles(items []item) []string {
+	var titles []string
+	for _, it := range items {
+		titles = append(titles, it.Title)
+	}
+	slices.Sort(titles)
+	return titles
+}
+
+func TestAuth(t *testing.T) {
+	ts := newTestServer(t)
+	bob, key := ts.addUser("bob", "bob-password")
+	feed := ts.addFeed(bob, "news")
+	ts.follow(bob, feed)
+	ts.addPost(feed, "post")
+
+	tests := []struct {
+		name string
+		key  string
+		auth int
+	}{
+		{"valid key", key, 1},
+		{"key is case insensitive", strings.ToUpper(key), 1},
+		{"wrong password", auth.FeverAPIKey("bob", "wrong"), 0},
+		{"no key", "", 0},
+	}
+	for _, tt := range tests {
+		resp := ts.call(tt.key, "items")
+		if resp.Auth != tt.auth {
+			t.Errorf("%s: auth = %d, want %d", tt.name, resp.Auth, tt.auth)
+		}
+		if tt.auth == 0 && len(resp.Items) != 0 {
+			t.Errorf("%s: got items %v without authenticating", tt.name, titles(resp.Items))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	ts.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fever/", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("request without ?api: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestItemsOnlyFollowedPosts(t *testing.T) {
+	ts := newTestServer(t)
+	ann, _ := ts.addUser("ann", "ann-password")
+	bob, key := ts.addUser("bob", "bob-password")
+	followed := ts.addFeed(ann, "followed")
+	muted := ts.addFeed(ann, "muted")
+	unfollowed := ts.addFeed(ann, "unfollowed")
+	ts.follow(ann, unfollowed)
+	ts.follow(bob, followed)
+	ts.follow(bob, muted)
+
+	visible := ts.addPost(followed, "visible")
+	hidden := ts.addPost(followed, "hidden")
+	mutedPost := ts.addPost(muted, "muted-post")
+	private := ts.addPost(unfollowed, "private")
+	err := ts.db.SetPostHidden(ts.ctx, database.SetPostHiddenParams{UserID: bob.ID, PostID: hidden, IsHidden: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ts.db.UpdateFeedFollowSettings(ts.ctx, database.UpdateFeedFollowSettingsParams{
+		UserID: bob.ID, FeedID: muted.ID, Muted: true, Notify: "all",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"visible"}
+	for _, query := range []string{"items", "items&since_id=0", fmt.Sprintf("items&max_id=%d", private+1)} {
+		resp := ts.call(key, query)
+		if got := titles(resp.Items); !slices.Equal(got, want) {
+			t.Errorf("%s: got items %v, want %v", query, got, want)
+		}
+		if resp.TotalItems != 1 {
+			t.Errorf("%s: total_items = %d, want 1", query, resp.TotalItems)
+		}
+	}
+
+	query := fmt.Sprintf("items&with_ids=%d,%d,%d,%d", visible, hidden, mutedPost, private)
+	if got := titles(ts.call(key, query).Items); !slices.Equal(got, []string{"muted-post", "visible"}) {
+		t.Errorf("with_ids: got items %v, want [muted-post visible]", got)
+	}
+	if got := titles(ts.call(key, fmt.Sprintf("items&with_ids=%d", private)).Items); len(got) != 0 {
+		t.Errorf("bob read a post from an unfollowed feed: %v", got)
+	}
+
+	if got := ts.call(key, "unread_item_ids").UnreadItemIDs; got != fmt.Sprint(visible) {
+		t.Errorf("unread_item_ids = %q, want %q", got, fmt.Sprint(visible))
+	}
+}
@@ -0,0 +1,95 @@
+package greader
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sanntintdev/gator/internal/auth"
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func TestClientLogin(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.addUser("bob", "bob-password")
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		status   int
+	}{
+		{"valid credentials", "bob", "bob-password", http.StatusOK},
+		{"wrong password", "bob", "wrong", http.StatusUnauthorized},
+		{"unknown user", "ann", "bob-password", http.StatusUnauthorized},
+		{"no credentials", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		w := ts.do(http.MethodPost, "/accounts/ClientLogin", "", url.Values{"Email": {tt.email}, "Passwd": {tt.password}})
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+			continue
+		}
+		if tt.status == http.StatusOK && !strings.Contains(w.Body.String(), "Auth="+token+"\n") {
+			t.Errorf("%s: body %q doesn't carry the token %q", tt.name, w.Body, token)
+		}
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	ts := newTestServer(t)
+	ann, annToken := ts.addUser("ann", "ann-password")
+	_, bobToken := ts.addUser("bob", "bob-password")
+	_, bobSignature, _ := strings.Cut(bobToken, "/")
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"valid token", annToken, http.StatusOK},
+		{"no token", "", http.StatusUnauthorized},
+		{"malformed token", "not-a-token", http.StatusUnauthorized},
+		{"unknown user", auth.Token("carol", "hash"), http.StatusUnauthorized},
+		{"another user's signature", "ann/" + bobSignature, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if w := ts.do(http.MethodGet, "/reader/api/0/user-info", tt.token, nil); w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+
+	// Changing the password revokes tokens issued before.
+	hash, err := auth.HashPassword("new-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.db.SetUserPassword(ts.ctx, database.SetUserPasswordParams{UserID: ann.ID, PasswordHash: hash}); err != nil {
+		t.Fatal(err)
+	}
+	if w := ts.do(http.MethodGet, "/reader/api/0/user-info", annToken, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("token after a password change: status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestStreamContentsOnlyFollowedFeeds(t *testing.T) {
+	ts := newTestServer(t)
+	ann, annToken := ts.addUser("ann", "ann-password")
+	_, bobToken := ts.addUser("bob", "bob-password")
+	feed := ts.addFeed(ann, "private")
+	ts.follow(ann, feed)
+	ts.addPost(feed, "post")
+
+	for _, stream := range []string{"feed/" + url.PathEscape(feed.Url), fmt.Sprintf("feed/%d", feed.ID), "user/-/state/com.google/reading-list"} {
+		path := "/reader/api/0/stream/contents/" + stream
+		if got := itemTitles(t, ts.do(http.MethodGet, path, bobToken, nil)); len(got) != 0 {
+			t.Errorf("%s: bob read %v from a feed they don't follow", stream, got)
+		}
+		if got := itemTitles(t, ts.do(http.MethodGet, path, annToken, nil)); !slices.Equal(got, []string{"post"}) {
+			t.Errorf("%s: ann got %v, want [post]", stream, got)
+		}
+	}
+}
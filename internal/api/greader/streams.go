@@ -0,0 +1,563 @@
+package greader
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/richtext"
+)
+
+const (
+	readingListState = "state/com.google/reading-list"
+	readState        = "state/com.google/read"
+	starredState     = "state/com.google/starred"
+	labelPrefix      = "label/"
+
+	itemIDPrefix = "tag:google.com,2005:reader/item/"
+
+	defaultStreamCount = 20
+	maxStreamCount     = 1000
+)
+
+type stream struct {
+	feedID      sql.NullInt32
+	folderID    sql.NullInt32
+	starredOnly bool
+}
+
+type link struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type content struct {
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+type origin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type item struct {
+	ID            string   `json:"id"`
+	CrawlTimeMsec string   `json:"crawlTimeMsec"`
+	TimestampUsec string   `json:"timestampUsec"`
+	Published     int64    `json:"published"`
+	Updated       int64    `json:"updated"`
+	Title         string   `json:"title"`
+	Author        string   `json:"author,omitempty"`
+	Canonical     []link   `json:"canonical"`
+	Alternate     []link   `json:"alternate"`
+	Summary       content  `json:"summary"`
+	Categories    []string `json:"categories"`
+	Origin        origin   `json:"origin"`
+}
+
+type streamContents struct {
+	ID           string `json:"id"`
+	Updated      int64  `json:"updated"`
+	Items        []item `json:"items"`
+	Continuation string `json:"continuation,omitempty"`
+}
+
+type itemRef struct {
+	ID              string   `json:"id"`
+	DirectStreamIDs []string `json:"directStreamIds"`
+	TimestampUsec   string   `json:"timestampUsec"`
+}
+
+type subscription struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Categories []category `json:"categories"`
+	URL        string     `json:"url"`
+	HTMLURL    string     `json:"htmlUrl"`
+	IconURL    string     `json:"iconUrl"`
+}
+
+type category struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type unreadCount struct {
+	ID                      string `json:"id"`
+	Count                   int64  `json:"count"`
+	NewestItemTimestampUsec string `json:"newestItemTimestampUsec"`
+}
+
+func (s *Server) handleSubscriptionList(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := s.db.RetrieveFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to retrieve subscriptions: %w", err))
+		return
+	}
+
+	subscriptions := make([]subscription, 0, len(follows))
+	for _, ff := range follows {
+		categories := []category{}
+		if ff.FolderName.Valid {
+			categories = append(categories, category{ID: labelStreamID(ff.FolderName.String), Label: ff.FolderName.String})
+		}
+		title := ff.FeedName
+		if ff.Title.Valid {
+			title = ff.Title.String
+		}
+		subscriptions = append(subscriptions, subscription{
+			ID:         feedStreamID(ff.FeedID),
+			Title:      title,
+			Categories: categories,
+			URL:        ff.FeedUrl,
+			HTMLURL:    ff.FeedUrl,
+		})
+	}
+
+	s.writeJSON(w, map[string]any{"subscriptions": subscriptions})
+}
+
+func (s *Server) handleTagList(w http.ResponseWriter, r *http.Request, user database.User) {
+	folders, err := s.db.RetrieveFoldersForUser(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to retrieve folders: %w", err))
+		return
+	}
+
+	tags := []map[string]string{
+		{"id": "user/-/" + starredState},
+	}
+	for _, folder := range folders {
+		tags = append(tags, map[string]string{"id": labelStreamID(folder.Name), "type": "folder"})
+	}
+	s.writeJSON(w, map[string]any{"tags": tags})
+}
+
+func (s *Server) handleUnreadCount(w http.ResponseWriter, r *http.Request, user database.User) {
+	counts, err := s.db.CountUnreadPostsForUser(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to count unread posts: %w", err))
+		return
+	}
+
+	folders, err := s.db.RetrieveFoldersForUser(r.Context(), user.ID)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to retrieve folders: %w", err))
+		return
+	}
+	labels := map[int32]*unreadCount{}
+	for _, folder := range folders {
+		labels[folder.ID] = &unreadCount{ID: labelStreamID(folder.Name)}
+	}
+
+	var total int64
+	var newest time.Time
+	newestByLabel := map[int32]time.Time{}
+	unreadCounts := make([]unreadCount, 0, len(counts)+len(folders)+1)
+	for _, count := range counts {
+		unreadCounts = append(unreadCounts, unreadCount{
+			ID:                      feedStreamID(count.FeedID),
+			Count:                   count.UnreadCount,
+			NewestItemTimestampUsec: usec(count.NewestCreatedAt),
+		})
+		// Muted feeds keep their own count but stay out of the reading
+		// list and label totals, as their posts do.
+		if count.Muted {
+			continue
+		}
+		total += count.UnreadCount
+		if count.NewestCreatedAt.After(newest) {
+			newest = count.NewestCreatedAt
+		}
+		if label, ok := labels[count.FolderID.Int32]; ok && count.FolderID.Valid {
+			label.Count += count.UnreadCount
+			if count.NewestCreatedAt.After(newestByLabel[count.FolderID.Int32]) {
+				newestByLabel[count.FolderID.Int32] = count.NewestCreatedAt
+			}
+		}
+	}
+	for _, folder := range folders {
+		label := labels[folder.ID]
+		if label.Count == 0 {
+			continue
+		}
+		label.NewestItemTimestampUsec = usec(newestByLabel[folder.ID])
+		unreadCounts = append(unreadCounts, *label)
+	}
+
+	unreadCounts = append(unreadCounts, unreadCount{
+		ID:                      "user/-/" + readingListState,
+		Count:                   total,
+		NewestItemTimestampUsec: usec(newest),
+	})
+
+	s.writeJSON(w, map[string]any{
+		"max":          maxStreamCount,
+		"unreadcounts": unreadCounts,
+	})
+}
+
+func (s *Server) handleStreamContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	streamID := r.PathValue("stream")
+	posts, continuation, err := s.queryStream(r, user, streamID)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	items := make([]item, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, newItem(post))
+	}
+
+	s.writeJSON(w, streamContents{
+		ID:           streamID,
+		Updated:      time.Now().Unix(),
+		Items:        items,
+		Continuation: continuation,
+	})
+}
+
+func (s *Server) handleStreamItemIDs(w http.ResponseWriter, r *http.Request, user database.User) {
+	posts, continuation, err := s.queryStream(r, user, r.URL.Query().Get("s"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	refs := make([]itemRef, 0, len(posts))
+	for _, post := range posts {
+		refs = append(refs, itemRef{
+			ID:              strconv.FormatInt(int64(post.ID), 10),
+			DirectStreamIDs: []string{},
+			TimestampUsec:   usec(post.CreatedAt),
+		})
+	}
+
+	response := map[string]any{"itemRefs": refs}
+	if continuation != "" {
+		response["continuation"] = continuation
+	}
+	s.writeJSON(w, response)
+}
+
+func (s *Server) handleStreamItemContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ids, err := parseItemIDs(r.Form["i"])
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	posts, err := s.db.RetrievePostsForUserByIDs(r.Context(), database.RetrievePostsForUserByIDsParams{
+		UserID: user.ID,
+		Ids:    ids,
+	})
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to retrieve items: %w", err))
+		return
+	}
+
+	items := make([]item, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, newItem(database.RetrieveStreamPostsForUserRow(post)))
+	}
+
+	s.writeJSON(w, streamContents{
+		ID:      "user/-/" + readingListState,
+		Updated: time.Now().Unix(),
+		Items:   items,
+	})
+}
+
+func (s *Server) handleEditTag(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ids, err := parseItemIDs(r.Form["i"])
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+	for _, tag := range r.Form["a"] {
+		if err := s.applyTag(ctx, user, ids, tag, true); err != nil {
+			s.writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	for _, tag := range r.Form["r"] {
+		if err := s.applyTag(ctx, user, ids, tag, false); err != nil {
+			s.writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	writeOK(w)
+}
+
+func (s *Server) applyTag(ctx context.Context, user database.User, ids []int32, tag string, value bool) error {
+	state := stripUserPrefix(tag)
+	for _, id := range ids {
+		var err error
+		switch state {
+		case readState:
+			err = s.db.SetPostRead(ctx, database.SetPostReadParams{
+				UserID: user.ID,
+				PostID: id,
+				IsRead: value,
+			})
+		case starredState:
+			err = s.db.SetPostStarred(ctx, database.SetPostStarredParams{
+				UserID:    user.ID,
+				PostID:    id,
+				IsStarred: value,
+			})
+		default:
+			// Other tags (kept-unread, labels) are accepted but not stored.
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update item %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleMarkAllAsRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	st, err := s.parseStream(r.Context(), user, r.Form.Get("s"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	before := time.Now()
+	if ts := r.Form.Get("ts"); ts != "" {
+		usecs, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid ts: %w", err))
+			return
+		}
+		before = time.UnixMicro(usecs)
+	}
+
+	err = s.db.MarkPostsReadForUser(r.Context(), database.MarkPostsReadForUserParams{
+		UserID:   user.ID,
+		FeedID:   st.feedID,
+		FolderID: st.folderID,
+		Before:   before,
+	})
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to mark stream read: %w", err))
+		return
+	}
+
+	writeOK(w)
+}
+
+func (s *Server) queryStream(r *http.Request, user database.User, streamID string) ([]database.RetrieveStreamPostsForUserRow, string, error) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	st, err := s.parseStream(ctx, user, streamID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	count := defaultStreamCount
+	if n := query.Get("n"); n != "" {
+		count, err = strconv.Atoi(n)
+		if err != nil || count <= 0 {
+			return nil, "", fmt.Errorf("invalid n: %s", n)
+		}
+		count = min(count, maxStreamCount)
+	}
+
+	offset := 0
+	if c := query.Get("c"); c != "" {
+		offset, err = strconv.Atoi(c)
+		if err != nil || offset < 0 {
+			return nil, "", fmt.Errorf("invalid continuation: %s", c)
+		}
+	}
+
+	params := database.RetrieveStreamPostsForUserParams{
+		UserID:      user.ID,
+		FeedID:      st.feedID,
+		FolderID:    st.folderID,
+		StarredOnly: st.starredOnly,
+		UnreadOnly:  stripUserPrefix(query.Get("xt")) == readState,
+		Limit:       int32(count),
+		Offset:      int32(offset),
+	}
+
+	// ot is the oldest timestamp the client wants, nt the newest.
+	if ot := query.Get("ot"); ot != "" {
+		secs, err := strconv.ParseInt(ot, 10, 64)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid ot: %s", ot)
+		}
+		params.NewerThan = sql.NullTime{Time: time.Unix(secs, 0), Valid: true}
+	}
+	if nt := query.Get("nt"); nt != "" {
+		secs, err := strconv.ParseInt(nt, 10, 64)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid nt: %s", nt)
+		}
+		params.OlderThan = sql.NullTime{Time: time.Unix(secs, 0), Valid: true}
+	}
+
+	posts, err := s.db.RetrieveStreamPostsForUser(ctx, params)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to retrieve stream: %w", err)
+	}
+
+	continuation := ""
+	if len(posts) == count {
+		continuation = strconv.Itoa(offset + count)
+	}
+
+	return posts, continuation, nil
+}
+
+func (s *Server) parseStream(ctx context.Context, user database.User, streamID string) (stream, error) {
+	if feedRef, ok := strings.CutPrefix(streamID, "feed/"); ok {
+		if id, err := strconv.ParseInt(feedRef, 10, 32); err == nil {
+			return stream{feedID: sql.NullInt32{Int32: int32(id), Valid: true}}, nil
+		}
+
+		feed, err := s.db.RetrieveFeedWithURL(ctx, feedRef)
+		if err != nil {
+			return stream{}, fmt.Errorf("unknown feed %s: %w", feedRef, err)
+		}
+		return stream{feedID: sql.NullInt32{Int32: feed.ID, Valid: true}}, nil
+	}
+
+	state := stripUserPrefix(streamID)
+	if name, ok := strings.CutPrefix(state, labelPrefix); ok {
+		folder, err := s.db.RetrieveFolderByName(ctx, database.RetrieveFolderByNameParams{
+			UserID: user.ID,
+			Name:   name,
+		})
+		if err != nil {
+			return stream{}, fmt.Errorf("unknown label %s: %w", name, err)
+		}
+		return stream{folderID: sql.NullInt32{Int32: folder.ID, Valid: true}}, nil
+	}
+
+	switch state {
+	case "", readingListState:
+		return stream{}, nil
+	case starredState:
+		return stream{starredOnly: true}, nil
+	}
+
+	return stream{}, fmt.Errorf("unsupported stream: %s", streamID)
+}
+
+func newItem(post database.RetrieveStreamPostsForUserRow) item {
+	published := post.CreatedAt
+	if post.PublishedAt.Valid {
+		published = post.PublishedAt.Time
+	}
+
+	categories := []string{"user/-/" + readingListState}
+	if post.IsRead {
+		categories = append(categories, "user/-/"+readState)
+	}
+	if post.IsStarred {
+		categories = append(categories, "user/-/"+starredState)
+	}
+
+	return item{
+		ID:            fmt.Sprintf("%s%016x", itemIDPrefix, post.ID),
+		CrawlTimeMsec: strconv.FormatInt(post.CreatedAt.UnixMilli(), 10),
+		TimestampUsec: usec(post.CreatedAt),
+		Published:     published.Unix(),
+		Updated:       post.UpdatedAt.Unix(),
+		Title:         post.Title,
+		Author:        post.Author,
+		Canonical:     []link{{Href: post.Url}},
+		Alternate:     []link{{Href: post.Url, Type: "text/html"}},
+		Summary:       content{Direction: "ltr", Content: richtext.Sanitize(post.Description)},
+		Categories:    categories,
+		Origin: origin{
+			StreamID: feedStreamID(post.FeedID),
+			Title:    post.FeedName,
+			HTMLURL:  post.FeedUrl,
+		},
+	}
+}
+
+// parseItemIDs accepts both the long "tag:google.com,2005:reader/item/<hex>"
+// form and the short decimal form clients send.
+func parseItemIDs(values []string) ([]int32, error) {
+	if len(values) == 0 {
+		return nil, errors.New("no item ids given")
+	}
+
+	ids := make([]int32, 0, len(values))
+	for _, value := range values {
+		var id int64
+		var err error
+		if hexID, ok := strings.CutPrefix(value, itemIDPrefix); ok {
+			id, err = strconv.ParseInt(hexID, 16, 64)
+		} else {
+			id, err = strconv.ParseInt(value, 10, 64)
+		}
+		if err != nil || id <= 0 || id > int64(^uint32(0)>>1) {
+			return nil, fmt.Errorf("invalid item id: %s", value)
+		}
+		ids = append(ids, int32(id))
+	}
+	return ids, nil
+}
+
+// stripUserPrefix turns "user/-/state/..." or "user/<id>/state/..." into
+// "state/...".
+func stripUserPrefix(streamID string) string {
+	rest, ok := strings.CutPrefix(streamID, "user/")
+	if !ok {
+		return streamID
+	}
+	_, state, ok := strings.Cut(rest, "/")
+	if !ok {
+		return streamID
+	}
+	return state
+}
+
+func feedStreamID(feedID int32) string {
+	return fmt.Sprintf("feed/%d", feedID)
+}
+
+func labelStreamID(name string) string {
+	return "user/-/" + labelPrefix + name
+}
+
+func usec(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixMicro(), 10)
+}
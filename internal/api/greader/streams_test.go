@@ -0,0 +1,166 @@
+package greader
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/auth"
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/memstore"
+)
+
+type testServer struct {
+	t   *testing.T
+	ctx context.Context
+	db  *memstore.Store
+	mux *http.ServeMux
+	now time.Time
+}
+
+func newTestServer(t *testing.T) *testServer {
+	db := memstore.New()
+	mux := http.NewServeMux()
+	New(db, slog.New(slog.DiscardHandler)).Register(mux)
+	return &testServer{
+		t:   t,
+		ctx: context.Background(),
+		db:  db,
+		mux: mux,
+		now: time.Date(2024, 9, 3, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// addUser creates a user with password and returns them with their API
+// token.
+func (ts *testServer) addUser(name, password string) (database.User, string) {
+	ts.t.Helper()
+	user, err := ts.db.CreateUser(ts.ctx, database.CreateUserParams{
+		ID: uuid.New(), CreatedAt: ts.now, UpdatedAt: ts.now, Name: name,
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	err = ts.db.SetUserPassword(ts.ctx, database.SetUserPasswordParams{UserID: user.ID, PasswordHash: hash})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return user, auth.Token(name, hash)
+}
+
+func (ts *testServer) addFeed(owner database.User, name string) database.Feed {
+	ts.t.Helper()
+	feed, err := ts.db.CreateFeed(ts.ctx, database.CreateFeedParams{
+		Url: "https://example.com/" + name, Name: name, UserID: owner.ID, CreatedAt: ts.now, UpdatedAt: ts.now,
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return feed
+}
+
+func (ts *testServer) follow(user database.User, feed database.Feed) {
+	ts.t.Helper()
+	if _, err := ts.db.CreateFeedFollow(ts.ctx, database.CreateFeedFollowParams{UserID: user.ID, FeedID: feed.ID}); err != nil {
+		ts.t.Fatal(err)
+	}
+}
+
+func (ts *testServer) addPost(feed database.Feed, title string) int32 {
+	ts.t.Helper()
+	ts.now = ts.now.Add(time.Hour)
+	id, err := ts.db.CreatePost(ts.ctx, database.CreatePostParams{
+		Title:       title,
+		Url:         "https://example.com/" + title,
+		PublishedAt: sql.NullTime{Time: ts.now, Valid: true},
+		FeedID:      feed.ID,
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return id
+}
+
+// do sends a request authorized with token, or without authorization if
+// token is empty. Form values are sent as the body of POST requests.
+func (ts *testServer) do(method, path, token string, form url.Values) *httptest.ResponseRecorder {
+	ts.t.Helper()
+	var req *http.Request
+	if method == http.MethodPost {
+		req = httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "GoogleLogin auth="+token)
+	}
+	w := httptest.NewRecorder()
+	ts.mux.ServeHTTP(w, req)
+	return w
+}
+
+func itemTitles(t *testing.T, w *httptest.ResponseRecorder) []string {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var contents streamContents
+	if err := json.Unmarshal(w.Body.Bytes(), &contents); err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, it := range contents.Items {
+		titles = append(titles, it.Title)
+	}
+	slices.Sort(titles)
+	return titles
+}
+
+func TestStreamItemContentsOnlyFollowedPosts(t *testing.T) {
+	ts := newTestServer(t)
+	ann, _ := ts.addUser("ann", "ann-password")
+	bob, bobToken := ts.addUser("bob", "bob-password")
+	followed := ts.addFeed(ann, "followed")
+	unfollowed := ts.addFeed(ann, "unfollowed")
+	ts.follow(ann, unfollowed)
+	ts.follow(bob, followed)
+
+	visible := ts.addPost(followed, "visible")
+	hidden := ts.addPost(followed, "hidden")
+	private := ts.addPost(unfollowed, "private")
+	err := ts.db.SetPostHidden(ts.ctx, database.SetPostHiddenParams{UserID: bob.ID, PostID: hidden, IsHidden: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{"i": {
+		fmt.Sprint(visible),
+		fmt.Sprintf("%s%016x", itemIDPrefix, hidden),
+		fmt.Sprint(private),
+	}}
+	got := itemTitles(t, ts.do(http.MethodPost, "/reader/api/0/stream/items/contents", bobToken, form))
+	if !slices.Equal(got, []string{"visible"}) {
+		t.Errorf("bob got items %v, want only [visible]", got)
+	}
+
+	form = url.Values{"i": {fmt.Sprint(private)}}
+	if got := itemTitles(t, ts.do(http.MethodPost, "/reader/api/0/stream/items/contents", bobToken, form)); len(got) != 0 {
+		t.Errorf("bob read a post from an unfollowed feed: %v", got)
+	}
+}
@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sanntintdev/gator/internal/alerts"
+	"github.com/sanntintdev/gator/internal/config"
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/mail"
+)
+
+// mailSender returns the SMTP relay settings from the config file.
+func mailSender(cfg *config.Config) mail.Sender {
+	return mail.Sender{
+		Addr:     cfg.SMTPAddr,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	}
+}
+
+// alertPolicy returns the command and file sinks the config file allows.
+func alertPolicy(cfg *config.Config) alerts.Policy {
+	return alerts.Policy{Commands: cfg.AlertCommands, FileDir: cfg.AlertFileDir}
+}
+
+func handlerAlert(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return errors.New("Usage: alert <add|list|remove|test> [args]")
+	}
+
+	sub := Command{Name: cmd.Args[0], Args: cmd.Args[1:]}
+	switch sub.Name {
+	case "add":
+		return handlerAlertAdd(s, sub, user)
+	case "list":
+		return handlerAlertList(s, sub, user)
+	case "remove":
+		return handlerAlertRemove(s, sub, user)
+	case "test":
+		return handlerAlertTest(s, sub, user)
+	}
+
+	return fmt.Errorf("unknown alert command: %s", sub.Name)
+}
+
+func handlerAlertAdd(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("alert add", flag.ContinueOnError)
+	feedRef := fs.String("feed", "", "only alert on posts from this feed URL or ID")
+	sink := fs.String("sink", alerts.SinkStdout, "where to send alerts: "+strings.Join(alerts.Sinks, ", "))
+	to := fs.String("to", "", "file path, shell command or email address, for the file, command and smtp sinks")
+	maxPerHour := fs.Int("max-per-hour", 10, "most notifications to send in an hour")
+	digestAfter := fs.Int("digest-after", 5, "send this many or more pending matches as one digest (0 to only digest when rate limited)")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("Usage: alert add [-feed url|id] [-sink stdout|file|command|smtp] [-to target] [-max-per-hour n] [-digest-after n] <pattern>")
+	}
+
+	pattern := strings.Join(fs.Args(), " ")
+	if _, err := alerts.Compile(pattern); err != nil {
+		return fmt.Errorf("Invalid pattern: %w", err)
+	}
+	if *maxPerHour < 1 {
+		return errors.New("-max-per-hour must be at least 1")
+	}
+	if *digestAfter < 0 {
+		return errors.New("-digest-after can't be negative")
+	}
+	target, err := alertTarget(s, *sink, *to)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	params := database.CreateAlertParams{
+		UserID:      user.ID,
+		Pattern:     pattern,
+		Sink:        *sink,
+		Target:      target,
+		MaxPerHour:  int32(*maxPerHour),
+		DigestAfter: int32(*digestAfter),
+	}
+	if *feedRef != "" {
+		feed, err := lookupFeed(s, ctx, *feedRef)
+		if err != nil {
+			return err
+		}
+		params.FeedID = sql.NullInt32{Int32: feed.ID, Valid: true}
+	}
+
+	alert, err := s.Db.CreateAlert(ctx, params)
+	if err != nil {
+		return fmt.Errorf("Failed to create alert: %w", err)
+	}
+
+	fmt.Printf("Alert %d created: %s\n", alert.ID, describeAlert(alert))
+	fmt.Println("Alerts are sent by agg after each fetch.")
+	return nil
+}
+
+// alertTarget checks the -to value needed by sink and normalizes it.
+func alertTarget(s *State, sink, to string) (string, error) {
+	switch sink {
+	case alerts.SinkStdout:
+		if to != "" {
+			return "", errors.New("The stdout sink takes no -to")
+		}
+		return "", nil
+	case alerts.SinkFile:
+		if to == "" {
+			return "", errors.New("The file sink needs -to path")
+		}
+		// agg may run from another directory.
+		path, err := filepath.Abs(to)
+		if err != nil {
+			return "", err
+		}
+		if err := alertPolicy(s.Cfg).Check(sink, path); err != nil {
+			return "", fmt.Errorf("The file sink can't write there: %w", err)
+		}
+		return path, nil
+	case alerts.SinkCommand:
+		if strings.TrimSpace(to) == "" {
+			return "", errors.New("The command sink needs -to command")
+		}
+		if err := alertPolicy(s.Cfg).Check(sink, to); err != nil {
+			return "", fmt.Errorf("The command sink can't run that: %w", err)
+		}
+		return to, nil
+	case alerts.SinkSMTP:
+		addr, err := netmail.ParseAddress(to)
+		if err != nil {
+			return "", fmt.Errorf("The smtp sink needs -to email address: %w", err)
+		}
+		if !mailSender(s.Cfg).Configured() {
+			fmt.Fprintln(os.Stderr, "Warning: set smtp_addr and smtp_from in the config file before agg can send email")
+		}
+		return addr.Address, nil
+	}
+	return "", fmt.Errorf("Invalid sink %q: use one of %s", sink, strings.Join(alerts.Sinks, ", "))
+}
+
+func describeAlert(a database.Alert) string {
+	to := a.Sink
+	if a.Target != "" {
+		to = fmt.Sprintf("%s %s", a.Sink, a.Target)
+	}
+	return fmt.Sprintf("%q to %s", a.Pattern, to)
+}
+
+func handlerAlertList(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	ctx := context.Background()
+	list, err := s.Db.RetrieveAlertsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve alerts: %w", err)
+	}
+	if len(list) == 0 {
+		fmt.Println("No alerts")
+		return nil
+	}
+
+	fmt.Println("=== ALERTS ===")
+	for _, a := range list {
+		fmt.Printf("  %d: %s\n", a.ID, describeAlert(a))
+		if a.FeedID.Valid {
+			feed, err := s.Db.RetrieveFeedByID(ctx, a.FeedID.Int32)
+			if err == nil {
+				fmt.Printf("     Feed: %s\n", feed.Url)
+			}
+		}
+		fmt.Printf("     At most %d an hour, digest after %d\n", a.MaxPerHour, a.DigestAfter)
+	}
+	return nil
+}
+
+func handlerAlertRemove(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	id, err := strconv.ParseInt(cmd.Args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("Invalid alert id: %w", err)
+	}
+
+	removed, err := s.Db.DeleteAlert(context.Background(), database.DeleteAlertParams{
+		ID:     int32(id),
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to remove alert: %w", err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("No alert %d found", id)
+	}
+
+	fmt.Printf("Alert %d removed.\n", id)
+	return nil
+}
+
+// handlerAlertTest sends a made-up match through an alert's sink right
+// away, ignoring its rate limit.
+func handlerAlertTest(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	id, err := strconv.ParseInt(cmd.Args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("Invalid alert id: %w", err)
+	}
+
+	ctx := context.Background()
+	list, err := s.Db.RetrieveAlertsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve alerts: %w", err)
+	}
+	i := slices.IndexFunc(list, func(a database.Alert) bool { return a.ID == int32(id) })
+	if i < 0 {
+		return fmt.Errorf("No alert %d found", id)
+	}
+
+	notifier := alerts.NewNotifier(s.Db, mailSender(s.Cfg), os.Stdout, alertPolicy(s.Cfg))
+	if err := notifier.Send(ctx, alerts.TestNotification(list[i])); err != nil {
+		return fmt.Errorf("Failed to send test alert: %w", err)
+	}
+	fmt.Printf("Test alert sent to %s\n", describeAlert(list[i]))
+	return nil
+}
+
+func RegisterAlertCommands(c *Commands) {
+	c.register("alert", MiddlewareLoggedIn(handlerAlert))
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"regexp"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+var (
+	// boms maps byte order marks to the charset they announce.
+	boms = []struct {
+		bom   []byte
+		label string
+	}{
+		{[]byte("\xef\xbb\xbf"), "utf-8"},
+		{[]byte("\xfe\xff"), "utf-16be"},
+		{[]byte("\xff\xfe"), "utf-16le"},
+	}
+
+	// xmlEncodingAttr matches the encoding declaration in an XML prolog.
+	xmlEncodingAttr = regexp.MustCompile(`^(\s*<\?xml[^>]*?\sencoding\s*=\s*["'])([^"']+)(["'])`)
+)
+
+// toUTF8 transcodes a feed body to UTF-8. The charset is taken from a byte
+// order mark, then the Content-Type header, then the XML prolog, as RFC 7303
+// orders them; bodies with none of these that are not valid UTF-8 are
+// assumed to be Windows-1252. The prolog is rewritten to declare UTF-8 so
+// encoding/xml accepts the result.
+func toUTF8(data []byte, contentType string) ([]byte, error) {
+	data, label := splitBOM(data)
+	if label == "" {
+		label = headerCharset(contentType)
+	}
+	if label == "" {
+		label = prologCharset(data)
+	}
+	if label == "" {
+		if utf8.Valid(data) {
+			return data, nil
+		}
+		label = "windows-1252"
+	}
+
+	enc, name := charset.Lookup(label)
+	if enc == nil {
+		return nil, fmt.Errorf("unsupported charset: %s", label)
+	}
+	if name != "utf-8" {
+		decoded, err := enc.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+		}
+		data = decoded
+	}
+
+	return xmlEncodingAttr.ReplaceAll(data, []byte("${1}UTF-8${3}")), nil
+}
+
+// splitBOM removes a byte order mark from data and returns the charset it
+// announces.
+func splitBOM(data []byte) ([]byte, string) {
+	for _, b := range boms {
+		if rest, ok := bytes.CutPrefix(data, b.bom); ok {
+			return rest, b.label
+		}
+	}
+	return data, ""
+}
+
+func prologCharset(data []byte) string {
+	m := xmlEncodingAttr.FindSubmatch(data)
+	if m == nil {
+		return ""
+	}
+	return string(m[2])
+}
+
+func headerCharset(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
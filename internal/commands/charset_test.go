@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"testing"
+	"unicode/utf16"
+)
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		contentType string
+		want        string
+	}{
+		{
+			name: "utf-8 unchanged",
+			data: `<?xml version="1.0"?><rss>café</rss>`,
+			want: `<?xml version="1.0"?><rss>café</rss>`,
+		},
+		{
+			name: "byte order mark removed",
+			data: "\xef\xbb\xbf<rss>café</rss>",
+			want: "<rss>café</rss>",
+		},
+		{
+			name: "prolog charset",
+			data: `<?xml version="1.0" encoding="ISO-8859-1"?><rss>caf` + "\xe9" + `</rss>`,
+			want: `<?xml version="1.0" encoding="UTF-8"?><rss>café</rss>`,
+		},
+		{
+			name: "prolog in single quotes",
+			data: `<?xml version='1.0' encoding='windows-1251'?><rss>` + "\xcf\xf0\xe8\xe2\xe5\xf2" + `</rss>`,
+			want: `<?xml version='1.0' encoding='UTF-8'?><rss>Привет</rss>`,
+		},
+		{
+			name:        "header beats prolog",
+			data:        `<?xml version="1.0" encoding="iso-8859-15"?><rss>` + "\xa4" + `</rss>`,
+			contentType: "application/rss+xml; charset=windows-1252",
+			want:        `<?xml version="1.0" encoding="UTF-8"?><rss>¤</rss>`,
+		},
+		{
+			name: "utf-16le byte order mark",
+			data: "\xff\xfe" + utf16le(`<?xml version="1.0" encoding="UTF-16"?><rss>café</rss>`),
+			want: `<?xml version="1.0" encoding="UTF-8"?><rss>café</rss>`,
+		},
+		{
+			name:        "utf-16be byte order mark beats header",
+			data:        "\xfe\xff" + utf16be(`<rss>日本</rss>`),
+			contentType: "text/xml; charset=iso-8859-1",
+			want:        "<rss>日本</rss>",
+		},
+		{
+			name:        "header charset",
+			data:        "<rss>\x93quoted\x94</rss>",
+			contentType: `text/xml; charset="windows-1252"`,
+			want:        "<rss>“quoted”</rss>",
+		},
+		{
+			name:        "shift_jis header",
+			data:        "<rss>\x93\xfa\x96\x7b</rss>",
+			contentType: "application/xml; charset=Shift_JIS",
+			want:        "<rss>日本</rss>",
+		},
+		{
+			name: "invalid utf-8 without a charset is windows-1252",
+			data: "<rss>caf\xe9 \x80</rss>",
+			want: "<rss>café €</rss>",
+		},
+		{
+			name:        "unparsable header ignored",
+			data:        "<rss>café</rss>",
+			contentType: "text/xml; charset",
+			want:        "<rss>café</rss>",
+		},
+	}
+	for _, tt := range tests {
+		got, err := toUTF8([]byte(tt.data), tt.contentType)
+		if err != nil {
+			t.Errorf("%s: failed: %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestToUTF8UnknownCharset(t *testing.T) {
+	_, err := toUTF8([]byte(`<?xml version="1.0" encoding="x-klingon"?><rss/>`), "")
+	if err == nil {
+		t.Error("expected an error for an unknown charset")
+	}
+}
+
+func utf16le(s string) string {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return string(b)
+}
+
+func utf16be(s string) string {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u>>8), byte(u))
+	}
+	return string(b)
+}
@@ -2,14 +2,18 @@ package commands
 
 import (
 	"fmt"
+	"log/slog"
 
 	"github.com/sanntintdev/gator/internal/config"
 	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/migrate"
 )
 
 type State struct {
-	Db  *database.Queries
-	Cfg *config.Config
+	Db       database.Store
+	Migrator *migrate.Migrator
+	Cfg      *config.Config
+	Logger   *slog.Logger
 }
 
 type Command struct {
@@ -32,6 +36,7 @@ func (c *Commands) Run(s *State, cmd Command) error {
 	if !ok {
 		return fmt.Errorf("unknown command: %s", cmd.Name)
 	}
+	s.Logger = s.Logger.With("command", cmd.Name)
 	return handler(s, cmd)
 }
 
@@ -42,4 +47,12 @@ func (c *Commands) register(name string, handler func(*State, Command) error) {
 func (c *Commands) RegisterDefaultCommands() {
 	RegisterUserCommands(c)
 	RegisterFeedCommands(c)
+	RegisterFolderCommands(c)
+	RegisterServerCommands(c)
+	RegisterWebhookCommands(c)
+	RegisterRulesCommands(c)
+	RegisterAlertCommands(c)
+	RegisterDigestCommands(c)
+	RegisterDoctorCommands(c)
+	RegisterMigrateCommands(c)
 }
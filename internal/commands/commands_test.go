@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/config"
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/memstore"
+)
+
+// testEnv runs handlers against an in-memory database.
+type testEnv struct {
+	t     *testing.T
+	ctx   context.Context
+	db    *memstore.Store
+	state *State
+	now   time.Time
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	db := memstore.New()
+	return &testEnv{
+		t:   t,
+		ctx: context.Background(),
+		db:  db,
+		state: &State{
+			Db:     db,
+			Cfg:    &config.Config{},
+			Logger: slog.New(slog.DiscardHandler),
+		},
+		now: time.Date(2024, 9, 3, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func (e *testEnv) addUser(name string) database.User {
+	e.t.Helper()
+	user, err := e.db.CreateUser(e.ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: e.now,
+		UpdatedAt: e.now,
+		Name:      name,
+	})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return user
+}
+
+func (e *testEnv) addFeed(user database.User, name, url string) database.Feed {
+	e.t.Helper()
+	feed, err := e.db.CreateFeed(e.ctx, database.CreateFeedParams{
+		Url:       url,
+		Name:      name,
+		UserID:    user.ID,
+		CreatedAt: e.now,
+		UpdatedAt: e.now,
+	})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return feed
+}
+
+func (e *testEnv) follow(user database.User, feed database.Feed) {
+	e.t.Helper()
+	_, err := e.db.CreateFeedFollow(e.ctx, database.CreateFeedFollowParams{UserID: user.ID, FeedID: feed.ID})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+// addPost adds a post to feed, each one published an hour after the last.
+func (e *testEnv) addPost(feed database.Feed, title string) int32 {
+	e.t.Helper()
+	e.now = e.now.Add(time.Hour)
+	id, err := e.db.CreatePost(e.ctx, database.CreatePostParams{
+		Title:       title,
+		Url:         "https://example.com/" + title,
+		Description: "<p>" + title + "</p>",
+		PublishedAt: sql.NullTime{Time: e.now, Valid: true},
+		FeedID:      feed.ID,
+	})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return id
+}
+
+// run calls handler as the given user, returning what it printed.
+func (e *testEnv) run(user database.User, handler func(*State, Command, database.User) error, args ...string) (string, error) {
+	e.t.Helper()
+	e.state.Cfg.CurrentUserName = user.Name
+	return captureStdout(e.t, func() error {
+		return MiddlewareLoggedIn(handler)(e.state, Command{Args: args})
+	})
+}
+
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	err = fn()
+	w.Close()
+	return <-out, err
+}
@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	netmail "net/mail"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/digest"
+)
+
+func handlerDigest(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return errors.New("Usage: digest <subscribe|unsubscribe|status|preview|send> [args]")
+	}
+
+	sub := Command{Name: cmd.Args[0], Args: cmd.Args[1:]}
+	switch sub.Name {
+	case "subscribe":
+		return handlerDigestSubscribe(s, sub, user)
+	case "unsubscribe":
+		return handlerDigestUnsubscribe(s, sub, user)
+	case "status":
+		return handlerDigestStatus(s, sub, user)
+	case "preview":
+		return handlerDigestPreview(s, sub, user)
+	case "send":
+		return handlerDigestSend(s, sub, user)
+	}
+
+	return fmt.Errorf("unknown digest command: %s", sub.Name)
+}
+
+func handlerDigestSubscribe(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("digest subscribe", flag.ContinueOnError)
+	frequency := fs.String("frequency", digest.Daily, "how often to send the digest: "+strings.Join(digest.Frequencies, " or "))
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("Usage: digest subscribe [-frequency daily|weekly] <email>")
+	}
+	if !slices.Contains(digest.Frequencies, *frequency) {
+		return fmt.Errorf("Invalid frequency %q, expected daily or weekly", *frequency)
+	}
+	addr, err := netmail.ParseAddress(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid email address: %w", err)
+	}
+
+	sub, err := s.Db.UpsertDigestSubscription(context.Background(), database.UpsertDigestSubscriptionParams{
+		UserID:    user.ID,
+		Email:     addr.Address,
+		Frequency: *frequency,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to save digest subscription: %w", err)
+	}
+
+	fmt.Printf("Subscribed %s to %s digests.\n", sub.Email, sub.Frequency)
+	if !mailSender(s.Cfg).Configured() {
+		fmt.Fprintln(os.Stderr, "Warning: set smtp_addr and smtp_from in the config file before agg can send email")
+	}
+	return nil
+}
+
+func handlerDigestUnsubscribe(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	removed, err := s.Db.DeleteDigestSubscription(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to remove digest subscription: %w", err)
+	}
+	if removed == 0 {
+		return errors.New("You are not subscribed to digests")
+	}
+
+	fmt.Println("Digests will no longer be sent.")
+	return nil
+}
+
+// digestSubscription returns the user's subscription, or a friendly error
+// if they have none.
+func digestSubscription(s *State, ctx context.Context, user database.User) (database.DigestSubscription, error) {
+	sub, err := s.Db.RetrieveDigestSubscription(ctx, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sub, errors.New("You are not subscribed to digests; run 'digest subscribe <email>' first")
+	}
+	if err != nil {
+		return sub, fmt.Errorf("Failed to retrieve digest subscription: %w", err)
+	}
+	return sub, nil
+}
+
+func handlerDigestStatus(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	sub, err := digestSubscription(s, context.Background(), user)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Email:     %s\n", sub.Email)
+	fmt.Printf("Frequency: %s\n", sub.Frequency)
+	if sub.LastRunAt.Valid {
+		fmt.Printf("Last run:  %s\n", sub.LastRunAt.Time.Local().Format(time.RFC1123))
+	} else {
+		fmt.Println("Last run:  never")
+	}
+	fmt.Printf("Next run:  %s\n", digest.NextRun(sub).Local().Format(time.RFC1123))
+	if !mailSender(s.Cfg).Configured() {
+		fmt.Println("No SMTP server is configured, so agg won't send digests (set smtp_addr and smtp_from).")
+	}
+	return nil
+}
+
+// handlerDigestPreview prints the digest that would be sent now, without
+// sending it or marking its posts as included.
+func handlerDigestPreview(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("digest preview", flag.ContinueOnError)
+	html := fs.Bool("html", false, "print the HTML version instead of plain text")
+	limit := fs.Int("limit", digest.MaxPosts, "most posts to include")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("Usage: digest preview [-html] [-limit n]")
+	}
+
+	ctx := context.Background()
+	d, _, err := buildDigest(s, ctx, user, *limit)
+	if err != nil {
+		return err
+	}
+	if d.Count == 0 {
+		fmt.Println("No new posts for your digest")
+		return nil
+	}
+
+	if !*html {
+		fmt.Printf("Subject: %s\n\n%s", d.Subject(), d.Text())
+		return nil
+	}
+	out, err := d.HTML()
+	if err != nil {
+		return fmt.Errorf("Failed to render digest: %w", err)
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// handlerDigestSend sends the user's digest right away, whether or not it
+// is due.
+func handlerDigestSend(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("digest send", flag.ContinueOnError)
+	limit := fs.Int("limit", digest.MaxPosts, "most posts to include")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("Usage: digest send [-limit n]")
+	}
+
+	ctx := context.Background()
+	d, sub, err := buildDigest(s, ctx, user, *limit)
+	if err != nil {
+		return err
+	}
+	if d.Count == 0 {
+		fmt.Println("No new posts for your digest")
+		return nil
+	}
+
+	err = digest.NewMailer(s.Db, mailSender(s.Cfg)).Send(ctx, sub, d)
+	if err != nil && !errors.Is(err, digest.ErrNotRecorded) {
+		return fmt.Errorf("Failed to send digest: %w", err)
+	}
+	fmt.Printf("Sent digest to %s: %s\n", sub.Email, d.Summary())
+	return err
+}
+
+// buildDigest builds the digest the user's subscription would get now.
+func buildDigest(s *State, ctx context.Context, user database.User, limit int) (digest.Digest, database.DigestSubscription, error) {
+	if limit < 1 {
+		return digest.Digest{}, database.DigestSubscription{}, errors.New("-limit must be at least 1")
+	}
+
+	sub, err := digestSubscription(s, ctx, user)
+	if err != nil {
+		return digest.Digest{}, sub, err
+	}
+
+	d, err := digest.Build(ctx, s.Db, user, sub.Frequency, digest.Since(sub, time.Now()), limit)
+	if err != nil {
+		return d, sub, fmt.Errorf("Failed to build digest: %w", err)
+	}
+	return d, sub, nil
+}
+
+func RegisterDigestCommands(c *Commands) {
+	c.register("digest", MiddlewareLoggedIn(handlerDigest))
+}
@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+// feedDiagnosis is the doctor's verdict on a single feed.
+type feedDiagnosis struct {
+	feed     database.RetrieveFeedHealthRow
+	issues   []string
+	dead     bool
+	orphaned bool
+	movedTo  string
+}
+
+func diagnoseFeed(feed database.RetrieveFeedHealthRow, maxFailures int, staleAfter time.Duration) feedDiagnosis {
+	d := feedDiagnosis{feed: feed}
+
+	if feed.DeadAt.Valid {
+		d.issues = append(d.issues, fmt.Sprintf("gone (410) since %s, no longer fetched", feed.DeadAt.Time.Format(time.DateOnly)))
+		d.dead = true
+	}
+	if maxFailures > 0 && feed.ConsecutiveFailures >= int64(maxFailures) {
+		issue := fmt.Sprintf("%d consecutive failed fetches", feed.ConsecutiveFailures)
+		if feed.LastError.Valid {
+			issue += ": " + feed.LastError.String
+		}
+		d.issues = append(d.issues, issue)
+		d.dead = true
+	}
+	if feed.LastErrorKind.Valid && feed.LastErrorKind.String == FetchErrorParse {
+		d.issues = append(d.issues, "invalid XML on last fetch")
+		d.dead = true
+	}
+	if staleAfter > 0 && time.Since(feed.LastPostAt) > staleAfter {
+		d.issues = append(d.issues, fmt.Sprintf("no new posts since %s", feed.LastPostAt.Format(time.DateOnly)))
+		d.dead = true
+	}
+	if feed.LastRedirectUrl.Valid {
+		d.movedTo = feed.LastRedirectUrl.String
+		d.issues = append(d.issues, "permanently redirected to "+d.movedTo)
+	}
+	if feed.FollowerCount == 0 {
+		d.issues = append(d.issues, "no followers")
+		d.orphaned = true
+	}
+
+	return d
+}
+
+func handlerDoctor(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	maxFailures := fs.Int("failures", 3, "flag feeds with at least this many consecutive failed fetches")
+	staleDays := fs.Int("stale-days", 90, "flag feeds with no new posts in this many days")
+	unfollow := fs.Bool("unfollow", false, "unfollow dead feeds")
+	deleteFeeds := fs.Bool("delete", false, "delete dead feeds and feeds nobody follows, among those you added")
+	updateURLs := fs.Bool("update-urls", false, "move permanently redirected feeds you added to their new URL")
+	yes := fs.Bool("yes", false, "apply changes without asking")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	ctx := context.Background()
+	feeds, err := s.Db.RetrieveFeedHealth(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve feed health: %w", err)
+	}
+
+	followed, err := s.Db.RetrieveFollowedFeedsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve followed feeds: %w", err)
+	}
+	following := make(map[int32]bool, len(followed))
+	for _, feed := range followed {
+		following[feed.ID] = true
+	}
+
+	var (
+		unhealthy    []feedDiagnosis
+		toUnfollow   []feedDiagnosis
+		toDelete     []feedDiagnosis
+		toUpdateURLs []feedDiagnosis
+		notOwned     []feedDiagnosis
+	)
+	staleAfter := time.Duration(*staleDays) * 24 * time.Hour
+	for _, feed := range feeds {
+		d := diagnoseFeed(feed, *maxFailures, staleAfter)
+		if len(d.issues) == 0 {
+			continue
+		}
+		unhealthy = append(unhealthy, d)
+
+		if *unfollow && d.dead && following[feed.ID] {
+			toUnfollow = append(toUnfollow, d)
+		}
+		// Deleting or moving a feed changes it for everyone following it, so
+		// like deletefeed and editfeed that is left to the user who added it.
+		deletable := *deleteFeeds && (d.dead || d.orphaned)
+		movable := *updateURLs && d.movedTo != "" && !deletable
+		if (deletable || movable) && feed.UserID != user.ID {
+			notOwned = append(notOwned, d)
+			continue
+		}
+		if deletable {
+			toDelete = append(toDelete, d)
+		}
+		if movable {
+			toUpdateURLs = append(toUpdateURLs, d)
+		}
+	}
+
+	if len(unhealthy) == 0 {
+		fmt.Printf("All %d feeds look healthy\n", len(feeds))
+		return nil
+	}
+
+	fmt.Printf("%d of %d feeds need attention:\n", len(unhealthy), len(feeds))
+	for _, d := range unhealthy {
+		fmt.Printf("#%d %s (%s)\n", d.feed.ID, d.feed.Name, d.feed.Url)
+		for _, issue := range d.issues {
+			fmt.Printf("  - %s\n", issue)
+		}
+	}
+
+	if len(notOwned) > 0 {
+		fmt.Println()
+		for _, d := range notOwned {
+			fmt.Printf("skip     #%d %s: added by another user\n", d.feed.ID, d.feed.Name)
+		}
+	}
+
+	changes := len(toUnfollow) + len(toDelete) + len(toUpdateURLs)
+	if changes == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	for _, d := range toUnfollow {
+		fmt.Printf("unfollow #%d %s\n", d.feed.ID, d.feed.Name)
+	}
+	for _, d := range toDelete {
+		fmt.Printf("delete   #%d %s\n", d.feed.ID, d.feed.Name)
+	}
+	for _, d := range toUpdateURLs {
+		fmt.Printf("move     #%d %s -> %s\n", d.feed.ID, d.feed.Url, d.movedTo)
+	}
+
+	if !*yes {
+		if !confirm(fmt.Sprintf("Apply %d changes?", changes)) {
+			fmt.Println("No changes made")
+			return nil
+		}
+	}
+
+	// The changes are applied together: if any fails, none are kept.
+	err = s.Db.InTx(ctx, func(q database.Querier) error {
+		for _, d := range toUnfollow {
+			err := q.DeleteFeedFollow(ctx, database.DeleteFeedFollowParams{
+				UserID: user.ID,
+				FeedID: d.feed.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to unfollow #%d: %w", d.feed.ID, err)
+			}
+		}
+		for _, d := range toDelete {
+			if err := q.DeleteFeed(ctx, d.feed.ID); err != nil {
+				return fmt.Errorf("failed to delete #%d: %w", d.feed.ID, err)
+			}
+		}
+		for _, d := range toUpdateURLs {
+			if _, err := moveFeed(ctx, q, d.feed.ID, d.movedTo); err != nil {
+				return fmt.Errorf("failed to move #%d: %w", d.feed.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("No changes made: %w", err)
+	}
+
+	fmt.Printf("Applied %d changes\n", changes)
+	return nil
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func RegisterDoctorCommands(c *Commands) {
+	c.register("doctor", MiddlewareLoggedIn(handlerDoctor))
+}
@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+// ownedFeed resolves ref to a feed added by user. Only the user who added
+// a feed may change or delete it, since others may be following it.
+func ownedFeed(s *State, ctx context.Context, ref string, user database.User) (database.Feed, error) {
+	feed, err := lookupFeed(s, ctx, ref)
+	if err != nil {
+		return database.Feed{}, err
+	}
+	if feed.UserID != user.ID {
+		return database.Feed{}, fmt.Errorf("Feed %s was added by another user", feed.Name)
+	}
+	return feed, nil
+}
+
+func handlerEditFeed(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("editfeed", flag.ContinueOnError)
+	name := fs.String("name", "", "new name for the feed")
+	newURL := fs.String("url", "", "new URL for the feed")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || (*name == "" && *newURL == "") {
+		return errors.New("Usage: editfeed [-name name] [-url url] <feed-url|feed-id>")
+	}
+
+	ctx := context.Background()
+	feed, err := ownedFeed(s, ctx, fs.Arg(0), user)
+	if err != nil {
+		return err
+	}
+
+	rename := *name != "" && *name != feed.Name
+	move := *newURL != "" && *newURL != feed.Url
+	if move {
+		if u, err := url.Parse(*newURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("Invalid feed URL: %s", *newURL)
+		}
+		existing, err := s.Db.RetrieveFeedWithURL(ctx, *newURL)
+		if err == nil {
+			return fmt.Errorf("Feed #%d %s already uses %s", existing.ID, existing.Name, *newURL)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("Failed to look up %s: %w", *newURL, err)
+		}
+	}
+
+	err = s.Db.InTx(ctx, func(q database.Querier) error {
+		if rename {
+			err := q.RenameFeed(ctx, database.RenameFeedParams{
+				ID:   feed.ID,
+				Name: *name,
+			})
+			if err != nil {
+				return fmt.Errorf("Failed to rename feed: %w", err)
+			}
+		}
+		if move {
+			err := q.UpdateFeedURL(ctx, database.UpdateFeedURLParams{
+				ID:  feed.ID,
+				Url: *newURL,
+			})
+			if err != nil {
+				return fmt.Errorf("Failed to change feed URL: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if rename {
+		fmt.Printf("Renamed %s to %s\n", feed.Name, *name)
+	}
+	if move {
+		fmt.Printf("Moved %s from %s to %s\n", feed.Name, feed.Url, *newURL)
+	}
+	return nil
+}
+
+func handlerDeleteFeed(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("deletefeed", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "show what would be deleted without deleting it")
+	yes := fs.Bool("yes", false, "delete without asking")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("Usage: deletefeed [-dry-run] [-yes] <feed-url|feed-id>")
+	}
+
+	ctx := context.Background()
+	feed, err := ownedFeed(s, ctx, fs.Arg(0), user)
+	if err != nil {
+		return err
+	}
+
+	refs, err := s.Db.CountFeedReferences(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to count feed data: %w", err)
+	}
+
+	fmt.Printf("#%d %s (%s)\n", feed.ID, feed.Name, feed.Url)
+	fmt.Printf("  %d follows\n", refs.FollowCount)
+	fmt.Printf("  %d posts, with %d read or starred states\n", refs.PostCount, refs.PostStateCount)
+	fmt.Printf("  %d webhooks\n", refs.WebhookCount)
+	fmt.Printf("  %d fetch log entries\n", refs.FetchCount)
+	fmt.Printf("  %d filter rules, and %d tags on its posts\n", refs.FilterRuleCount, refs.PostTagCount)
+	fmt.Printf("  %d alerts, and %d alert events for it or its posts\n", refs.AlertCount, refs.AlertEventCount)
+
+	if *dryRun {
+		fmt.Println("Dry run, nothing deleted")
+		return nil
+	}
+	if !*yes && !confirm("Delete this feed and everything above?") {
+		fmt.Println("Nothing deleted")
+		return nil
+	}
+
+	if err := s.Db.DeleteFeed(ctx, feed.ID); err != nil {
+		return fmt.Errorf("Failed to delete feed: %w", err)
+	}
+	fmt.Printf("Deleted %s\n", feed.Name)
+	return nil
+}
+
+// handlerEditFollow changes how the current user sees a feed they follow,
+// without affecting anyone else following it. With no flags it prints the
+// current settings.
+func handlerEditFollow(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("editfollow", flag.ContinueOnError)
+	title := fs.String("title", "", "your own title for the feed (empty to use the feed's name)")
+	muted := fs.Bool("muted", false, "hide the feed's posts from browse folders, streams and your timeline")
+	notify := fs.String("notify", "", "send new posts to your webhooks: all or none")
+	priority := fs.Int("priority", 0, "sort position in following; higher comes first")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("Usage: editfollow [-title t] [-muted=true|false] [-notify all|none] [-priority n] <feed-url|feed-id>")
+	}
+
+	ctx := context.Background()
+	feed, err := lookupFeed(s, ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	follow, err := s.Db.RetrieveFeedFollow(ctx, database.RetrieveFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("You are not following %s", feed.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to look up follow: %w", err)
+	}
+
+	params := database.UpdateFeedFollowSettingsParams{
+		UserID:   user.ID,
+		FeedID:   feed.ID,
+		Title:    follow.Title,
+		Muted:    follow.Muted,
+		Notify:   follow.Notify,
+		Priority: follow.Priority,
+	}
+	changed := false
+	fs.Visit(func(f *flag.Flag) {
+		changed = true
+		switch f.Name {
+		case "title":
+			t := strings.TrimSpace(*title)
+			params.Title = sql.NullString{String: t, Valid: t != "" && t != feed.Name}
+		case "muted":
+			params.Muted = *muted
+		case "notify":
+			params.Notify = *notify
+		case "priority":
+			params.Priority = int32(*priority)
+		}
+	})
+	if params.Notify != "all" && params.Notify != "none" {
+		return fmt.Errorf("Invalid notify setting %q, expected all or none", params.Notify)
+	}
+
+	if changed {
+		if err := s.Db.UpdateFeedFollowSettings(ctx, params); err != nil {
+			return fmt.Errorf("Failed to update follow: %w", err)
+		}
+	}
+
+	fmt.Printf("#%d %s (%s)\n", feed.ID, feed.Name, feed.Url)
+	if params.Title.Valid {
+		fmt.Printf("  title:    %s\n", params.Title.String)
+	} else {
+		fmt.Printf("  title:    (feed name)\n")
+	}
+	fmt.Printf("  muted:    %t\n", params.Muted)
+	fmt.Printf("  notify:   %s\n", params.Notify)
+	fmt.Printf("  priority: %d\n", params.Priority)
+	return nil
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func TestDeleteFeedOwnership(t *testing.T) {
+	e := newTestEnv(t)
+	ann := e.addUser("ann")
+	bob := e.addUser("bob")
+	feed := e.addFeed(ann, "Ann's Blog", "https://ann.example.com/feed")
+	e.follow(ann, feed)
+	e.follow(bob, feed)
+	e.addPost(feed, "first")
+	e.addPost(feed, "second")
+	id := strconv.Itoa(int(feed.ID))
+
+	for _, ref := range []string{id, feed.Url} {
+		_, err := e.run(bob, handlerDeleteFeed, "-yes", ref)
+		if err == nil || !strings.Contains(err.Error(), "added by another user") {
+			t.Errorf("bob deleting %s: got %v, want an ownership error", ref, err)
+		}
+	}
+	if _, err := e.run(bob, handlerDeleteFeed, "-dry-run", id); err == nil {
+		t.Error("bob could dry-run deleting ann's feed")
+	}
+
+	out, err := e.run(ann, handlerDeleteFeed, "-dry-run", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"2 follows", "2 posts", "Dry run, nothing deleted"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dry run output %q is missing %q", out, want)
+		}
+	}
+	if _, err := e.db.RetrieveFeedByID(e.ctx, feed.ID); err != nil {
+		t.Fatalf("feed gone before ann deleted it: %v", err)
+	}
+
+	if _, err := e.run(ann, handlerDeleteFeed, "-yes", feed.Url); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.db.RetrieveFeedByID(e.ctx, feed.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("feed still there after ann deleted it: %v", err)
+	}
+	posts, err := e.db.RetrievePostsForUser(e.ctx, database.RetrievePostsForUserParams{UserID: bob.ID, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 0 {
+		t.Errorf("bob still sees %d posts from the deleted feed", len(posts))
+	}
+}
+
+func TestFeedSettingsOwnership(t *testing.T) {
+	e := newTestEnv(t)
+	ann := e.addUser("ann")
+	bob := e.addUser("bob")
+	feed := e.addFeed(ann, "Ann's Blog", "https://ann.example.com/feed")
+	e.follow(bob, feed)
+
+	commands := []struct {
+		name    string
+		handler func(*State, Command, database.User) error
+		args    []string
+	}{
+		{"editfeed", handlerEditFeed, []string{"-name", "Renamed", feed.Url}},
+		{"fullcontent", handlerFullContent, []string{feed.Url, "on"}},
+	}
+	for _, c := range commands {
+		if _, err := e.run(bob, c.handler, c.args...); err == nil || !strings.Contains(err.Error(), "added by another user") {
+			t.Errorf("bob running %s on ann's feed: got %v, want an ownership error", c.name, err)
+		}
+		if _, err := e.run(ann, c.handler, c.args...); err != nil {
+			t.Errorf("ann running %s on their own feed: %v", c.name, err)
+		}
+	}
+
+	got, err := e.db.RetrieveFeedByID(e.ctx, feed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Renamed" || !got.FetchFullContent {
+		t.Errorf("feed after ann's changes: %+v", got)
+	}
+}
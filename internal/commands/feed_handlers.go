@@ -4,17 +4,34 @@ import (
 	"context"
 	"database/sql"
 	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
 	"html"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/sanntintdev/gator/internal/alerts"
+	"github.com/sanntintdev/gator/internal/auth"
 	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/dates"
+	"github.com/sanntintdev/gator/internal/digest"
+	"github.com/sanntintdev/gator/internal/filters"
+	"github.com/sanntintdev/gator/internal/metrics"
+	"github.com/sanntintdev/gator/internal/outfeed"
+	"github.com/sanntintdev/gator/internal/richtext"
+	"github.com/sanntintdev/gator/internal/scrape"
+	"github.com/sanntintdev/gator/internal/webhooks"
 )
 
 type RSSFeed struct {
@@ -27,164 +44,443 @@ type RSSFeed struct {
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+// author prefers dc:creator, which feeds use for a name, over the RSS
+// author element, which is meant to hold an email address.
+func (item RSSItem) author() string {
+	if creator := strings.TrimSpace(item.Creator); creator != "" {
+		return creator
+	}
+	return strings.TrimSpace(item.Author)
 }
 
-func FetchFeed(ctx context.Context, url string) (*RSSFeed, error) {
+// Kinds of fetch failure recorded in feed_fetches.error_kind.
+const (
+	FetchErrorNetwork = "network"
+	FetchErrorHTTP    = "http"
+	FetchErrorRead    = "read"
+	FetchErrorParse   = "parse"
+)
+
+// Redirect is one hop of a redirect chain followed while fetching.
+type Redirect struct {
+	StatusCode int
+	URL        string
+}
+
+// FetchInfo describes the HTTP side of a fetch, filled in as far as the
+// fetch got even when it fails.
+type FetchInfo struct {
+	StatusCode  int
+	Bytes       int
+	ContentType string
+	FinalURL    string
+	ErrorKind   string
+	Redirects   []Redirect
+	// PermanentRedirect is the final URL when every redirect followed was a
+	// 301 or 308, and empty otherwise.
+	PermanentRedirect string
+}
+
+func FetchFeed(ctx context.Context, url string) (*RSSFeed, FetchInfo, error) {
+	data, info, err := download(ctx, url)
+	if err != nil {
+		return nil, info, err
+	}
+
+	data, err = toUTF8(data, info.ContentType)
+	if err != nil {
+		metrics.ParseFailures.Inc()
+		info.ErrorKind = FetchErrorParse
+		return nil, info, err
+	}
+
+	var feed RSSFeed
+	err = xml.Unmarshal(data, &feed)
+	if err != nil {
+		metrics.ParseFailures.Inc()
+		info.ErrorKind = FetchErrorParse
+		return nil, info, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
+	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
+
+	for i := range feed.Channel.Item {
+		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
+		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+	}
+
+	return &feed, info, nil
+}
+
+// download GETs url, following redirects, and returns the body of a 200
+// response. Metrics are recorded for every attempt.
+func download(ctx context.Context, url string) ([]byte, FetchInfo, error) {
+	var info FetchInfo
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, err
+		info.ErrorKind = FetchErrorNetwork
+		return nil, info, err
 	}
 	req.Header.Set("User-Agent", "gator")
 
 	client := &http.Client{
 		Timeout: 10 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			info.Redirects = append(info.Redirects, Redirect{
+				StatusCode: req.Response.StatusCode,
+				URL:        req.URL.String(),
+			})
+			return nil
+		},
 	}
+	start := time.Now()
 	res, err := client.Do(req)
 
 	if err != nil {
-		return nil, err
+		metrics.ObserveFetch(0, time.Since(start), 0)
+		info.ErrorKind = FetchErrorNetwork
+		return nil, info, err
 	}
 	defer res.Body.Close()
+	info.StatusCode = res.StatusCode
+	info.FinalURL = res.Request.URL.String()
+	info.ContentType = res.Header.Get("Content-Type")
+	info.PermanentRedirect = permanentTarget(info.Redirects)
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+		metrics.ObserveFetch(res.StatusCode, time.Since(start), 0)
+		info.ErrorKind = FetchErrorHTTP
+		return nil, info, fmt.Errorf("unexpected status code: %d", res.StatusCode)
 	}
 
 	data, err := io.ReadAll(res.Body)
+	info.Bytes = len(data)
+	metrics.ObserveFetch(res.StatusCode, time.Since(start), len(data))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		info.ErrorKind = FetchErrorRead
+		return nil, info, fmt.Errorf("failed to read response body: %w", err)
 	}
+	return data, info, nil
+}
 
-	var feed RSSFeed
-	err = xml.Unmarshal(data, &feed)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+// permanentTarget returns where a redirect chain ends if every hop in it was
+// permanent.
+func permanentTarget(chain []Redirect) string {
+	if len(chain) == 0 {
+		return ""
 	}
-
-	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
-	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
-
-	for i := range feed.Channel.Item {
-		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
-		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+	for _, hop := range chain {
+		if hop.StatusCode != http.StatusMovedPermanently && hop.StatusCode != http.StatusPermanentRedirect {
+			return ""
+		}
 	}
-
-	return &feed, nil
+	return chain[len(chain)-1].URL
 }
 
+// fetchHistoryRetention is how long rows in feed_fetches are kept.
+const fetchHistoryRetention = 30 * 24 * time.Hour
+
 func ScrapeFeeds(s *State) error {
 	ctx := context.Background()
 	feed, err := s.Db.RetrieveNextFeedToFetch(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.Logger.Info("no feeds to fetch")
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("couldn't get next feed: %w", err)
 	}
 
+	logger := feedLogger(s, feed)
+
+	lastFetched := feed.CreatedAt
+	if feed.LastFetchedAt.Valid {
+		lastFetched = feed.LastFetchedAt.Time
+	}
+	metrics.ObserveSchedulerLag(time.Since(lastFetched))
+
 	err = s.Db.MarkFeedFetched(ctx, feed.ID)
 	if err != nil {
 		return fmt.Errorf("couldn't mark feed as fetched: %w", err)
 	}
 
-	fmt.Printf("Fetching feed: %s from %s\n", feed.Name, feed.Url)
-	rssFeed, err := FetchFeed(ctx, feed.Url)
+	logger.Info("fetching feed", "feed_name", feed.Name)
+	fetch := database.CreateFeedFetchParams{
+		FeedID:    feed.ID,
+		StartedAt: time.Now().UTC(),
+	}
+	rssFeed, info, err := fetchFeedItems(ctx, feed)
+	fetch.StatusCode = sql.NullInt32{Int32: int32(info.StatusCode), Valid: info.StatusCode != 0}
+	fetch.Bytes = int32(info.Bytes)
+	fetch.RedirectUrl = sql.NullString{String: info.PermanentRedirect, Valid: info.PermanentRedirect != ""}
+	if len(info.Redirects) > 0 {
+		logger.Info("feed redirected", "chain", formatRedirects(info.Redirects))
+	}
 	if err != nil {
-		return fmt.Errorf("failed to fetch feed: %w", err)
+		metrics.FetchAttempts.WithLabelValues("error").Inc()
+		fetch.Error = sql.NullString{String: err.Error(), Valid: true}
+		fetch.ErrorKind = sql.NullString{String: info.ErrorKind, Valid: info.ErrorKind != ""}
+		recordFetch(s, ctx, feed, fetch)
+		if info.StatusCode == http.StatusGone {
+			markFeedGone(s, ctx, feed)
+		}
+		return fmt.Errorf("failed to fetch feed %s: %w", feed.Url, err)
 	}
+	metrics.FetchAttempts.WithLabelValues("success").Inc()
 
-	fmt.Printf("\n=== %s ===\n", rssFeed.Channel.Title)
-	fmt.Printf("Found %d posts:\n\n", len(rssFeed.Channel.Item))
+	logger.Info("fetched feed",
+		"channel_title", rssFeed.Channel.Title,
+		"items", len(rssFeed.Channel.Item),
+	)
 
-	for i, item := range rssFeed.Channel.Item {
-		err := savePost(s, ctx, item, feed.ID)
+	fetch.ItemCount = int32(len(rssFeed.Channel.Item))
+	for _, item := range rssFeed.Channel.Item {
+		logger.Debug("found post", "title", item.Title, "url", item.Link, "published", item.PubDate)
+		created, err := savePost(s, ctx, item, feed, fetch.StartedAt)
 		if err != nil {
-			log.Printf("Error saving post %s: %v", item.Title, err)
+			logger.Error("failed to save post", "title", item.Title, "url", item.Link, "error", err)
+			continue
 		}
-		fmt.Printf("%d. %s\n", i+1, item.Title)
-		fmt.Printf("   Link: %s\n", item.Link)
-		if item.PubDate != "" {
-			fmt.Printf("   Published: %s\n", item.PubDate)
+		if created {
+			fetch.NewPostCount++
 		}
-		fmt.Println()
 	}
 
+	recordFetch(s, ctx, feed, fetch)
+	if info.PermanentRedirect != "" {
+		followPermanentRedirect(s, ctx, feed, info.PermanentRedirect)
+	}
 	return nil
 }
 
-func savePost(s *State, ctx context.Context, rssItem RSSItem, feedId int32) error {
-	publishedAt, err := parsePublishedDate(rssItem.PubDate)
+// recordFetch stores a fetch attempt and trims the feed's history past the
+// retention window. Failures are logged rather than failing the scrape.
+func recordFetch(s *State, ctx context.Context, feed database.Feed, fetch database.CreateFeedFetchParams) {
+	logger := feedLogger(s, feed)
+
+	fetch.FinishedAt = time.Now().UTC()
+	if err := s.Db.CreateFeedFetch(ctx, fetch); err != nil {
+		logger.Error("failed to record fetch", "error", err)
+		return
+	}
+
+	trimmed, err := s.Db.DeleteFeedFetchesBefore(ctx, database.DeleteFeedFetchesBeforeParams{
+		FeedID:    feed.ID,
+		StartedAt: fetch.StartedAt.Add(-fetchHistoryRetention),
+	})
 	if err != nil {
-		log.Printf("Warning: couldn't parse date '%s' for post '%s': %v",
-			rssItem.PubDate, rssItem.Title, err)
-		publishedAt = nil
+		logger.Error("failed to trim fetch history", "error", err)
+		return
+	}
+	if trimmed > 0 {
+		logger.Debug("trimmed fetch history", "rows", trimmed)
 	}
+}
 
-	var sqlPublishedAt sql.NullTime
-	if publishedAt != nil {
-		sqlPublishedAt = sql.NullTime{
-			Time:  *publishedAt,
-			Valid: true,
+// savePost stores rssItem and reports whether it was new. Items without a
+// usable date are stamped with fetchedAt.
+func savePost(s *State, ctx context.Context, rssItem RSSItem, feed database.Feed, fetchedAt time.Time) (bool, error) {
+	logger := feedLogger(s, feed)
+
+	publishedAt, dateFlag := publishedDate(rssItem.PubDate, fetchedAt)
+	switch dateFlag {
+	case dateFlagMissing:
+		logger.Debug("post has no date, using fetch time", "title", rssItem.Title)
+	case dateFlagUnparsed, dateFlagFuture:
+		logger.Warn("questionable post date",
+			"title", rssItem.Title,
+			"date", rssItem.PubDate,
+			"flag", dateFlag,
+		)
+	}
+
+	description := richtext.Sanitize(rssItem.Description)
+
+	// The post, its categories, its users' filter rules, alerts and webhook
+	// deliveries are saved together, so a post is either stored in full or
+	// fetched again later.
+	var postID int32
+	var queued, filtered, alerted int
+	err := s.Db.InTx(ctx, func(q database.Querier) error {
+		var err error
+		postID, err = q.CreatePost(ctx, database.CreatePostParams{
+			Title:       rssItem.Title,
+			Url:         rssItem.Link,
+			Description: description,
+			PublishedAt: sql.NullTime{Time: publishedAt, Valid: true},
+			FeedID:      feed.ID,
+			DateFlag:    sql.NullString{String: dateFlag, Valid: dateFlag != ""},
+			Excerpt:     richtext.Excerpt(description, excerptLength),
+			Author:      rssItem.author(),
+		})
+		if err != nil {
+			return err
 		}
-	}
-	_, err = s.Db.CreatePost(ctx, database.CreatePostParams{
-		Title:       rssItem.Title,
-		Url:         rssItem.Link,
-		Description: rssItem.Description,
-		PublishedAt: sqlPublishedAt,
-		FeedID:      feedId,
+
+		var categories []string
+		for _, category := range rssItem.Categories {
+			category = strings.TrimSpace(html.UnescapeString(category))
+			if category == "" {
+				continue
+			}
+			categories = append(categories, category)
+			err = q.CreatePostCategory(ctx, database.CreatePostCategoryParams{
+				PostID: postID,
+				Name:   category,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to save category %s: %w", category, err)
+			}
+		}
+
+		post := filters.Post{
+			ID:          postID,
+			Title:       rssItem.Title,
+			Description: description,
+			Author:      rssItem.author(),
+			Categories:  categories,
+		}
+		filtered, err = filters.Apply(ctx, q, feed, post)
+		if err != nil {
+			return fmt.Errorf("failed to apply filter rules: %w", err)
+		}
+
+		alerted, err = alerts.Enqueue(ctx, q, feed, post)
+		if err != nil {
+			return fmt.Errorf("failed to queue alerts: %w", err)
+		}
+
+		queued, err = webhooks.Enqueue(ctx, q, feed, webhooks.Post{
+			ID:          postID,
+			Title:       rssItem.Title,
+			URL:         rssItem.Link,
+			Description: description,
+			PublishedAt: &publishedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to queue webhooks: %w", err)
+		}
+		return nil
 	})
 
 	if err != nil {
-		if isDuplicateURLError(err) {
-			return nil
+		if isUniqueViolation(err, postsURLKey) {
+			metrics.DuplicatePosts.Inc()
+			return false, nil
 		}
-		return fmt.Errorf("database insertion failed: %w", err)
+		return false, fmt.Errorf("failed to save post: %w", err)
 	}
 
-	fmt.Printf("✓ Saved: %s\n", rssItem.Title)
-	return nil
+	metrics.PostsSaved.Inc()
+
+	// Articles are downloaded after the post is committed, so a slow site
+	// never holds a transaction open.
+	if feed.FetchFullContent && rssItem.Link != "" {
+		saveFullContent(s, ctx, feed, postID, rssItem.Link)
+	}
 
+	logger.Info("saved post",
+		"post_id", postID,
+		"title", rssItem.Title,
+		"url", rssItem.Link,
+		"filter_rules_matched", filtered,
+		"alerts_queued", alerted,
+		"webhooks_queued", queued,
+	)
+	return true, nil
 }
 
-func isDuplicateURLError(err error) bool {
-	if pqErr, ok := err.(*pq.Error); ok {
-		return pqErr.Code == "23505"
-	}
-	return strings.Contains(err.Error(), "UNIQUE constraint failed") ||
-		strings.Contains(err.Error(), "duplicate key")
+// feedLogger returns the state's logger annotated with the feed being processed.
+func feedLogger(s *State, feed database.Feed) *slog.Logger {
+	return s.Logger.With("feed_id", feed.ID, "feed_url", feed.Url)
 }
 
-func parsePublishedDate(dateStr string) (*time.Time, error) {
-	if dateStr == "" {
-		return nil, nil
-	}
+// Unique constraints that handlers turn into friendlier outcomes, by their
+// Postgres names.
+const (
+	postsURLKey        = "posts_url_key"
+	foldersUserNameKey = "folders_user_id_name_key"
+)
 
-	// Common RSS/Atom date formats to try
-	formats := []string{
-		time.RFC1123Z, // "Mon, 02 Jan 2006 15:04:05 -0700"
-		time.RFC1123,  // "Mon, 02 Jan 2006 15:04:05 MST"
-		time.RFC3339,  // "2006-01-02T15:04:05Z07:00"
-		time.RFC822Z,  // "02 Jan 06 15:04 -0700"
-		time.RFC822,   // "02 Jan 06 15:04 MST"
+// sqliteUniqueColumns is how SQLite, which doesn't name its constraints,
+// reports a violation of each of them.
+var sqliteUniqueColumns = map[string]string{
+	postsURLKey:        "posts.url",
+	foldersUserNameKey: "folders.user_id, folders.name",
+}
+
+// isUniqueViolation reports whether err violates the unique constraint with
+// the given name, and not some other one.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505" && pqErr.Constraint == constraint
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		columns, ok := sqliteUniqueColumns[constraint]
+		return ok && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE &&
+			strings.Contains(sqliteErr.Error(), "UNIQUE constraint failed: "+columns+" (")
 	}
+	return false
+}
 
-	for _, format := range formats {
-		if t, err := time.Parse(format, dateStr); err == nil {
-			return &t, nil
-		}
+// excerptLength is the maximum length of the plain-text excerpt stored with
+// each post.
+const excerptLength = 280
+
+// Values stored in posts.date_flag.
+const (
+	dateFlagMissing  = "missing"
+	dateFlagUnparsed = "unparsed"
+	dateFlagFuture   = "future"
+)
+
+// futureDateTolerance absorbs clock skew before a date counts as future.
+const futureDateTolerance = time.Hour
+
+// publishedDate interprets an item's date, falling back to fetchedAt when it
+// is missing or unparseable. The flag is empty for dates taken as given.
+func publishedDate(value string, fetchedAt time.Time) (time.Time, string) {
+	if strings.TrimSpace(value) == "" {
+		return fetchedAt, dateFlagMissing
 	}
 
-	return nil, fmt.Errorf("couldn't parse date format: %s", dateStr)
+	t, err := dates.Parse(value)
+	if err != nil {
+		return fetchedAt, dateFlagUnparsed
+	}
+	if t.After(fetchedAt.Add(futureDateTolerance)) {
+		return t, dateFlagFuture
+	}
+	return t, ""
 }
 
 func handlerAgg(s *State, cmd Command) error {
-	if len(cmd.Args) != 1 {
+	fs := flag.NewFlagSet("agg", flag.ContinueOnError)
+	listen := fs.String("listen", "", "address to serve /metrics, /healthz and /readyz on, e.g. :9090")
+	readyThreshold := fs.Duration("ready-threshold", 0, "max age of the last successful scrape before /readyz fails (default 3x the interval)")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
 		return fmt.Errorf("Invalid number of arguments")
 	}
 
-	timeBetweenRequest, err := time.ParseDuration(cmd.Args[0])
+	timeBetweenRequest, err := time.ParseDuration(fs.Arg(0))
 	if err != nil {
 		return fmt.Errorf("Invalid time format: %w", err)
 	}
@@ -193,32 +489,90 @@ func handlerAgg(s *State, cmd Command) error {
 		return fmt.Errorf("time between requests must be at least 1s")
 	}
 
-	fmt.Printf("Collecting feeds every %s\n", timeBetweenRequest)
+	if *readyThreshold <= 0 {
+		*readyThreshold = 3 * timeBetweenRequest
+	}
+	status := newAggStatus(*readyThreshold)
+
+	if *listen != "" {
+		if err := startAggServer(s, status, *listen); err != nil {
+			return fmt.Errorf("failed to start listener: %w", err)
+		}
+		s.Logger.Info("serving metrics and health checks", "addr", *listen)
+	}
+
+	s.Logger.Info("collecting feeds", "interval", timeBetweenRequest)
 	fmt.Println("Press Ctrl+C to stop")
 
+	dispatcher := webhooks.NewDispatcher(s.Db)
+	notifier := alerts.NewNotifier(s.Db, mailSender(s.Cfg), os.Stdout, alertPolicy(s.Cfg))
+	// Digests are only sent once an SMTP server is configured.
+	var mailer *digest.Mailer
+	if sender := mailSender(s.Cfg); sender.Configured() {
+		mailer = digest.NewMailer(s.Db, sender)
+	}
+
 	tiker := time.NewTicker(timeBetweenRequest)
 	defer tiker.Stop()
 
 	for ; ; <-tiker.C {
-		fmt.Println("Fetching feeds...")
 		err := ScrapeFeeds(s)
+		status.recordCycle(err)
 		if err != nil {
-			fmt.Printf("Error fetching feeds: %v\n", err)
+			s.Logger.Error("scrape cycle failed", "error", err)
+		}
+
+		delivered, failed, err := dispatcher.DeliverDue(context.Background())
+		if err != nil {
+			s.Logger.Error("webhook delivery failed", "error", err)
+		}
+		if delivered > 0 || failed > 0 {
+			s.Logger.Info("delivered webhooks", "delivered", delivered, "failed", failed)
+		}
+
+		sent, failed, err := notifier.DeliverPending(context.Background())
+		if err != nil {
+			s.Logger.Error("alert delivery failed", "error", err)
+		}
+		if sent > 0 || failed > 0 {
+			s.Logger.Info("sent alerts", "sent", sent, "failed", failed)
+		}
+
+		if mailer != nil {
+			sent, err := mailer.SendDue(context.Background())
+			if err != nil {
+				s.Logger.Error("digest delivery failed", "error", err)
+			}
+			if sent > 0 {
+				s.Logger.Info("sent digests", "sent", sent)
+			}
 		}
 	}
 
 }
 
 func handlerCreateFeed(s *State, cmd Command, user database.User) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if len(cmd.Args) != 2 {
+	fs := flag.NewFlagSet("addfeed", flag.ContinueOnError)
+	fullContent := fs.Bool("full-content", false, "download the linked article for each new post")
+	rules := scrapeRuleFlags(fs)
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
 		return fmt.Errorf("Invalid number of arguments")
 	}
+	scraped := *rules != scrape.Rules{}
+	if scraped {
+		if err := rules.Validate(); err != nil {
+			return err
+		}
+	}
 
-	name := cmd.Args[0]
-	feedUrl := cmd.Args[1]
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	name := fs.Arg(0)
+	feedUrl := fs.Arg(1)
 
 	now := time.Now()
 	createFeedParams := database.CreateFeedParams{
@@ -229,20 +583,44 @@ func handlerCreateFeed(s *State, cmd Command, user database.User) error {
 		UpdatedAt: now,
 	}
 
-	createdFeed, err := s.Db.CreateFeed(ctx, createFeedParams)
-	if err != nil {
-		return fmt.Errorf("Failed to create feed: %w", err)
-	}
+	// The feed, its settings and the follow are saved together so that a
+	// failure never leaves a feed nobody follows.
+	err := s.Db.InTx(ctx, func(q database.Querier) error {
+		createdFeed, err := q.CreateFeed(ctx, createFeedParams)
+		if err != nil {
+			return fmt.Errorf("Failed to create feed: %w", err)
+		}
 
-	// Created follow feed records
-	followFeedParams := database.CreateFeedFollowParams{
-		UserID: user.ID,
-		FeedID: createdFeed.ID,
-	}
+		if scraped {
+			if err := setScrapeRules(ctx, q, createdFeed.ID, *rules); err != nil {
+				return fmt.Errorf("Failed to save scrape rules: %w", err)
+			}
+		}
+
+		if *fullContent {
+			err = q.SetFeedFullContent(ctx, database.SetFeedFullContentParams{
+				ID:               createdFeed.ID,
+				FetchFullContent: true,
+			})
+			if err != nil {
+				return fmt.Errorf("Failed to enable full content: %w", err)
+			}
+		}
+
+		// Created follow feed records
+		followFeedParams := database.CreateFeedFollowParams{
+			UserID: user.ID,
+			FeedID: createdFeed.ID,
+		}
 
-	_, err = s.Db.CreateFeedFollow(ctx, followFeedParams)
+		_, err = q.CreateFeedFollow(ctx, followFeedParams)
+		if err != nil {
+			return fmt.Errorf("Failed to create follow feed record: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("Failed to create follow feed record: %w", err)
+		return err
 	}
 
 	fmt.Printf("Feed created successfully.")
@@ -324,44 +702,216 @@ func handlerUnfollowFeed(s *State, cmd Command, user database.User) error {
 	return nil
 }
 
-func handlerBrowse(s *State, cmd Command) error {
-	if len(cmd.Args) != 1 {
+// handlerBrowse shows the newest posts from the feeds the user follows,
+// leaving out muted feeds and posts hidden by filter rules.
+func handlerBrowse(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("browse", flag.ContinueOnError)
+	folderName := fs.String("folder", "", "only show posts from feeds in this folder")
+	unread := fs.Bool("unread", false, "only show posts not marked read")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
 		return fmt.Errorf("Invalid number of arguments")
 	}
 
-	limit, err := strconv.ParseInt(cmd.Args[0], 10, 32)
+	limit, err := strconv.ParseInt(fs.Arg(0), 10, 32)
 
 	if err != nil {
 		return fmt.Errorf("Invalid limit: %w", err)
 	}
 
 	ctx := context.Background()
-	posts, err := s.Db.RetrievePostsForUser(ctx, int32(limit))
-
-	if err != nil {
-		return fmt.Errorf("Invalid feed URL: %w", err)
+	var posts []database.Post
+	if *folderName != "" {
+		posts, err = browseFolder(s, ctx, user, *folderName, *unread, int32(limit))
+		if err != nil {
+			return err
+		}
+	} else {
+		posts, err = s.Db.RetrievePostsForUser(ctx, database.RetrievePostsForUserParams{
+			UserID:     user.ID,
+			UnreadOnly: *unread,
+			Limit:      int32(limit),
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to retrieve posts: %w", err)
+		}
 	}
 
 	for _, post := range posts {
 		fmt.Printf("%s\n", post.Title)
-		fmt.Printf("%s\n", post.Description)
-		fmt.Printf("%s\n", post.PublishedAt.Time)
+		body := post.Description
+		if post.FullContent.Valid {
+			body = post.FullContent.String
+		}
+		fmt.Printf("%s\n", richtext.Text(body))
+		if post.DateFlag.Valid {
+			fmt.Printf("%s (date %s)\n", post.PublishedAt.Time, post.DateFlag.String)
+		} else {
+			fmt.Printf("%s\n", post.PublishedAt.Time)
+		}
 	}
 
 	return nil
 }
 
+// browseFolder returns the newest posts in one of the user's folders.
+func browseFolder(s *State, ctx context.Context, user database.User, name string, unread bool, limit int32) ([]database.Post, error) {
+	folder, err := lookupFolder(s.Db, ctx, user, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Db.RetrievePostsInFolder(ctx, database.RetrievePostsInFolderParams{
+		FolderID:   sql.NullInt32{Int32: folder.ID, Valid: true},
+		UnreadOnly: unread,
+		Limit:      limit,
+	})
+}
+
+func handlerOutfeed(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("outfeed", flag.ContinueOnError)
+	format := fs.String("format", outfeed.FormatRSS, "output format: rss or atom")
+	category := fs.String("category", "", "only include posts in this category")
+	keyword := fs.String("keyword", "", "only include posts mentioning this keyword")
+	tag := fs.String("tag", "", "only include posts tagged by a filter rule")
+	limit := fs.Int("limit", outfeed.DefaultLimit, "maximum number of posts")
+	link := fs.String("link", "", "canonical URL of the generated feed")
+	showURL := fs.Bool("url", false, "print the URL gator serve publishes this feed at, instead of the feed")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	ctx := context.Background()
+	if *showURL {
+		feedURL, err := outfeedURL(s, ctx, user, *format)
+		if err != nil {
+			return err
+		}
+		fmt.Println(feedURL)
+		return nil
+	}
+	if *link == "" {
+		// The served URL minus its token: the feed is often shared, and the
+		// token would let anyone who reads it follow along.
+		*link = fmt.Sprintf("http://localhost%s/outfeed/%s/%s", defaultServeAddr, url.PathEscape(user.Name), *format)
+	}
+
+	feed, err := outfeed.Build(ctx, s.Db, user, outfeed.Options{
+		Category: *category,
+		Keyword:  *keyword,
+		Tag:      *tag,
+		Limit:    int32(*limit),
+		Link:     *link,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to build feed: %w", err)
+	}
+
+	return outfeed.Write(os.Stdout, *format, feed)
+}
+
+// outfeedURL returns the address gator serve publishes the user's timeline
+// at, including the secret token derived from their API password.
+func outfeedURL(s *State, ctx context.Context, user database.User, format string) (string, error) {
+	creds, err := s.Db.GetUserCredentials(ctx, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errors.New("Set a password with 'gator passwd' to publish your timeline")
+	}
+	if err != nil {
+		return "", fmt.Errorf("Failed to retrieve credentials: %w", err)
+	}
+	query := url.Values{"token": {auth.FeedToken(user.Name, creds.PasswordHash)}}
+	return fmt.Sprintf("http://localhost%s/outfeed/%s/%s?%s", defaultServeAddr, url.PathEscape(user.Name), format, query.Encode()), nil
+}
+
+func handlerFetchLog(s *State, cmd Command) error {
+	fs := flag.NewFlagSet("fetchlog", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "maximum number of fetches to show")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	ctx := context.Background()
+	feed, err := lookupFeed(s, ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	fetches, err := s.Db.RetrieveFeedFetches(ctx, database.RetrieveFeedFetchesParams{
+		FeedID: feed.ID,
+		Limit:  int32(*limit),
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve fetch history: %w", err)
+	}
+
+	fmt.Printf("%s (%s)\n", feed.Name, feed.Url)
+	if len(fetches) == 0 {
+		fmt.Println("No fetches recorded")
+		return nil
+	}
+	for _, fetch := range fetches {
+		status := "-"
+		if fetch.StatusCode.Valid {
+			status = strconv.Itoa(int(fetch.StatusCode.Int32))
+		}
+		fmt.Printf("%s  status=%s bytes=%d items=%d new=%d took=%s\n",
+			fetch.StartedAt.Local().Format(time.DateTime),
+			status,
+			fetch.Bytes,
+			fetch.ItemCount,
+			fetch.NewPostCount,
+			fetch.FinishedAt.Sub(fetch.StartedAt).Round(time.Millisecond),
+		)
+		if fetch.Error.Valid {
+			fmt.Printf("  error: %s\n", fetch.Error.String)
+		}
+	}
+	return nil
+}
+
+// lookupFeed resolves a feed given either its numeric ID or its URL.
+func lookupFeed(s *State, ctx context.Context, ref string) (database.Feed, error) {
+	if id, err := strconv.ParseInt(ref, 10, 32); err == nil {
+		feed, err := s.Db.RetrieveFeedByID(ctx, int32(id))
+		if err != nil {
+			return database.Feed{}, fmt.Errorf("Feed %d not found: %w", id, err)
+		}
+		return feed, nil
+	}
+
+	feed, err := s.Db.RetrieveFeedWithURL(ctx, ref)
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("Feed %s not found: %w", ref, err)
+	}
+	return feed, nil
+}
+
 func RegisterFeedCommands(c *Commands) {
 	publicHandlers := map[string]func(*State, Command) error{
-		"agg":    handlerAgg,
-		"feeds":  handlerRetrieveFeeds,
-		"browse": handlerBrowse,
+		"agg":      handlerAgg,
+		"feeds":    handlerRetrieveFeeds,
+		"fetchlog": handlerFetchLog,
+		"testrule": handlerTestRule,
 	}
 
 	authHandlers := map[string]func(*State, Command, database.User) error{
-		"addfeed":  handlerCreateFeed,
-		"follow":   handlerFollowFeed,
-		"unfollow": handlerUnfollowFeed,
+		"addfeed":     handlerCreateFeed,
+		"follow":      handlerFollowFeed,
+		"unfollow":    handlerUnfollowFeed,
+		"browse":      handlerBrowse,
+		"outfeed":     handlerOutfeed,
+		"fullcontent": handlerFullContent,
+		"editfeed":    handlerEditFeed,
+		"deletefeed":  handlerDeleteFeed,
+		"editfollow":  handlerEditFollow,
 	}
 
 	for name, handler := range publicHandlers {
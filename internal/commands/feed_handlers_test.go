@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func TestFollowFeed(t *testing.T) {
+	e := newTestEnv(t)
+	ann := e.addUser("ann")
+	bob := e.addUser("bob")
+	feed := e.addFeed(ann, "Ann's Blog", "https://ann.example.com/feed")
+
+	out, err := e.run(bob, handlerFollowFeed, feed.Url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Feed Ann's Blog followed") || !strings.Contains(out, "Followed by bob") {
+		t.Errorf("unexpected output %q", out)
+	}
+	follows, err := e.db.RetrieveFeedFollowsForUser(e.ctx, bob.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 1 || follows[0].FeedID != feed.ID {
+		t.Errorf("bob follows %+v, want only %s", follows, feed.Name)
+	}
+
+	if _, err := e.run(bob, handlerFollowFeed, feed.Url); err == nil {
+		t.Error("following a feed twice succeeded")
+	}
+	if _, err := e.run(bob, handlerFollowFeed, "https://unknown.example.com/feed"); err == nil {
+		t.Error("following an unknown feed succeeded")
+	}
+	if _, err := e.run(bob, handlerFollowFeed); err == nil {
+		t.Error("follow without a URL succeeded")
+	}
+
+	if _, err := e.run(bob, handlerUnfollowFeed, feed.Url); err != nil {
+		t.Fatal(err)
+	}
+	follows, err = e.db.RetrieveFeedFollowsForUser(e.ctx, bob.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 0 {
+		t.Errorf("bob still follows %+v after unfollowing", follows)
+	}
+}
+
+func TestBrowse(t *testing.T) {
+	e := newTestEnv(t)
+	ann := e.addUser("ann")
+	bob := e.addUser("bob")
+	news := e.addFeed(ann, "News", "https://news.example.com/feed")
+	muted := e.addFeed(ann, "Muted", "https://muted.example.com/feed")
+	other := e.addFeed(ann, "Other", "https://other.example.com/feed")
+	e.follow(bob, news)
+	e.follow(bob, muted)
+	e.follow(ann, news)
+	e.follow(ann, other)
+
+	e.addPost(news, "news-old")
+	hidden := e.addPost(news, "news-hidden")
+	read := e.addPost(news, "news-read")
+	e.addPost(muted, "muted-post")
+	e.addPost(other, "other-post")
+	e.addPost(news, "news-new")
+
+	err := e.db.UpdateFeedFollowSettings(e.ctx, database.UpdateFeedFollowSettingsParams{
+		UserID: bob.ID, FeedID: muted.ID, Muted: true, Notify: "all",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.db.SetPostHidden(e.ctx, database.SetPostHiddenParams{UserID: bob.ID, PostID: hidden, IsHidden: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.db.SetPostRead(e.ctx, database.SetPostReadParams{UserID: bob.ID, PostID: read, IsRead: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		user database.User
+		args []string
+		want []string
+	}{
+		{"followed feeds only", bob, []string{"10"}, []string{"news-new", "news-read", "news-old"}},
+		{"limit keeps the newest", bob, []string{"2"}, []string{"news-new", "news-read"}},
+		{"unread only", bob, []string{"-unread", "10"}, []string{"news-new", "news-old"}},
+		{"other users' states don't apply", ann, []string{"10"}, []string{"news-new", "other-post", "news-read", "news-hidden", "news-old"}},
+	}
+	for _, tt := range tests {
+		out, err := e.run(tt.user, handlerBrowse, tt.args...)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := browsedTitles(out); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s: browse showed %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, args := range [][]string{{}, {"ten"}, {"-folder", "missing", "10"}} {
+		if _, err := e.run(bob, handlerBrowse, args...); err == nil {
+			t.Errorf("browse %v succeeded", args)
+		}
+	}
+}
+
+func TestBrowseFolder(t *testing.T) {
+	e := newTestEnv(t)
+	bob := e.addUser("bob")
+	filed := e.addFeed(bob, "Filed", "https://filed.example.com/feed")
+	unfiled := e.addFeed(bob, "Unfiled", "https://unfiled.example.com/feed")
+	e.follow(bob, filed)
+	e.follow(bob, unfiled)
+	e.addPost(filed, "filed-post")
+	e.addPost(unfiled, "unfiled-post")
+
+	if _, err := e.run(bob, handlerFolder, "create", "tech"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.run(bob, handlerFolder, "move", filed.Url, "tech"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := e.run(bob, handlerBrowse, "-folder", "tech", "10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := browsedTitles(out); strings.Join(got, " ") != "filed-post" {
+		t.Errorf("browse -folder showed %v, want [filed-post]", got)
+	}
+}
+
+// browsedTitles picks the post titles out of browse's output, relying on
+// addPost naming each post after its title.
+func browsedTitles(out string) []string {
+	var titles []string
+	lines := strings.Split(out, "\n")
+	for i := 0; i+1 < len(lines); i++ {
+		if lines[i] != "" && lines[i+1] == lines[i] {
+			titles = append(titles, lines[i])
+		}
+	}
+	return titles
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, url TEXT UNIQUE, title TEXT UNIQUE)",
+		"INSERT INTO posts (id, url, title) VALUES (1, 'a', 'a')",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, sqliteURL := db.Exec("INSERT INTO posts (id, url, title) VALUES (2, 'a', 'b')")
+	_, sqliteTitle := db.Exec("INSERT INTO posts (id, url, title) VALUES (2, 'b', 'a')")
+	_, sqlitePK := db.Exec("INSERT INTO posts (id, url, title) VALUES (1, 'c', 'c')")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"postgres", &pq.Error{Code: "23505", Constraint: postsURLKey}, true},
+		{"postgres wrapped", fmt.Errorf("insert: %w", &pq.Error{Code: "23505", Constraint: postsURLKey}), true},
+		{"postgres other constraint", &pq.Error{Code: "23505", Constraint: "posts_pkey"}, false},
+		{"postgres foreign key", &pq.Error{Code: "23503", Constraint: postsURLKey}, false},
+		{"sqlite", sqliteURL, true},
+		{"sqlite other column", sqliteTitle, false},
+		{"sqlite primary key", sqlitePK, false},
+		{"message only", errors.New(`duplicate key value violates unique constraint "posts_url_key"`), false},
+	}
+	for _, tt := range tests {
+		if got := isUniqueViolation(tt.err, postsURLKey); got != tt.want {
+			t.Errorf("%s: isUniqueViolation(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
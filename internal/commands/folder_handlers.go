@@ -0,0 +1,253 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func handlerFolder(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return errors.New("Usage: folder <list|create|rename|delete|move> [args]")
+	}
+
+	sub := Command{Name: cmd.Args[0], Args: cmd.Args[1:]}
+	switch sub.Name {
+	case "list":
+		return handlerFolderList(s, sub, user)
+	case "create":
+		return handlerFolderCreate(s, sub, user)
+	case "rename":
+		return handlerFolderRename(s, sub, user)
+	case "delete":
+		return handlerFolderDelete(s, sub, user)
+	case "move":
+		return handlerFolderMove(s, sub, user)
+	}
+
+	return fmt.Errorf("unknown folder command: %s", sub.Name)
+}
+
+// lookupFolder resolves one of user's folders by name.
+func lookupFolder(q database.Querier, ctx context.Context, user database.User, name string) (database.Folder, error) {
+	folder, err := q.RetrieveFolderByName(ctx, database.RetrieveFolderByNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Folder{}, fmt.Errorf("Folder %s not found", name)
+	}
+	if err != nil {
+		return database.Folder{}, fmt.Errorf("Failed to look up folder %s: %w", name, err)
+	}
+	return folder, nil
+}
+
+func validFolderName(name string) error {
+	if strings.TrimSpace(name) == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("Invalid folder name: %q", name)
+	}
+	return nil
+}
+
+func handlerFolderList(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return errors.New("Usage: folder list")
+	}
+
+	ctx := context.Background()
+	folders, err := s.Db.RetrieveFoldersForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to list folders: %w", err)
+	}
+	follows, err := s.Db.RetrieveFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to list followed feeds: %w", err)
+	}
+	if len(folders) == 0 {
+		fmt.Println("No folders")
+		return nil
+	}
+
+	feeds := map[int32]int{}
+	for _, ff := range follows {
+		if ff.FolderID.Valid {
+			feeds[ff.FolderID.Int32]++
+		}
+	}
+	for _, f := range folders {
+		fmt.Printf("%s (%d feeds)\n", f.Name, feeds[f.ID])
+	}
+	return nil
+}
+
+func handlerFolderCreate(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return errors.New("Usage: folder create <name>")
+	}
+	name := cmd.Args[0]
+	if err := validFolderName(name); err != nil {
+		return err
+	}
+
+	_, err := s.Db.CreateFolder(context.Background(), database.CreateFolderParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if err != nil {
+		if isUniqueViolation(err, foldersUserNameKey) {
+			return fmt.Errorf("Folder %s already exists", name)
+		}
+		return fmt.Errorf("Failed to create folder: %w", err)
+	}
+	fmt.Printf("Created folder %s\n", name)
+	return nil
+}
+
+func handlerFolderRename(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 2 {
+		return errors.New("Usage: folder rename <name> <new-name>")
+	}
+	name, newName := cmd.Args[0], cmd.Args[1]
+	if err := validFolderName(newName); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	err := s.Db.InTx(ctx, func(q database.Querier) error {
+		folder, err := lookupFolder(q, ctx, user, name)
+		if err != nil {
+			return err
+		}
+		if _, err := lookupFolder(q, ctx, user, newName); err == nil && newName != name {
+			return fmt.Errorf("Folder %s already exists", newName)
+		}
+		return q.RenameFolder(ctx, database.RenameFolderParams{
+			ID:   folder.ID,
+			Name: newName,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Renamed folder %s to %s\n", name, newName)
+	return nil
+}
+
+func handlerFolderDelete(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return errors.New("Usage: folder delete <name>")
+	}
+
+	ctx := context.Background()
+	folder, err := lookupFolder(s.Db, ctx, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	if err := s.Db.DeleteFolder(ctx, folder.ID); err != nil {
+		return fmt.Errorf("Failed to delete folder: %w", err)
+	}
+	fmt.Printf("Deleted folder %s; its feeds are now unfiled\n", folder.Name)
+	return nil
+}
+
+// handlerFolderMove files a followed feed under a folder, or takes it out
+// of its folder when none is given.
+func handlerFolderMove(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 && len(cmd.Args) != 2 {
+		return errors.New("Usage: folder move <feed-url|feed-id> [folder]")
+	}
+
+	ctx := context.Background()
+	feed, err := lookupFeed(s, ctx, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	var folderID sql.NullInt32
+	if len(cmd.Args) == 2 {
+		folder, err := lookupFolder(s.Db, ctx, user, cmd.Args[1])
+		if err != nil {
+			return err
+		}
+		folderID = sql.NullInt32{Int32: folder.ID, Valid: true}
+	}
+
+	n, err := s.Db.SetFeedFollowFolder(ctx, database.SetFeedFollowFolderParams{
+		UserID:   user.ID,
+		FeedID:   feed.ID,
+		FolderID: folderID,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to move feed: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("You are not following %s", feed.Name)
+	}
+
+	if folderID.Valid {
+		fmt.Printf("Moved %s to %s\n", feed.Name, cmd.Args[1])
+	} else {
+		fmt.Printf("Removed %s from its folder\n", feed.Name)
+	}
+	return nil
+}
+
+func handlerUnread(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("unread", flag.ContinueOnError)
+	folderName := fs.String("folder", "", "only count feeds in this folder")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("Usage: unread [-folder name]")
+	}
+
+	ctx := context.Background()
+	var folderID sql.NullInt32
+	if *folderName != "" {
+		folder, err := lookupFolder(s.Db, ctx, user, *folderName)
+		if err != nil {
+			return err
+		}
+		folderID = sql.NullInt32{Int32: folder.ID, Valid: true}
+	}
+
+	follows, err := s.Db.RetrieveFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to list followed feeds: %w", err)
+	}
+	counts, err := s.Db.CountUnreadPostsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to count unread posts: %w", err)
+	}
+	unread := map[int32]int64{}
+	for _, c := range counts {
+		unread[c.FeedID] = c.UnreadCount
+	}
+
+	var total int64
+	for _, ff := range follows {
+		if folderID.Valid && ff.FolderID != folderID {
+			continue
+		}
+		fmt.Printf("%6d  %s\n", unread[ff.FeedID], followLabel(ff))
+		// Muted feeds are listed but left out of the total, as they are
+		// left out of the reading list.
+		if !ff.Muted {
+			total += unread[ff.FeedID]
+		}
+	}
+	fmt.Printf("%6d  total\n", total)
+	return nil
+}
+
+func RegisterFolderCommands(c *Commands) {
+	c.register("folder", MiddlewareLoggedIn(handlerFolder))
+	c.register("unread", MiddlewareLoggedIn(handlerUnread))
+}
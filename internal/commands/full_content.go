@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/readability"
+)
+
+// maxArticleBytes caps how much of a linked article is downloaded.
+const maxArticleBytes = 5 << 20
+
+// fetchArticle downloads the page at link and extracts its main content.
+func fetchArticle(ctx context.Context, link string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "gator")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return "", fmt.Errorf("not an HTML page: %s", contentType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(res.Body, maxArticleBytes), contentType)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode page: %w", err)
+	}
+	return readability.Extract(body, res.Request.URL)
+}
+
+// saveFullContent fetches the article behind a new post and stores it.
+// Failures are logged; the post keeps its feed description.
+func saveFullContent(s *State, ctx context.Context, feed database.Feed, postID int32, link string) {
+	logger := feedLogger(s, feed).With("post_id", postID, "url", link)
+
+	article, err := fetchArticle(ctx, link)
+	if err != nil {
+		logger.Warn("failed to fetch full content", "error", err)
+		return
+	}
+
+	err = s.Db.SetPostFullContent(ctx, database.SetPostFullContentParams{
+		ID:          postID,
+		FullContent: sql.NullString{String: article, Valid: true},
+	})
+	if err != nil {
+		logger.Error("failed to save full content", "error", err)
+		return
+	}
+	logger.Debug("saved full content", "bytes", len(article))
+}
+
+func handlerFullContent(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("Usage: fullcontent <feed-url|feed-id> <on|off>")
+	}
+
+	var enabled bool
+	switch cmd.Args[1] {
+	case "on":
+		enabled = true
+	case "off":
+	default:
+		return fmt.Errorf("Invalid setting %q, expected on or off", cmd.Args[1])
+	}
+
+	ctx := context.Background()
+	feed, err := ownedFeed(s, ctx, cmd.Args[0], user)
+	if err != nil {
+		return err
+	}
+
+	err = s.Db.SetFeedFullContent(ctx, database.SetFeedFullContentParams{
+		ID:               feed.ID,
+		FetchFullContent: enabled,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to update feed: %w", err)
+	}
+
+	fmt.Printf("Full content for %s turned %s\n", feed.Name, cmd.Args[1])
+	return nil
+}
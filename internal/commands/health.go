@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// aggStatus tracks scrape cycles so the health endpoints can tell whether
+// agg is still making progress.
+type aggStatus struct {
+	mu          sync.Mutex
+	startedAt   time.Time
+	threshold   time.Duration
+	cycles      int
+	failures    int
+	lastSuccess time.Time
+	lastError   string
+}
+
+func newAggStatus(threshold time.Duration) *aggStatus {
+	return &aggStatus{
+		startedAt: time.Now(),
+		threshold: threshold,
+	}
+}
+
+func (a *aggStatus) recordCycle(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.cycles++
+	if err != nil {
+		a.failures++
+		a.lastError = err.Error()
+		return
+	}
+	a.lastSuccess = time.Now()
+	a.lastError = ""
+}
+
+type healthResponse struct {
+	Status        string  `json:"status"`
+	Database      string  `json:"database"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+}
+
+type oldestFeed struct {
+	ID         int32   `json:"id"`
+	Name       string  `json:"name"`
+	URL        string  `json:"url"`
+	AgeSeconds float64 `json:"age_seconds"`
+}
+
+type readyResponse struct {
+	Status                string      `json:"status"`
+	Cycles                int         `json:"cycles"`
+	Failures              int         `json:"failures"`
+	LastSuccessAt         *time.Time  `json:"last_success_at"`
+	LastSuccessAgeSeconds *float64    `json:"last_success_age_seconds"`
+	ThresholdSeconds      float64     `json:"threshold_seconds"`
+	LastError             string      `json:"last_error,omitempty"`
+	OldestFeed            *oldestFeed `json:"oldest_feed,omitempty"`
+	OldestFeedError       string      `json:"oldest_feed_error,omitempty"`
+}
+
+// handleHealthz reports whether the process is up and the database answers.
+func (a *aggStatus) handleHealthz(s *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		response := healthResponse{
+			Status:        "ok",
+			Database:      "ok",
+			UptimeSeconds: time.Since(a.startedAt).Seconds(),
+		}
+		status := http.StatusOK
+
+		if err := s.Db.Ping(ctx); err != nil {
+			response.Status = "error"
+			response.Database = err.Error()
+			status = http.StatusServiceUnavailable
+		}
+
+		writeStatusJSON(w, status, response)
+	}
+}
+
+// handleReadyz reports whether a scrape cycle succeeded within the
+// threshold, along with how stale the most overdue feed is.
+func (a *aggStatus) handleReadyz(s *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		response := readyResponse{
+			Status:           "ready",
+			Cycles:           a.cycles,
+			Failures:         a.failures,
+			ThresholdSeconds: a.threshold.Seconds(),
+			LastError:        a.lastError,
+		}
+		lastSuccess := a.lastSuccess
+		a.mu.Unlock()
+
+		status := http.StatusOK
+		if lastSuccess.IsZero() {
+			response.Status = "not_ready"
+			status = http.StatusServiceUnavailable
+		} else {
+			age := time.Since(lastSuccess).Seconds()
+			response.LastSuccessAt = &lastSuccess
+			response.LastSuccessAgeSeconds = &age
+			if time.Since(lastSuccess) > a.threshold {
+				response.Status = "not_ready"
+				status = http.StatusServiceUnavailable
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		feed, err := s.Db.RetrieveNextFeedToFetch(ctx)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			response.OldestFeedError = err.Error()
+		default:
+			lastFetched := feed.CreatedAt
+			if feed.LastFetchedAt.Valid {
+				lastFetched = feed.LastFetchedAt.Time
+			}
+			response.OldestFeed = &oldestFeed{
+				ID:         feed.ID,
+				Name:       feed.Name,
+				URL:        feed.Url,
+				AgeSeconds: time.Since(lastFetched).Seconds(),
+			}
+		}
+
+		writeStatusJSON(w, status, response)
+	}
+}
+
+func writeStatusJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -16,6 +16,7 @@ func MiddlewareLoggedIn(handler func(s *State, cmd Command, user database.User)
 		if err != nil {
 			return fmt.Errorf("failed to get user %s: %w", username, err)
 		}
+		s.Logger = s.Logger.With("user", currentUser.Name)
 
 		return handler(s, cmd, currentUser)
 	}
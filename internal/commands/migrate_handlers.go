@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func handlerMigrate(s *State, cmd Command) error {
+	if len(cmd.Args) != 1 {
+		return errors.New("Usage: migrate <up|down|status>")
+	}
+	if s.Migrator == nil {
+		return errors.New("The in-memory database has no migrations")
+	}
+
+	ctx := context.Background()
+	switch cmd.Args[0] {
+	case "up":
+		applied, err := s.Migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("Applied %s\n", m.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to migrate database: %w", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database schema is up to date")
+		}
+		return nil
+	case "down":
+		m, err := s.Migrator.Down(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to roll back migration: %w", err)
+		}
+		fmt.Printf("Rolled back %s\n", m.Name)
+		return nil
+	case "status":
+		return migrateStatus(s, ctx)
+	}
+
+	return fmt.Errorf("unknown migrate command: %s", cmd.Args[0])
+}
+
+func migrateStatus(s *State, ctx context.Context) error {
+	statuses, err := s.Migrator.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to read migration status: %w", err)
+	}
+	current, latest, err := s.Migrator.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to read migration status: %w", err)
+	}
+
+	for _, st := range statuses {
+		applied := "pending"
+		if st.Applied {
+			applied = "applied"
+			if !st.AppliedAt.IsZero() {
+				applied = st.AppliedAt.Local().Format(time.DateTime)
+			}
+		}
+		fmt.Printf("%-19s  %s\n", applied, st.Name)
+	}
+	fmt.Printf("Schema version %d, latest %d\n", current, latest)
+	if current > latest {
+		fmt.Println("The database was migrated by a newer version of gator")
+	}
+	return nil
+}
+
+func RegisterMigrateCommands(c *Commands) {
+	c.register("migrate", handlerMigrate)
+}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+// redirectConfirmations is how many fetches in a row must be permanently
+// redirected to the same URL before the feed is moved there.
+const redirectConfirmations = 3
+
+func formatRedirects(chain []Redirect) string {
+	hops := make([]string, len(chain))
+	for i, hop := range chain {
+		hops[i] = fmt.Sprintf("%d %s", hop.StatusCode, hop.URL)
+	}
+	return strings.Join(hops, " -> ")
+}
+
+// followPermanentRedirect moves feed to target once the last few fetches
+// have all been permanently redirected there.
+func followPermanentRedirect(s *State, ctx context.Context, feed database.Feed, target string) {
+	logger := feedLogger(s, feed)
+
+	fetches, err := s.Db.RetrieveFeedFetches(ctx, database.RetrieveFeedFetchesParams{
+		FeedID: feed.ID,
+		Limit:  redirectConfirmations,
+	})
+	if err != nil {
+		logger.Error("failed to check redirect history", "error", err)
+		return
+	}
+	if len(fetches) < redirectConfirmations {
+		return
+	}
+	for _, fetch := range fetches {
+		if !fetch.RedirectUrl.Valid || fetch.RedirectUrl.String != target {
+			return
+		}
+	}
+
+	var merged bool
+	err = s.Db.InTx(ctx, func(q database.Querier) error {
+		var err error
+		merged, err = moveFeed(ctx, q, feed.ID, target)
+		return err
+	})
+	if err != nil {
+		logger.Error("failed to move feed", "target", target, "error", err)
+		return
+	}
+	if merged {
+		logger.Info("merged feed into existing feed at redirect target", "target", target)
+		return
+	}
+	logger.Info("moved feed to redirect target", "target", target)
+}
+
+// moveFeed changes a feed's URL to target. If another feed already lives at
+// target, the follows, posts, webhooks, filter rules and alerts of feedID are
+// merged into it and feedID is deleted; merged reports whether that happened.
+// Run it in a transaction so that a failed merge leaves both feeds as they
+// were.
+func moveFeed(ctx context.Context, q database.Querier, feedID int32, target string) (merged bool, err error) {
+	existing, err := q.RetrieveFeedWithURL(ctx, target)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = q.UpdateFeedURL(ctx, database.UpdateFeedURLParams{
+			ID:  feedID,
+			Url: target,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to update feed URL: %w", err)
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up %s: %w", target, err)
+	}
+	if existing.ID == feedID {
+		return false, nil
+	}
+
+	err = q.MoveFeedFollows(ctx, database.MoveFeedFollowsParams{
+		ToFeedID:   existing.ID,
+		FromFeedID: feedID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to move follows: %w", err)
+	}
+	if err := q.DeleteFeedFollowsForFeed(ctx, feedID); err != nil {
+		return false, fmt.Errorf("failed to delete old follows: %w", err)
+	}
+	err = q.MovePostsToFeed(ctx, database.MovePostsToFeedParams{
+		ToFeedID:   existing.ID,
+		FromFeedID: feedID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to move posts: %w", err)
+	}
+	err = q.MoveWebhooksToFeed(ctx, database.MoveWebhooksToFeedParams{
+		ToFeedID:   sql.NullInt32{Int32: existing.ID, Valid: true},
+		FromFeedID: sql.NullInt32{Int32: feedID, Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to move webhooks: %w", err)
+	}
+	err = q.MoveFilterRulesToFeed(ctx, database.MoveFilterRulesToFeedParams{
+		ToFeedID:   sql.NullInt32{Int32: existing.ID, Valid: true},
+		FromFeedID: sql.NullInt32{Int32: feedID, Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to move filter rules: %w", err)
+	}
+	err = q.MoveAlertsToFeed(ctx, database.MoveAlertsToFeedParams{
+		ToFeedID:   sql.NullInt32{Int32: existing.ID, Valid: true},
+		FromFeedID: sql.NullInt32{Int32: feedID, Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to move alerts: %w", err)
+	}
+	if err := q.DeleteFeed(ctx, feedID); err != nil {
+		return false, fmt.Errorf("failed to delete old feed: %w", err)
+	}
+	return true, nil
+}
+
+// markFeedGone stops scheduling a feed whose server answered 410 Gone.
+func markFeedGone(s *State, ctx context.Context, feed database.Feed) {
+	logger := feedLogger(s, feed)
+	if err := s.Db.SetFeedDead(ctx, feed.ID); err != nil {
+		logger.Error("failed to mark feed dead", "error", err)
+		return
+	}
+	logger.Warn("feed is gone, no longer fetching it")
+}
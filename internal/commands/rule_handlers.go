@@ -0,0 +1,276 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/filters"
+)
+
+func handlerRules(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return errors.New("Usage: rules <add|list|remove|apply> [args]")
+	}
+
+	sub := Command{Name: cmd.Args[0], Args: cmd.Args[1:]}
+	switch sub.Name {
+	case "add":
+		return handlerRulesAdd(s, sub, user)
+	case "list":
+		return handlerRulesList(s, sub, user)
+	case "remove":
+		return handlerRulesRemove(s, sub, user)
+	case "apply":
+		return handlerRulesApply(s, sub, user)
+	}
+
+	return fmt.Errorf("unknown rules command: %s", sub.Name)
+}
+
+func handlerRulesAdd(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("rules add", flag.ContinueOnError)
+	feedRef := fs.String("feed", "", "only apply to posts from this feed URL or ID")
+	field := fs.String("field", filters.FieldAny, "field to match: "+strings.Join(filters.Fields, ", "))
+	match := fs.String("match", filters.MatchContains, "how to read the pattern: "+strings.Join(filters.MatchTypes, ", "))
+	tag := fs.String("tag", "", "tag to add, for the tag action")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return errors.New("Usage: rules add [-feed url|id] [-field name] [-match contains|regex|expr] [-tag name] <hide|read|star|tag> <pattern>")
+	}
+
+	action := fs.Arg(0)
+	pattern := strings.Join(fs.Args()[1:], " ")
+	if !slices.Contains(filters.Actions, action) {
+		return fmt.Errorf("Invalid action %q: use one of %s", action, strings.Join(filters.Actions, ", "))
+	}
+	if action == filters.ActionTag && strings.TrimSpace(*tag) == "" {
+		return errors.New("The tag action needs -tag name")
+	}
+	if action != filters.ActionTag && *tag != "" {
+		return errors.New("-tag is only used by the tag action")
+	}
+	if _, err := filters.Compile(*field, *match, pattern); err != nil {
+		return fmt.Errorf("Invalid pattern: %w", err)
+	}
+
+	ctx := context.Background()
+	params := database.CreateFilterRuleParams{
+		UserID:    user.ID,
+		Field:     *field,
+		MatchType: *match,
+		Pattern:   pattern,
+		Action:    action,
+		Tag:       sql.NullString{String: strings.TrimSpace(*tag), Valid: action == filters.ActionTag},
+	}
+	if *feedRef != "" {
+		feed, err := lookupFeed(s, ctx, *feedRef)
+		if err != nil {
+			return err
+		}
+		params.FeedID = sql.NullInt32{Int32: feed.ID, Valid: true}
+	}
+
+	rule, err := s.Db.CreateFilterRule(ctx, params)
+	if err != nil {
+		return fmt.Errorf("Failed to create rule: %w", err)
+	}
+
+	fmt.Printf("Rule %d created: %s\n", rule.ID, describeRule(rule))
+	fmt.Println("It applies to new posts; run 'rules apply' to apply it to saved ones.")
+	return nil
+}
+
+// describeRule renders a rule as one line, such as
+// `hide posts where title contains "sponsored"`.
+func describeRule(r database.FilterRule) string {
+	action := r.Action + " posts"
+	switch r.Action {
+	case filters.ActionRead:
+		action = "mark posts read"
+	case filters.ActionTag:
+		action = fmt.Sprintf("tag posts %q", r.Tag.String)
+	}
+	if r.MatchType == filters.MatchExpr {
+		return fmt.Sprintf("%s matching %s", action, r.Pattern)
+	}
+	return fmt.Sprintf("%s where %s %s %q", action, r.Field, r.MatchType, r.Pattern)
+}
+
+func handlerRulesList(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	ctx := context.Background()
+	rules, err := s.Db.RetrieveFilterRulesForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve rules: %w", err)
+	}
+	if len(rules) == 0 {
+		fmt.Println("No filter rules")
+		return nil
+	}
+
+	fmt.Println("=== FILTER RULES ===")
+	for _, r := range rules {
+		fmt.Printf("  %d: %s\n", r.ID, describeRule(r))
+		if r.FeedID.Valid {
+			feed, err := s.Db.RetrieveFeedByID(ctx, r.FeedID.Int32)
+			if err == nil {
+				fmt.Printf("     Feed: %s\n", feed.Url)
+			}
+		}
+	}
+	return nil
+}
+
+func handlerRulesRemove(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	id, err := strconv.ParseInt(cmd.Args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("Invalid rule id: %w", err)
+	}
+
+	removed, err := s.Db.DeleteFilterRule(context.Background(), database.DeleteFilterRuleParams{
+		ID:     int32(id),
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to remove rule: %w", err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("No rule %d found", id)
+	}
+
+	fmt.Printf("Rule %d removed.\n", id)
+	return nil
+}
+
+// handlerRulesApply runs the user's rules, or just one of them, over the
+// posts already saved from the feeds they follow.
+func handlerRulesApply(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("rules apply", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only report how many posts each rule matches")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return errors.New("Usage: rules apply [-dry-run] [rule-id]")
+	}
+
+	ctx := context.Background()
+	rules, err := s.Db.RetrieveFilterRulesForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve rules: %w", err)
+	}
+	if fs.NArg() == 1 {
+		id, err := strconv.ParseInt(fs.Arg(0), 10, 32)
+		if err != nil {
+			return fmt.Errorf("Invalid rule id: %w", err)
+		}
+		rules = slices.DeleteFunc(rules, func(r database.FilterRule) bool { return r.ID != int32(id) })
+		if len(rules) == 0 {
+			return fmt.Errorf("No rule %d found", id)
+		}
+	}
+	if len(rules) == 0 {
+		fmt.Println("No filter rules")
+		return nil
+	}
+
+	compiled := make([]filters.Rule, len(rules))
+	for i, r := range rules {
+		if compiled[i], err = filters.CompileRule(r); err != nil {
+			return fmt.Errorf("Invalid rule: %w", err)
+		}
+	}
+
+	params := database.RetrieveFilterablePostsForUserParams{UserID: user.ID}
+	if len(rules) == 1 {
+		params.FeedID = rules[0].FeedID
+	}
+	rows, err := s.Db.RetrieveFilterablePostsForUser(ctx, params)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve posts: %w", err)
+	}
+	posts, err := filterablePosts(s, ctx, rows)
+	if err != nil {
+		return err
+	}
+
+	matched := make([]int, len(compiled))
+	err = s.Db.InTx(ctx, func(q database.Querier) error {
+		for i, rule := range compiled {
+			for j, post := range posts {
+				if !rule.Match(rows[j].FeedID, post) {
+					continue
+				}
+				matched[i]++
+				if *dryRun {
+					continue
+				}
+				if err := rule.Apply(ctx, q, post.ID); err != nil {
+					return fmt.Errorf("Failed to apply rule %d to post %d: %w", rule.ID, post.ID, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	verb := "applied to"
+	if *dryRun {
+		verb = "would apply to"
+	}
+	for i, rule := range compiled {
+		fmt.Printf("Rule %d %s %d posts: %s\n", rule.ID, verb, matched[i], describeRule(rule.FilterRule))
+	}
+	return nil
+}
+
+// filterablePosts loads the categories of rows, returning posts in the
+// same order for matching.
+func filterablePosts(s *State, ctx context.Context, rows []database.RetrieveFilterablePostsForUserRow) ([]filters.Post, error) {
+	ids := make([]int32, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	categories, err := s.Db.RetrieveCategoriesForPosts(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve categories: %w", err)
+	}
+	byPost := map[int32][]string{}
+	for _, c := range categories {
+		byPost[c.PostID] = append(byPost[c.PostID], c.Name)
+	}
+
+	posts := make([]filters.Post, len(rows))
+	for i, row := range rows {
+		posts[i] = filters.Post{
+			ID:          row.ID,
+			Title:       row.Title,
+			Description: row.Description,
+			Author:      row.Author,
+			Categories:  byPost[row.ID],
+		}
+	}
+	return posts, nil
+}
+
+func RegisterRulesCommands(c *Commands) {
+	c.register("rules", MiddlewareLoggedIn(handlerRules))
+}
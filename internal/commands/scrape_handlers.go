@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/metrics"
+	"github.com/sanntintdev/gator/internal/richtext"
+	"github.com/sanntintdev/gator/internal/scrape"
+)
+
+// Values of feeds.kind.
+const (
+	FeedKindRSS    = "rss"
+	FeedKindScrape = "scrape"
+)
+
+// fetchFeedItems fetches feed according to its kind.
+func fetchFeedItems(ctx context.Context, feed database.Feed) (*RSSFeed, FetchInfo, error) {
+	if feed.Kind != FeedKindScrape {
+		return FetchFeed(ctx, feed.Url)
+	}
+
+	rules, err := scrape.ParseRules(feed.ScrapeRules.String)
+	if err != nil {
+		return nil, FetchInfo{ErrorKind: FetchErrorParse}, err
+	}
+	return FetchScrapedFeed(ctx, feed.Url, rules)
+}
+
+// FetchScrapedFeed downloads an HTML page and extracts items from it with
+// rules, returning them in the same shape as an RSS feed.
+func FetchScrapedFeed(ctx context.Context, pageURL string, rules scrape.Rules) (*RSSFeed, FetchInfo, error) {
+	data, info, err := download(ctx, pageURL)
+	if err != nil {
+		return nil, info, err
+	}
+
+	base, _ := url.Parse(info.FinalURL)
+	feed, err := scrapePage(data, info.ContentType, base, rules)
+	if err != nil {
+		metrics.ParseFailures.Inc()
+		info.ErrorKind = FetchErrorParse
+		return nil, info, err
+	}
+	return feed, info, nil
+}
+
+func scrapePage(data []byte, contentType string, base *url.URL, rules scrape.Rules) (*RSSFeed, error) {
+	body, err := charset.NewReader(bytes.NewReader(data), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode page: %w", err)
+	}
+
+	items, err := scrape.Extract(body, base, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed RSSFeed
+	for _, item := range items {
+		feed.Channel.Item = append(feed.Channel.Item, RSSItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			PubDate:     item.PubDate,
+		})
+	}
+	return &feed, nil
+}
+
+// scrapeRuleFlags registers the flags describing scrape rules on fs.
+func scrapeRuleFlags(fs *flag.FlagSet) *scrape.Rules {
+	var rules scrape.Rules
+	fs.StringVar(&rules.Item, "item", "", "CSS selector matching one element per post")
+	fs.StringVar(&rules.Title, "title", "", "CSS selector for the title inside an item (default: the item's text)")
+	fs.StringVar(&rules.Link, "link", "", "CSS selector for the link inside an item (default: its first link)")
+	fs.StringVar(&rules.Description, "description", "", "CSS selector for the description inside an item")
+	fs.StringVar(&rules.Date, "date", "", "CSS selector for the date inside an item")
+	fs.StringVar(&rules.DateLayout, "date-layout", "", "Go time layout of the date (default: detect)")
+	return &rules
+}
+
+func handlerTestRule(s *State, cmd Command) error {
+	fs := flag.NewFlagSet("testrule", flag.ContinueOnError)
+	flags := scrapeRuleFlags(fs)
+	feedRef := fs.String("feed", "", "start from the rules of an existing scraped feed")
+	baseURL := fs.String("base", "", "URL used to resolve relative links when reading a local file")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("Usage: testrule [-feed feed] [-item sel] [-title sel] [-link sel] [-description sel] [-date sel] [-date-layout layout] <url|file>")
+	}
+
+	ctx := context.Background()
+	rules := *flags
+	if *feedRef != "" {
+		feed, err := lookupFeed(s, ctx, *feedRef)
+		if err != nil {
+			return err
+		}
+		if feed.Kind != FeedKindScrape {
+			return fmt.Errorf("Feed %s is not a scraped feed", feed.Name)
+		}
+		stored, err := scrape.ParseRules(feed.ScrapeRules.String)
+		if err != nil {
+			return err
+		}
+		rules = mergeRules(stored, *flags)
+	}
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+
+	source := fs.Arg(0)
+	var feed *RSSFeed
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		var err error
+		feed, _, err = FetchScrapedFeed(ctx, source, rules)
+		if err != nil {
+			return fmt.Errorf("Failed to scrape %s: %w", source, err)
+		}
+	} else {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return fmt.Errorf("Failed to read %s: %w", source, err)
+		}
+		var base *url.URL
+		if *baseURL != "" {
+			if base, err = url.Parse(*baseURL); err != nil {
+				return fmt.Errorf("Invalid base URL: %w", err)
+			}
+		}
+		feed, err = scrapePage(data, "", base, rules)
+		if err != nil {
+			return fmt.Errorf("Failed to scrape %s: %w", source, err)
+		}
+	}
+
+	fmt.Printf("Rules: %s\n", rules.JSON())
+	fmt.Printf("Found %d items\n", len(feed.Channel.Item))
+	for i, item := range feed.Channel.Item {
+		fmt.Printf("\n%d. %s\n", i+1, item.Title)
+		fmt.Printf("   link: %s\n", item.Link)
+		if item.PubDate != "" {
+			fmt.Printf("   date: %s\n", item.PubDate)
+		}
+		if item.Description != "" {
+			fmt.Printf("   %s\n", richtext.Excerpt(item.Description, 120))
+		}
+	}
+	return nil
+}
+
+// mergeRules overrides base with the non-empty fields of override.
+func mergeRules(base, override scrape.Rules) scrape.Rules {
+	for _, f := range []struct{ dst, src *string }{
+		{&base.Item, &override.Item},
+		{&base.Title, &override.Title},
+		{&base.Link, &override.Link},
+		{&base.Description, &override.Description},
+		{&base.Date, &override.Date},
+		{&base.DateLayout, &override.DateLayout},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+	return base
+}
+
+// setScrapeRules turns feed into a scraped feed using rules.
+func setScrapeRules(ctx context.Context, q database.Querier, feedID int32, rules scrape.Rules) error {
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+	return q.SetFeedScrapeRules(ctx, database.SetFeedScrapeRulesParams{
+		ID:          feedID,
+		Kind:        FeedKindScrape,
+		ScrapeRules: sql.NullString{String: rules.JSON(), Valid: true},
+	})
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/sanntintdev/gator/internal/api/fever"
+	"github.com/sanntintdev/gator/internal/api/greader"
+	"github.com/sanntintdev/gator/internal/metrics"
+	"github.com/sanntintdev/gator/internal/outfeed"
+)
+
+const defaultServeAddr = ":8080"
+
+func handlerServe(s *State, cmd Command) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	addr := defaultServeAddr
+	if len(cmd.Args) == 1 {
+		addr = cmd.Args[0]
+	}
+
+	mux := http.NewServeMux()
+	greader.New(s.Db, s.Logger).Register(mux)
+	fever.New(s.Db, s.Logger).Register(mux)
+	outfeed.NewServer(s.Db, s.Logger).Register(mux)
+
+	s.Logger.Info("serving API", "addr", addr)
+	fmt.Println("Press Ctrl+C to stop")
+
+	return http.ListenAndServe(addr, mux)
+}
+
+// startAggServer serves the aggregator's operational endpoints in the
+// background so agg can keep fetching.
+func startAggServer(s *State, status *aggStatus, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.HandleFunc("GET /healthz", status.handleHealthz(s))
+	mux.HandleFunc("GET /readyz", status.handleReadyz(s))
+
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			s.Logger.Error("agg listener stopped", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+func RegisterServerCommands(c *Commands) {
+	c.register("serve", handlerServe)
+}
@@ -1,13 +1,20 @@
 package commands
 
 import (
+	"bufio"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sanntintdev/gator/internal/auth"
 	"github.com/sanntintdev/gator/internal/database"
+	"golang.org/x/term"
 )
 
 func handlerLogin(s *State, cmd Command) error {
@@ -102,12 +109,108 @@ func handlerFollowing(s *State, cmd Command, user database.User) error {
 		return fmt.Errorf("failed to get following: %w", err)
 	}
 
+	// Follows come back with unfiled feeds first, then grouped by folder.
 	for _, following := range following {
-		fmt.Println("*", following.FeedName)
+		if !following.FolderName.Valid {
+			fmt.Println("*", followLabel(following))
+		}
+	}
+	folder := ""
+	for _, following := range following {
+		if !following.FolderName.Valid {
+			continue
+		}
+		if following.FolderName.String != folder {
+			folder = following.FolderName.String
+			fmt.Printf("%s/\n", folder)
+		}
+		fmt.Println("  *", followLabel(following))
 	}
 	return nil
 }
 
+// followLabel describes a followed feed by the user's own title for it,
+// along with any settings that change how it is shown.
+func followLabel(ff database.RetrieveFeedFollowsForUserRow) string {
+	label := ff.FeedName
+	if ff.Title.Valid {
+		label = fmt.Sprintf("%s (%s)", ff.Title.String, ff.FeedName)
+	}
+	if ff.Muted {
+		label += " [muted]"
+	}
+	if ff.Notify == "none" {
+		label += " [no notifications]"
+	}
+	return label
+}
+
+func handlerPasswd(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return errors.New("Usage: passwd (the new password is read from stdin)")
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+	if password == "" {
+		return errors.New("Password must not be empty")
+	}
+
+	passwordHash, err := auth.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	ctx := context.Background()
+	err = s.Db.SetUserPassword(ctx, database.SetUserPasswordParams{
+		UserID:       user.ID,
+		PasswordHash: passwordHash,
+		FeverApiKey: sql.NullString{
+			String: auth.FeverAPIKey(user.Name, password),
+			Valid:  true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set password: %w", err)
+	}
+
+	fmt.Printf("Password updated for: %s\n", user.Name)
+	return nil
+}
+
+// readPassword reads a new password from stdin. On a terminal it is asked
+// for twice without echoing it; otherwise the first line is used, so that
+// it can be piped in.
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			return "", fmt.Errorf("Failed to read password from stdin: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Print("New password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("Failed to read password: %w", err)
+	}
+	fmt.Print("Retype new password: ")
+	again, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("Failed to read password: %w", err)
+	}
+	if string(password) != string(again) {
+		return "", errors.New("Passwords do not match")
+	}
+	return string(password), nil
+}
+
 func RegisterUserCommands(c *Commands) {
 	userHandlers := map[string]func(*State, Command) error{
 		"login":    handlerLogin,
@@ -121,4 +224,5 @@ func RegisterUserCommands(c *Commands) {
 	}
 
 	c.register("following", MiddlewareLoggedIn(handlerFollowing))
+	c.register("passwd", MiddlewareLoggedIn(handlerPasswd))
 }
@@ -0,0 +1,218 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/webhooks"
+)
+
+func handlerWebhook(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return errors.New("Usage: webhook <add|list|remove|deliveries|replay> [args]")
+	}
+
+	sub := Command{Name: cmd.Args[0], Args: cmd.Args[1:]}
+	switch sub.Name {
+	case "add":
+		return handlerWebhookAdd(s, sub, user)
+	case "list":
+		return handlerWebhookList(s, sub, user)
+	case "remove":
+		return handlerWebhookRemove(s, sub, user)
+	case "deliveries":
+		return handlerWebhookDeliveries(s, sub, user)
+	case "replay":
+		return handlerWebhookReplay(s, sub, user)
+	}
+
+	return fmt.Errorf("unknown webhook command: %s", sub.Name)
+}
+
+func handlerWebhookAdd(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("webhook add", flag.ContinueOnError)
+	feedURL := fs.String("feed", "", "only fire for posts from this feed URL")
+	keyword := fs.String("keyword", "", "only fire for posts mentioning this keyword")
+	secret := fs.String("secret", "", "HMAC secret used to sign payloads (generated if empty)")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("Usage: webhook add [-feed url] [-keyword text] [-secret s] <url>")
+	}
+
+	target := fs.Arg(0)
+	if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("Invalid webhook URL: %s", target)
+	}
+
+	ctx := context.Background()
+	params := database.CreateWebhookParams{
+		UserID:  user.ID,
+		Url:     target,
+		Secret:  *secret,
+		Keyword: sql.NullString{String: *keyword, Valid: *keyword != ""},
+	}
+
+	if *feedURL != "" {
+		feed, err := s.Db.RetrieveFeedWithURL(ctx, *feedURL)
+		if err != nil {
+			return fmt.Errorf("Invalid feed URL: %w", err)
+		}
+		params.FeedID = sql.NullInt32{Int32: feed.ID, Valid: true}
+	}
+
+	if params.Secret == "" {
+		generated, err := webhooks.GenerateSecret()
+		if err != nil {
+			return fmt.Errorf("failed to generate secret: %w", err)
+		}
+		params.Secret = generated
+	}
+
+	hook, err := s.Db.CreateWebhook(ctx, params)
+	if err != nil {
+		return fmt.Errorf("Failed to create webhook: %w", err)
+	}
+
+	fmt.Printf("Webhook %d created for %s\n", hook.ID, hook.Url)
+	fmt.Printf("Signing secret: %s\n", hook.Secret)
+	fmt.Printf("Payloads are signed in the %s header.\n", webhooks.SignatureHeader)
+	return nil
+}
+
+func handlerWebhookList(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	ctx := context.Background()
+	hooks, err := s.Db.RetrieveWebhooksForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve webhooks: %w", err)
+	}
+
+	fmt.Println("=== WEBHOOKS ===")
+	for _, hook := range hooks {
+		fmt.Printf("  ID: %d\n", hook.ID)
+		fmt.Printf("  URL: %s\n", hook.Url)
+		if hook.FeedID.Valid {
+			feed, err := s.Db.RetrieveFeedByID(ctx, hook.FeedID.Int32)
+			if err == nil {
+				fmt.Printf("  Feed: %s\n", feed.Url)
+			}
+		}
+		if hook.Keyword.Valid {
+			fmt.Printf("  Keyword: %s\n", hook.Keyword.String)
+		}
+	}
+	return nil
+}
+
+func handlerWebhookRemove(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	id, err := strconv.ParseInt(cmd.Args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("Invalid webhook id: %w", err)
+	}
+
+	removed, err := s.Db.DeleteWebhook(context.Background(), database.DeleteWebhookParams{
+		ID:     int32(id),
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to remove webhook: %w", err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("No webhook %d found", id)
+	}
+
+	fmt.Printf("Webhook %d removed.\n", id)
+	return nil
+}
+
+func handlerWebhookDeliveries(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("webhook deliveries", flag.ContinueOnError)
+	status := fs.String("status", "", "only show deliveries with this status (pending, delivered, dead)")
+	limit := fs.Int("limit", 20, "maximum number of deliveries")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+
+	deliveries, err := s.Db.RetrieveWebhookDeliveriesForUser(context.Background(), database.RetrieveWebhookDeliveriesForUserParams{
+		UserID: user.ID,
+		Status: sql.NullString{String: *status, Valid: *status != ""},
+		Limit:  int32(*limit),
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve deliveries: %w", err)
+	}
+
+	fmt.Println("=== DELIVERIES ===")
+	for _, d := range deliveries {
+		fmt.Printf("  #%d [%s] %s -> %s\n", d.ID, d.Status, d.PostTitle, d.WebhookUrl)
+		fmt.Printf("    Attempts: %d, last update: %s\n", d.Attempts, d.UpdatedAt.Format("2006-01-02 15:04:05"))
+		if d.LastStatusCode.Valid {
+			fmt.Printf("    Last status: %d\n", d.LastStatusCode.Int32)
+		}
+		if d.LastError.Valid {
+			fmt.Printf("    Last error: %s\n", d.LastError.String)
+		}
+		if d.Status == webhooks.StatusPending && d.Attempts > 0 {
+			fmt.Printf("    Next attempt: %s\n", d.NextAttemptAt.Format("2006-01-02 15:04:05"))
+		}
+	}
+	return nil
+}
+
+func handlerWebhookReplay(s *State, cmd Command, user database.User) error {
+	fs := flag.NewFlagSet("webhook replay", flag.ContinueOnError)
+	dead := fs.Bool("dead", false, "replay every dead-lettered delivery")
+	if err := fs.Parse(cmd.Args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	var replayed int64
+	var err error
+	switch {
+	case *dead && fs.NArg() == 0:
+		replayed, err = s.Db.ReplayDeadWebhookDeliveriesForUser(ctx, user.ID)
+	case !*dead && fs.NArg() == 1:
+		id, parseErr := strconv.ParseInt(fs.Arg(0), 10, 32)
+		if parseErr != nil {
+			return fmt.Errorf("Invalid delivery id: %w", parseErr)
+		}
+		replayed, err = s.Db.ReplayWebhookDelivery(ctx, database.ReplayWebhookDeliveryParams{
+			ID:     int32(id),
+			UserID: user.ID,
+		})
+	default:
+		return errors.New("Usage: webhook replay <delivery-id> | webhook replay -dead")
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to replay deliveries: %w", err)
+	}
+
+	fmt.Printf("%d deliveries queued for replay.\n", replayed)
+
+	delivered, failed, err := webhooks.NewDispatcher(s.Db).DeliverDue(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to deliver webhooks: %w", err)
+	}
+	fmt.Printf("Webhooks: %d delivered, %d failed\n", delivered, failed)
+	return nil
+}
+
+func RegisterWebhookCommands(c *Commands) {
+	c.register("webhook", MiddlewareLoggedIn(handlerWebhook))
+}
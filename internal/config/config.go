@@ -9,7 +9,24 @@ const configFileName = ".gatorconfig.json"
 
 type Config struct {
 	CurrentUserName string `json:"current_user_name"`
-	Db_url          string `json:"db_url, omitempty"`
+	Db_url          string `json:"db_url,omitempty"`
+	LogLevel        string `json:"log_level,omitempty"`
+	LogFormat       string `json:"log_format,omitempty"`
+	AutoMigrate     bool   `json:"auto_migrate,omitempty"`
+
+	// SMTP relay used to send email, as host:port.
+	SMTPAddr     string `json:"smtp_addr,omitempty"`
+	SMTPUsername string `json:"smtp_username,omitempty"`
+	SMTPPassword string `json:"smtp_password,omitempty"`
+	SMTPFrom     string `json:"smtp_from,omitempty"`
+
+	// Alert sinks that act on the machine running agg are off unless
+	// enabled here: the exact commands the command sink may run, and the
+	// directory file sinks may write in.
+	AlertCommands []string `json:"alert_commands,omitempty"`
+	AlertFileDir  string   `json:"alert_file_dir,omitempty"`
+
+	readOnly bool
 }
 
 func Read() (Config, error) {
@@ -38,9 +55,19 @@ func Read() (Config, error) {
 
 func (c *Config) SetUser(username string) error {
 	c.CurrentUserName = username
+	if c.readOnly {
+		return nil
+	}
 	return write(*c)
 }
 
+// ReadOnly returns a copy of the config whose changes are never written back
+// to the config file.
+func (c Config) ReadOnly() Config {
+	c.readOnly = true
+	return c
+}
+
 func getConfigFilePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
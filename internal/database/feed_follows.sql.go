@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,7 +17,7 @@ const createFeedFollow = `-- name: CreateFeedFollow :one
 WITH inserted_follow AS (
     INSERT INTO feed_follows (user_id, feed_id, created_at, updated_at)
     VALUES ($1, $2, NOW(), NOW())
-    RETURNING id, user_id, feed_id, created_at, updated_at
+    RETURNING id, user_id, feed_id, created_at, updated_at, folder_id, title, muted, notify, priority
 )
 SELECT
     ff.id,
@@ -76,6 +77,62 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 	return err
 }
 
+const deleteFeedFollowsForFeed = `-- name: DeleteFeedFollowsForFeed :exec
+DELETE FROM feed_follows
+WHERE feed_id = $1
+`
+
+func (q *Queries) DeleteFeedFollowsForFeed(ctx context.Context, feedID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedFollowsForFeed, feedID)
+	return err
+}
+
+const moveFeedFollows = `-- name: MoveFeedFollows :exec
+INSERT INTO feed_follows (user_id, feed_id, folder_id, title, muted, notify, priority, created_at, updated_at)
+SELECT user_id, $1, folder_id, title, muted, notify, priority, created_at, NOW()
+FROM feed_follows
+WHERE feed_id = $2
+ON CONFLICT (user_id, feed_id) DO NOTHING
+`
+
+type MoveFeedFollowsParams struct {
+	ToFeedID   int32
+	FromFeedID int32
+}
+
+func (q *Queries) MoveFeedFollows(ctx context.Context, arg MoveFeedFollowsParams) error {
+	_, err := q.db.ExecContext(ctx, moveFeedFollows, arg.ToFeedID, arg.FromFeedID)
+	return err
+}
+
+const retrieveFeedFollow = `-- name: RetrieveFeedFollow :one
+SELECT id, user_id, feed_id, created_at, updated_at, folder_id, title, muted, notify, priority FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type RetrieveFeedFollowParams struct {
+	UserID uuid.UUID
+	FeedID int32
+}
+
+func (q *Queries) RetrieveFeedFollow(ctx context.Context, arg RetrieveFeedFollowParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, retrieveFeedFollow, arg.UserID, arg.FeedID)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FolderID,
+		&i.Title,
+		&i.Muted,
+		&i.Notify,
+		&i.Priority,
+	)
+	return i, err
+}
+
 const retrieveFeedFollowsForUser = `-- name: RetrieveFeedFollowsForUser :many
 SELECT
     ff.id,
@@ -84,21 +141,37 @@ SELECT
     ff.created_at,
     ff.updated_at,
     u.name AS user_name,
-    f.name AS feed_name
+    f.name AS feed_name,
+    ff.folder_id,
+    fo.name AS folder_name,
+    f.url AS feed_url,
+    ff.title,
+    ff.muted,
+    ff.notify,
+    ff.priority
 FROM feed_follows ff
 INNER JOIN users u ON ff.user_id = u.id
 INNER JOIN feeds f ON ff.feed_id = f.id
+LEFT JOIN folders fo ON ff.folder_id = fo.id
 WHERE ff.user_id = $1
+ORDER BY fo.name NULLS FIRST, ff.priority DESC, COALESCE(ff.title, f.name)
 `
 
 type RetrieveFeedFollowsForUserRow struct {
-	ID        int32
-	UserID    uuid.UUID
-	FeedID    int32
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserName  string
-	FeedName  string
+	ID         int32
+	UserID     uuid.UUID
+	FeedID     int32
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserName   string
+	FeedName   string
+	FolderID   sql.NullInt32
+	FolderName sql.NullString
+	FeedUrl    string
+	Title      sql.NullString
+	Muted      bool
+	Notify     string
+	Priority   int32
 }
 
 func (q *Queries) RetrieveFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]RetrieveFeedFollowsForUserRow, error) {
@@ -118,6 +191,13 @@ func (q *Queries) RetrieveFeedFollowsForUser(ctx context.Context, userID uuid.UU
 			&i.UpdatedAt,
 			&i.UserName,
 			&i.FeedName,
+			&i.FolderID,
+			&i.FolderName,
+			&i.FeedUrl,
+			&i.Title,
+			&i.Muted,
+			&i.Notify,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -131,3 +211,50 @@ func (q *Queries) RetrieveFeedFollowsForUser(ctx context.Context, userID uuid.UU
 	}
 	return items, nil
 }
+
+const setFeedFollowFolder = `-- name: SetFeedFollowFolder :execrows
+UPDATE feed_follows
+SET folder_id = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type SetFeedFollowFolderParams struct {
+	UserID   uuid.UUID
+	FeedID   int32
+	FolderID sql.NullInt32
+}
+
+func (q *Queries) SetFeedFollowFolder(ctx context.Context, arg SetFeedFollowFolderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowFolder, arg.UserID, arg.FeedID, arg.FolderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateFeedFollowSettings = `-- name: UpdateFeedFollowSettings :exec
+UPDATE feed_follows
+SET title = $3, muted = $4, notify = $5, priority = $6, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type UpdateFeedFollowSettingsParams struct {
+	UserID   uuid.UUID
+	FeedID   int32
+	Title    sql.NullString
+	Muted    bool
+	Notify   string
+	Priority int32
+}
+
+func (q *Queries) UpdateFeedFollowSettings(ctx context.Context, arg UpdateFeedFollowSettingsParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedFollowSettings,
+		arg.UserID,
+		arg.FeedID,
+		arg.Title,
+		arg.Muted,
+		arg.Notify,
+		arg.Priority,
+	)
+	return err
+}
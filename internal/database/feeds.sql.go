@@ -13,6 +13,55 @@ import (
 	"github.com/google/uuid"
 )
 
+const countFeedReferences = `-- name: CountFeedReferences :one
+SELECT
+    (SELECT COUNT(*) FROM feed_follows ff WHERE ff.feed_id = $1) AS follow_count,
+    (SELECT COUNT(*) FROM posts p WHERE p.feed_id = $1) AS post_count,
+    (SELECT COUNT(*) FROM post_states ps
+        INNER JOIN posts p ON p.id = ps.post_id
+        WHERE p.feed_id = $1) AS post_state_count,
+    (SELECT COUNT(*) FROM webhooks w WHERE w.feed_id = $1) AS webhook_count,
+    (SELECT COUNT(*) FROM feed_fetches x WHERE x.feed_id = $1) AS fetch_count,
+    (SELECT COUNT(*) FROM filter_rules r WHERE r.feed_id = $1) AS filter_rule_count,
+    (SELECT COUNT(*) FROM post_tags pt
+        INNER JOIN posts p ON p.id = pt.post_id
+        WHERE p.feed_id = $1) AS post_tag_count,
+    (SELECT COUNT(*) FROM alerts a WHERE a.feed_id = $1) AS alert_count,
+    (SELECT COUNT(*) FROM alert_events e
+        INNER JOIN alerts a ON a.id = e.alert_id
+        INNER JOIN posts p ON p.id = e.post_id
+        WHERE a.feed_id = $1 OR p.feed_id = $1) AS alert_event_count
+`
+
+type CountFeedReferencesRow struct {
+	FollowCount     int64
+	PostCount       int64
+	PostStateCount  int64
+	WebhookCount    int64
+	FetchCount      int64
+	FilterRuleCount int64
+	PostTagCount    int64
+	AlertCount      int64
+	AlertEventCount int64
+}
+
+func (q *Queries) CountFeedReferences(ctx context.Context, feedID int32) (CountFeedReferencesRow, error) {
+	row := q.db.QueryRowContext(ctx, countFeedReferences, feedID)
+	var i CountFeedReferencesRow
+	err := row.Scan(
+		&i.FollowCount,
+		&i.PostCount,
+		&i.PostStateCount,
+		&i.WebhookCount,
+		&i.FetchCount,
+		&i.FilterRuleCount,
+		&i.PostTagCount,
+		&i.AlertCount,
+		&i.AlertEventCount,
+	)
+	return i, err
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (url, name, user_id, created_at, updated_at)
 VALUES (
@@ -22,7 +71,7 @@ VALUES (
     $4,
     $5
 )
-RETURNING id, url, name, user_id, created_at, updated_at, last_fetched_at
+RETURNING id, url, name, user_id, created_at, updated_at, last_fetched_at, dead_at, fetch_full_content, kind, scrape_rules
 `
 
 type CreateFeedParams struct {
@@ -50,10 +99,24 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastFetchedAt,
+		&i.DeadAt,
+		&i.FetchFullContent,
+		&i.Kind,
+		&i.ScrapeRules,
 	)
 	return i, err
 }
 
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :exec
 UPDATE feeds
 SET last_fetched_at = NOW(), updated_at = NOW()
@@ -65,8 +128,48 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id int32) error {
 	return err
 }
 
+const renameFeed = `-- name: RenameFeed :exec
+UPDATE feeds
+SET name = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type RenameFeedParams struct {
+	ID   int32
+	Name string
+}
+
+func (q *Queries) RenameFeed(ctx context.Context, arg RenameFeedParams) error {
+	_, err := q.db.ExecContext(ctx, renameFeed, arg.ID, arg.Name)
+	return err
+}
+
+const retrieveFeedByID = `-- name: RetrieveFeedByID :one
+SELECT id, url, name, user_id, created_at, updated_at, last_fetched_at, dead_at, fetch_full_content, kind, scrape_rules FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) RetrieveFeedByID(ctx context.Context, id int32) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, retrieveFeedByID, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Name,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastFetchedAt,
+		&i.DeadAt,
+		&i.FetchFullContent,
+		&i.Kind,
+		&i.ScrapeRules,
+	)
+	return i, err
+}
+
 const retrieveFeedWithURL = `-- name: RetrieveFeedWithURL :one
-SELECT id, url, name, user_id, created_at, updated_at, last_fetched_at FROM feeds
+SELECT id, url, name, user_id, created_at, updated_at, last_fetched_at, dead_at, fetch_full_content, kind, scrape_rules FROM feeds
 WHERE  url = $1
 `
 
@@ -81,27 +184,35 @@ func (q *Queries) RetrieveFeedWithURL(ctx context.Context, url string) (Feed, er
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastFetchedAt,
+		&i.DeadAt,
+		&i.FetchFullContent,
+		&i.Kind,
+		&i.ScrapeRules,
 	)
 	return i, err
 }
 
 const retrieveFeedsWithUser = `-- name: RetrieveFeedsWithUser :many
-SELECT feeds.id, url, feeds.name, user_id, feeds.created_at, feeds.updated_at, last_fetched_at, users.id, users.name, users.created_at, users.updated_at FROM feeds
+SELECT feeds.id, url, feeds.name, user_id, feeds.created_at, feeds.updated_at, last_fetched_at, dead_at, fetch_full_content, kind, scrape_rules, users.id, users.name, users.created_at, users.updated_at FROM feeds
 LEFT JOIN users ON feeds.user_id = users.id
 `
 
 type RetrieveFeedsWithUserRow struct {
-	ID            int32
-	Url           string
-	Name          string
-	UserID        uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	LastFetchedAt sql.NullTime
-	ID_2          uuid.NullUUID
-	Name_2        sql.NullString
-	CreatedAt_2   sql.NullTime
-	UpdatedAt_2   sql.NullTime
+	ID               int32
+	Url              string
+	Name             string
+	UserID           uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	LastFetchedAt    sql.NullTime
+	DeadAt           sql.NullTime
+	FetchFullContent bool
+	Kind             string
+	ScrapeRules      sql.NullString
+	ID_2             uuid.NullUUID
+	Name_2           sql.NullString
+	CreatedAt_2      sql.NullTime
+	UpdatedAt_2      sql.NullTime
 }
 
 func (q *Queries) RetrieveFeedsWithUser(ctx context.Context) ([]RetrieveFeedsWithUserRow, error) {
@@ -121,6 +232,10 @@ func (q *Queries) RetrieveFeedsWithUser(ctx context.Context) ([]RetrieveFeedsWit
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.LastFetchedAt,
+			&i.DeadAt,
+			&i.FetchFullContent,
+			&i.Kind,
+			&i.ScrapeRules,
 			&i.ID_2,
 			&i.Name_2,
 			&i.CreatedAt_2,
@@ -139,8 +254,51 @@ func (q *Queries) RetrieveFeedsWithUser(ctx context.Context) ([]RetrieveFeedsWit
 	return items, nil
 }
 
+const retrieveFollowedFeedsForUser = `-- name: RetrieveFollowedFeedsForUser :many
+SELECT f.id, f.url, f.name, f.user_id, f.created_at, f.updated_at, f.last_fetched_at, f.dead_at, f.fetch_full_content, f.kind, f.scrape_rules FROM feeds f
+INNER JOIN feed_follows ff ON ff.feed_id = f.id
+WHERE ff.user_id = $1
+ORDER BY f.name
+`
+
+func (q *Queries) RetrieveFollowedFeedsForUser(ctx context.Context, userID uuid.UUID) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, retrieveFollowedFeedsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Name,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastFetchedAt,
+			&i.DeadAt,
+			&i.FetchFullContent,
+			&i.Kind,
+			&i.ScrapeRules,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const retrieveNextFeedToFetch = `-- name: RetrieveNextFeedToFetch :one
-SELECT id, url, name, user_id, created_at, updated_at, last_fetched_at FROM feeds
+SELECT id, url, name, user_id, created_at, updated_at, last_fetched_at, dead_at, fetch_full_content, kind, scrape_rules FROM feeds
+WHERE dead_at IS NULL
 ORDER BY last_fetched_at ASC NULLS FIRST
 LIMIT 1
 `
@@ -156,6 +314,70 @@ func (q *Queries) RetrieveNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastFetchedAt,
+		&i.DeadAt,
+		&i.FetchFullContent,
+		&i.Kind,
+		&i.ScrapeRules,
 	)
 	return i, err
 }
+
+const setFeedDead = `-- name: SetFeedDead :exec
+UPDATE feeds
+SET dead_at = NOW(), updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) SetFeedDead(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, setFeedDead, id)
+	return err
+}
+
+const setFeedFullContent = `-- name: SetFeedFullContent :exec
+UPDATE feeds
+SET fetch_full_content = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedFullContentParams struct {
+	ID               int32
+	FetchFullContent bool
+}
+
+func (q *Queries) SetFeedFullContent(ctx context.Context, arg SetFeedFullContentParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedFullContent, arg.ID, arg.FetchFullContent)
+	return err
+}
+
+const setFeedScrapeRules = `-- name: SetFeedScrapeRules :exec
+UPDATE feeds
+SET kind = $2, scrape_rules = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedScrapeRulesParams struct {
+	ID          int32
+	Kind        string
+	ScrapeRules sql.NullString
+}
+
+func (q *Queries) SetFeedScrapeRules(ctx context.Context, arg SetFeedScrapeRulesParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedScrapeRules, arg.ID, arg.Kind, arg.ScrapeRules)
+	return err
+}
+
+const updateFeedURL = `-- name: UpdateFeedURL :exec
+UPDATE feeds
+SET url = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateFeedURLParams struct {
+	ID  int32
+	Url string
+}
+
+func (q *Queries) UpdateFeedURL(ctx context.Context, arg UpdateFeedURLParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedURL, arg.ID, arg.Url)
+	return err
+}
@@ -11,14 +11,73 @@ import (
 	"github.com/google/uuid"
 )
 
+type Alert struct {
+	ID          int32
+	UserID      uuid.UUID
+	FeedID      sql.NullInt32
+	Pattern     string
+	Sink        string
+	Target      string
+	MaxPerHour  int32
+	DigestAfter int32
+	WindowStart sql.NullTime
+	WindowCount int32
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type AlertEvent struct {
+	ID        int32
+	AlertID   int32
+	PostID    int32
+	Status    string
+	Attempts  int32
+	LastError sql.NullString
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type DigestPost struct {
+	UserID uuid.UUID
+	PostID int32
+	SentAt time.Time
+}
+
+type DigestSubscription struct {
+	UserID    uuid.UUID
+	Email     string
+	Frequency string
+	LastRunAt sql.NullTime
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type Feed struct {
-	ID            int32
-	Url           string
-	Name          string
-	UserID        uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	LastFetchedAt sql.NullTime
+	ID               int32
+	Url              string
+	Name             string
+	UserID           uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	LastFetchedAt    sql.NullTime
+	DeadAt           sql.NullTime
+	FetchFullContent bool
+	Kind             string
+	ScrapeRules      sql.NullString
+}
+
+type FeedFetch struct {
+	ID           int32
+	FeedID       int32
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	StatusCode   sql.NullInt32
+	Bytes        int32
+	ItemCount    int32
+	NewPostCount int32
+	Error        sql.NullString
+	ErrorKind    sql.NullString
+	RedirectUrl  sql.NullString
 }
 
 type FeedFollow struct {
@@ -27,6 +86,32 @@ type FeedFollow struct {
 	FeedID    int32
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	FolderID  sql.NullInt32
+	Title     sql.NullString
+	Muted     bool
+	Notify    string
+	Priority  int32
+}
+
+type FilterRule struct {
+	ID        int32
+	UserID    uuid.UUID
+	FeedID    sql.NullInt32
+	Field     string
+	MatchType string
+	Pattern   string
+	Action    string
+	Tag       sql.NullString
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Folder struct {
+	ID        int32
+	UserID    uuid.UUID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 type Post struct {
@@ -38,6 +123,31 @@ type Post struct {
 	FeedID      int32
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	DateFlag    sql.NullString
+	Excerpt     string
+	FullContent sql.NullString
+	Author      string
+}
+
+type PostCategory struct {
+	PostID int32
+	Name   string
+}
+
+type PostState struct {
+	UserID    uuid.UUID
+	PostID    int32
+	IsRead    bool
+	IsStarred bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	IsHidden  bool
+}
+
+type PostTag struct {
+	UserID uuid.UUID
+	PostID int32
+	Name   string
 }
 
 type User struct {
@@ -46,3 +156,36 @@ type User struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
+
+type UserCredential struct {
+	UserID       uuid.UUID
+	PasswordHash string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	FeverApiKey  sql.NullString
+}
+
+type Webhook struct {
+	ID        int32
+	UserID    uuid.UUID
+	Url       string
+	Secret    string
+	FeedID    sql.NullInt32
+	Keyword   sql.NullString
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID             int32
+	WebhookID      int32
+	PostID         int32
+	Payload        string
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastStatusCode sql.NullInt32
+	LastError      sql.NullString
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -8,11 +8,15 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (title, url, description, published_at, feed_id, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, Now(), Now())
+INSERT INTO posts (title, url, description, published_at, feed_id, date_flag, excerpt, author, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, Now(), Now())
 RETURNING id
 `
 
@@ -22,6 +26,9 @@ type CreatePostParams struct {
 	Description string
 	PublishedAt sql.NullTime
 	FeedID      int32
+	DateFlag    sql.NullString
+	Excerpt     string
+	Author      string
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (int32, error) {
@@ -31,21 +38,106 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (int32,
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.DateFlag,
+		arg.Excerpt,
+		arg.Author,
 	)
 	var id int32
 	err := row.Scan(&id)
 	return id, err
 }
 
+const createPostCategory = `-- name: CreatePostCategory :exec
+INSERT INTO post_categories (post_id, name)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type CreatePostCategoryParams struct {
+	PostID int32
+	Name   string
+}
+
+func (q *Queries) CreatePostCategory(ctx context.Context, arg CreatePostCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, createPostCategory, arg.PostID, arg.Name)
+	return err
+}
+
+const deletePostsForFeed = `-- name: DeletePostsForFeed :exec
+DELETE FROM posts
+WHERE feed_id = $1
+`
+
+func (q *Queries) DeletePostsForFeed(ctx context.Context, feedID int32) error {
+	_, err := q.db.ExecContext(ctx, deletePostsForFeed, feedID)
+	return err
+}
+
+const movePostsToFeed = `-- name: MovePostsToFeed :exec
+UPDATE posts
+SET feed_id = $1, updated_at = NOW()
+WHERE feed_id = $2
+`
+
+type MovePostsToFeedParams struct {
+	ToFeedID   int32
+	FromFeedID int32
+}
+
+func (q *Queries) MovePostsToFeed(ctx context.Context, arg MovePostsToFeedParams) error {
+	_, err := q.db.ExecContext(ctx, movePostsToFeed, arg.ToFeedID, arg.FromFeedID)
+	return err
+}
+
+const retrieveCategoriesForPosts = `-- name: RetrieveCategoriesForPosts :many
+SELECT post_id, name FROM post_categories
+WHERE post_id = ANY($1::integer[])
+ORDER BY post_id, name
+`
+
+func (q *Queries) RetrieveCategoriesForPosts(ctx context.Context, postIds []int32) ([]PostCategory, error) {
+	rows, err := q.db.QueryContext(ctx, retrieveCategoriesForPosts, pq.Array(postIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostCategory
+	for rows.Next() {
+		var i PostCategory
+		if err := rows.Scan(&i.PostID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const retrievePostsForUser = `-- name: RetrievePostsForUser :many
-SELECT id, title, url, description, published_at, feed_id, created_at, updated_at
-FROM posts
-ORDER BY published_at DESC
-LIMIT $1
+SELECT p.id, p.title, p.url, p.description, p.published_at, p.feed_id, p.created_at, p.updated_at, p.date_flag, p.excerpt, p.full_content, p.author
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = $1 AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+  AND (NOT $2::boolean OR COALESCE(ps.is_read, FALSE) = FALSE)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT $3
 `
 
-func (q *Queries) RetrievePostsForUser(ctx context.Context, limit int32) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, retrievePostsForUser, limit)
+type RetrievePostsForUserParams struct {
+	UserID     uuid.UUID
+	UnreadOnly bool
+	Limit      int32
+}
+
+func (q *Queries) RetrievePostsForUser(ctx context.Context, arg RetrievePostsForUserParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, retrievePostsForUser, arg.UserID, arg.UnreadOnly, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -62,6 +154,10 @@ func (q *Queries) RetrievePostsForUser(ctx context.Context, limit int32) ([]Post
 			&i.FeedID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DateFlag,
+			&i.Excerpt,
+			&i.FullContent,
+			&i.Author,
 		); err != nil {
 			return nil, err
 		}
@@ -75,3 +171,171 @@ func (q *Queries) RetrievePostsForUser(ctx context.Context, limit int32) ([]Post
 	}
 	return items, nil
 }
+
+const retrievePostsInFolder = `-- name: RetrievePostsInFolder :many
+SELECT p.id, p.title, p.url, p.description, p.published_at, p.feed_id, p.created_at, p.updated_at, p.date_flag, p.excerpt, p.full_content, p.author
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.folder_id = $1 AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+  AND (NOT $2::boolean OR COALESCE(ps.is_read, FALSE) = FALSE)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT $3
+`
+
+type RetrievePostsInFolderParams struct {
+	FolderID   sql.NullInt32
+	UnreadOnly bool
+	Limit      int32
+}
+
+func (q *Queries) RetrievePostsInFolder(ctx context.Context, arg RetrievePostsInFolderParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, retrievePostsInFolder, arg.FolderID, arg.UnreadOnly, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DateFlag,
+			&i.Excerpt,
+			&i.FullContent,
+			&i.Author,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const retrieveTimelineForUser = `-- name: RetrieveTimelineForUser :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.created_at,
+    p.updated_at,
+    COALESCE(ff.title, f.name) AS feed_name,
+    f.url AS feed_url
+FROM posts p
+INNER JOIN feeds f ON p.feed_id = f.id
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = $1
+  AND NOT ff.muted
+  AND ($2::text IS NULL OR EXISTS (
+      SELECT 1 FROM post_categories pc
+      WHERE pc.post_id = p.id AND LOWER(pc.name) = LOWER($2)
+  ))
+  AND ($3::text IS NULL
+      OR p.title ILIKE '%' || $3 || '%' ESCAPE '\'
+      OR p.description ILIKE '%' || $3 || '%' ESCAPE '\'
+      OR p.full_content ILIKE '%' || $3 || '%' ESCAPE '\')
+  AND ($4::text IS NULL OR EXISTS (
+      SELECT 1 FROM post_tags pt
+      WHERE pt.post_id = p.id AND pt.user_id = ff.user_id AND pt.name = $4
+  ))
+  AND NOT EXISTS (
+      SELECT 1 FROM post_states ps
+      WHERE ps.post_id = p.id AND ps.user_id = ff.user_id AND ps.is_hidden
+  )
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT $5
+`
+
+type RetrieveTimelineForUserParams struct {
+	UserID   uuid.UUID
+	Category sql.NullString
+	Keyword  sql.NullString
+	Tag      sql.NullString
+	Limit    int32
+}
+
+type RetrieveTimelineForUserRow struct {
+	ID          int32
+	Title       string
+	Url         string
+	Description string
+	PublishedAt sql.NullTime
+	FeedID      int32
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	FeedName    string
+	FeedUrl     string
+}
+
+func (q *Queries) RetrieveTimelineForUser(ctx context.Context, arg RetrieveTimelineForUserParams) ([]RetrieveTimelineForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, retrieveTimelineForUser,
+		arg.UserID,
+		arg.Category,
+		arg.Keyword,
+		arg.Tag,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RetrieveTimelineForUserRow
+	for rows.Next() {
+		var i RetrieveTimelineForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPostFullContent = `-- name: SetPostFullContent :exec
+UPDATE posts
+SET full_content = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetPostFullContentParams struct {
+	ID          int32
+	FullContent sql.NullString
+}
+
+func (q *Queries) SetPostFullContent(ctx context.Context, arg SetPostFullContentParams) error {
+	_, err := q.db.ExecContext(ctx, setPostFullContent, arg.ID, arg.FullContent)
+	return err
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Store is the storage the application runs against: every query, plus
+// the ability to group queries into a transaction.
+type Store interface {
+	Querier
+
+	// InTx calls fn with a Querier whose writes are committed together if
+	// fn returns nil and discarded otherwise.
+	InTx(ctx context.Context, fn func(Querier) error) error
+
+	// Ping reports whether the storage is reachable.
+	Ping(ctx context.Context) error
+}
+
+// SQLStore is a Store backed by a database/sql connection pool.
+type SQLStore struct {
+	*Queries
+	db *sql.DB
+}
+
+var _ Store = (*SQLStore)(nil)
+
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{Queries: New(db), db: db}
+}
+
+func (s *SQLStore) InTx(ctx context.Context, fn func(Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(s.Queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
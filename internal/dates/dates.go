@@ -0,0 +1,148 @@
+// Package dates parses the many date formats found in real-world feeds.
+package dates
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	// Feeds name zones like Europe/Berlin, and the host may not have a
+	// zoneinfo database installed.
+	_ "time/tzdata"
+)
+
+// zoneOffsets maps timezone abbreviations seen in feeds to numeric offsets,
+// since time.Parse only understands abbreviations for the local zone.
+var zoneOffsets = map[string]string{
+	"UT": "+0000", "UTC": "+0000", "GMT": "+0000", "Z": "+0000",
+	"WET": "+0000", "WEST": "+0100", "BST": "+0100", "IST": "+0530",
+	"CET": "+0100", "CEST": "+0200", "MET": "+0100", "MEST": "+0200",
+	"EET": "+0200", "EEST": "+0300", "MSK": "+0300",
+	"EST": "-0500", "EDT": "-0400", "CST": "-0600", "CDT": "-0500",
+	"MST": "-0700", "MDT": "-0600", "PST": "-0800", "PDT": "-0700",
+	"AKST": "-0900", "AKDT": "-0800", "HST": "-1000",
+	"JST": "+0900", "KST": "+0900", "SGT": "+0800", "HKT": "+0800",
+	"AWST": "+0800", "ACST": "+0930", "ACDT": "+1030",
+	"AEST": "+1000", "AEDT": "+1100", "NZST": "+1200", "NZDT": "+1300",
+}
+
+var (
+	weekdayPrefix = regexp.MustCompile(`(?i)^(mon|tue|tues|wed|thu|thur|thurs|fri|sat|sun)[a-z]*\.?,?\s+`)
+	trailingZone  = regexp.MustCompile(`\s*\(?([A-Za-z]{1,5})\)?$`)
+	gmtOffset     = regexp.MustCompile(`(?i)(?:GMT|UTC)([+-]\d{2}:?\d{2})$`)
+	regionZone    = regexp.MustCompile(`\s+\(?([A-Za-z]+(?:/[A-Za-z0-9_+-]+)+)\)?$`)
+	zoneComment   = regexp.MustCompile(`([+-]\d{2}:?\d{2})\s*\([A-Za-z]{1,5}\)$`)
+	clockOffset   = regexp.MustCompile(`(:\d{2}(?:\.\d+)?)([+-]\d{2}:?\d{2})$`)
+	spaces        = regexp.MustCompile(`\s+`)
+)
+
+// exact are tried against the input as given.
+var exact = []string{
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+}
+
+// layouts are tried after the input is normalised: weekday dropped, commas
+// removed, named zones turned into offsets and "T" replaced by a space.
+var layouts = func() []string {
+	days := []string{
+		"2 Jan 2006", "2 January 2006", "2 Jan 06",
+		"2-Jan-06", "2-Jan-2006",
+		"Jan 2 2006", "January 2 2006",
+		"2006-01-02", "2006/01/02", "2006.01.02",
+	}
+	clocks := []string{"15:04:05", "15:04", "3:04:05 PM", "3:04 PM"}
+	zones := []string{"-0700", "-07:00", "-07", ""}
+
+	var out []string
+	for _, d := range days {
+		for _, c := range clocks {
+			for _, z := range zones {
+				out = append(out, strings.TrimSpace(d+" "+c+" "+z))
+			}
+		}
+		out = append(out, d)
+	}
+	return out
+}()
+
+// Parse interprets value as a date. Times without a zone are taken as UTC,
+// unless they end in a zone region such as Europe/Berlin.
+func Parse(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+	value, loc := splitRegion(value)
+
+	for _, layout := range exact {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil && !isNamedZoneGuess(layout, value) {
+			return t, nil
+		}
+	}
+
+	normalised := normalise(value)
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, normalised, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("couldn't parse date format: %s", value)
+}
+
+// splitRegion removes a trailing IANA zone name from value and returns the
+// location it names, or UTC if there is none.
+func splitRegion(value string) (string, *time.Location) {
+	m := regionZone.FindStringSubmatchIndex(value)
+	if m == nil {
+		return value, time.UTC
+	}
+	loc, err := time.LoadLocation(value[m[2]:m[3]])
+	if err != nil {
+		return value, time.UTC
+	}
+	return value[:m[0]], loc
+}
+
+// isNamedZoneGuess reports whether time.Parse may have fabricated a zero
+// offset for a zone abbreviation. normalise resolves the ones we know and
+// leaves the rest to fail.
+func isNamedZoneGuess(layout, value string) bool {
+	if layout != time.RFC1123 && layout != time.RFC822 {
+		return false
+	}
+	return trailingZone.MatchString(value)
+}
+
+func normalise(value string) string {
+	value = weekdayPrefix.ReplaceAllString(value, "")
+	value = strings.ReplaceAll(value, ",", " ")
+	value = strings.Replace(value, "Sept ", "Sep ", 1)
+
+	// ISO 8601: 2006-01-02T15:04:05Z
+	if len(value) > 10 && value[10] == 'T' {
+		value = value[:10] + " " + value[11:]
+	}
+	if strings.HasSuffix(value, "Z") && len(value) > 1 && value[len(value)-2] >= '0' && value[len(value)-2] <= '9' {
+		value = value[:len(value)-1] + " +0000"
+	}
+
+	// "-0700 (PDT)": the offset is what counts, the name is a comment.
+	value = zoneComment.ReplaceAllString(value, "$1")
+	// "10:00:00+0200"
+	value = clockOffset.ReplaceAllString(value, "$1 $2")
+
+	value = gmtOffset.ReplaceAllString(value, " $1")
+	if m := trailingZone.FindStringSubmatchIndex(value); m != nil {
+		if offset, ok := zoneOffsets[strings.ToUpper(value[m[2]:m[3]])]; ok {
+			value = value[:m[0]] + " " + offset
+		}
+	}
+
+	return spaces.ReplaceAllString(strings.TrimSpace(value), " ")
+}
@@ -0,0 +1,60 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"Tue, 03 Sep 2024 10:00:00 +0000", time.Date(2024, 9, 3, 10, 0, 0, 0, time.UTC)},
+		{"Tue, 03 Sep 2024 10:00:00 GMT", time.Date(2024, 9, 3, 10, 0, 0, 0, time.UTC)},
+		{"Tue, 03 Sep 2024 10:00:00 PDT", time.Date(2024, 9, 3, 17, 0, 0, 0, time.UTC)},
+		{"Tuesday, 3 September 2024 10:00 CEST", time.Date(2024, 9, 3, 8, 0, 0, 0, time.UTC)},
+		{"2024-09-03T10:00:00Z", time.Date(2024, 9, 3, 10, 0, 0, 0, time.UTC)},
+		{"2024-09-03T10:00:00.5+02:00", time.Date(2024, 9, 3, 8, 0, 0, 5e8, time.UTC)},
+		{"2024-09-03 10:00:00", time.Date(2024, 9, 3, 10, 0, 0, 0, time.UTC)},
+		{"2024-09-03", time.Date(2024, 9, 3, 0, 0, 0, 0, time.UTC)},
+		{"Sept 3, 2024 10:00 AM", time.Date(2024, 9, 3, 10, 0, 0, 0, time.UTC)},
+		{"Tue, 03 Sep 2024 10:00:00 GMT+02:00", time.Date(2024, 9, 3, 8, 0, 0, 0, time.UTC)},
+		{"Tue, 03 Sep 2024 10:00:00 -0700 (PDT)", time.Date(2024, 9, 3, 17, 0, 0, 0, time.UTC)},
+		{"2024-09-03T10:00:00+0200", time.Date(2024, 9, 3, 8, 0, 0, 0, time.UTC)},
+		{"2024-09-03 10:00:00.25-0130", time.Date(2024, 9, 3, 11, 30, 0, 25e7, time.UTC)},
+
+		// RFC 850
+		{"Tuesday, 03-Sep-24 10:00:00 GMT", time.Date(2024, 9, 3, 10, 0, 0, 0, time.UTC)},
+		{"Tuesday, 03-Sep-24 10:00:00 EST", time.Date(2024, 9, 3, 15, 0, 0, 0, time.UTC)},
+		{"03-Sep-2024 10:00:00 +0100", time.Date(2024, 9, 3, 9, 0, 0, 0, time.UTC)},
+
+		// Zone regions, with daylight saving time taken into account.
+		{"2024-09-03 10:00:00 Europe/Berlin", time.Date(2024, 9, 3, 10, 0, 0, 0, berlin)},
+		{"Tue, 03 Dec 2024 10:00:00 Europe/Berlin", time.Date(2024, 12, 3, 9, 0, 0, 0, time.UTC)},
+		{"2024-09-03 10:00 (America/Argentina/Buenos_Aires)", time.Date(2024, 9, 3, 13, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.value)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, value := range []string{"", "   ", "yesterday", "2024-13-45", "10:00 Mars/Olympus", "Tue, 03 Sep 2024 10:00:00 XYZ", "03 Sep 24 10:00 XYZ"} {
+		if got, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", value, got)
+		}
+	}
+}
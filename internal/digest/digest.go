@@ -0,0 +1,241 @@
+// Package digest emails users a daily or weekly summary of the unread
+// posts from the feeds they follow, grouped by feed. Each post is only
+// included in one digest.
+package digest
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/mail"
+)
+
+const (
+	Daily  = "daily"
+	Weekly = "weekly"
+
+	// MaxPosts is how many posts a digest lists by default. The newest are
+	// kept; the rest are left for the next digest if still unread then.
+	MaxPosts = 100
+)
+
+var Frequencies = []string{Daily, Weekly}
+
+// Period returns the time between two digests of the given frequency.
+func Period(frequency string) time.Duration {
+	if frequency == Weekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// NextRun returns when sub's next digest is due. A subscription that has
+// never run is due straight away.
+func NextRun(sub database.DigestSubscription) time.Time {
+	if !sub.LastRunAt.Valid {
+		return sub.CreatedAt
+	}
+	return sub.LastRunAt.Time.Add(Period(sub.Frequency))
+}
+
+// Since returns the oldest post time sub's next digest covers at now: one
+// period back, or further if the last digest was longer ago than that.
+func Since(sub database.DigestSubscription, now time.Time) time.Time {
+	since := now.Add(-Period(sub.Frequency))
+	if sub.LastRunAt.Valid && sub.LastRunAt.Time.Before(since) {
+		return sub.LastRunAt.Time
+	}
+	return since
+}
+
+// Section is the part of a digest listing one feed's posts.
+type Section struct {
+	Feed     string
+	URL      string
+	Posts    []database.RetrieveDigestPostsForUserRow
+	priority int32
+}
+
+type Digest struct {
+	User      database.User
+	Frequency string
+	Since     time.Time
+	Sections  []Section
+	Count     int
+}
+
+// Build collects the user's unread posts saved after since that no earlier
+// digest included, at most limit of them. Feeds are listed by follow
+// priority and then name, with their posts newest first.
+func Build(ctx context.Context, db database.Querier, user database.User, frequency string, since time.Time, limit int) (Digest, error) {
+	rows, err := db.RetrieveDigestPostsForUser(ctx, database.RetrieveDigestPostsForUserParams{
+		UserID: user.ID,
+		Since:  since,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return Digest{}, fmt.Errorf("failed to retrieve posts: %w", err)
+	}
+
+	d := Digest{User: user, Frequency: frequency, Since: since, Count: len(rows)}
+	byFeed := map[int32]int{}
+	for _, row := range rows {
+		i, ok := byFeed[row.FeedID]
+		if !ok {
+			i = len(d.Sections)
+			byFeed[row.FeedID] = i
+			d.Sections = append(d.Sections, Section{Feed: row.FeedName, URL: row.FeedUrl, priority: row.Priority})
+		}
+		d.Sections[i].Posts = append(d.Sections[i].Posts, row)
+	}
+	slices.SortStableFunc(d.Sections, func(a, b Section) int {
+		return cmp.Or(cmp.Compare(b.priority, a.priority), cmp.Compare(a.Feed, b.Feed))
+	})
+	return d, nil
+}
+
+func (d Digest) Subject() string {
+	return fmt.Sprintf("[gator] Your %s digest: %s", d.Frequency, d.Summary())
+}
+
+// Summary counts the posts and feeds in d, as in "3 new posts from 2 feeds".
+func (d Digest) Summary() string {
+	posts, feeds := "posts", "feeds"
+	if d.Count == 1 {
+		posts = "post"
+	}
+	if len(d.Sections) == 1 {
+		feeds = "feed"
+	}
+	return fmt.Sprintf("%d new %s from %d %s", d.Count, posts, len(d.Sections), feeds)
+}
+
+// Message renders d as an email to the given address, with both plain
+// text and HTML bodies.
+func (d Digest) Message(to string) (mail.Message, error) {
+	html, err := d.HTML()
+	if err != nil {
+		return mail.Message{}, err
+	}
+	return mail.Message{
+		To:      []string{to},
+		Subject: d.Subject(),
+		Text:    d.Text(),
+		HTML:    html,
+	}, nil
+}
+
+// ErrNotRecorded is returned, wrapping the cause, when a digest was sent but
+// the posts it included could not be recorded. The run itself is still
+// recorded if possible, so the same digest isn't sent again on every tick.
+var ErrNotRecorded = errors.New("digest sent, but its posts were not recorded")
+
+// Mailer sends digests and records what they included.
+type Mailer struct {
+	db   database.Store
+	mail mail.Sender
+	now  func() time.Time
+}
+
+func NewMailer(db database.Store, sender mail.Sender) *Mailer {
+	return &Mailer{db: db, mail: sender, now: time.Now}
+}
+
+// Send emails d to sub's address, then records its posts so that later
+// digests skip them, along with the time as sub's last run. Sending comes
+// first so that no transaction is held open while talking to the relay.
+func (m *Mailer) Send(ctx context.Context, sub database.DigestSubscription, d Digest) error {
+	msg, err := d.Message(sub.Email)
+	if err != nil {
+		return fmt.Errorf("failed to render digest: %w", err)
+	}
+	if err := m.mail.Send(msg); err != nil {
+		return fmt.Errorf("failed to send digest: %w", err)
+	}
+
+	err = m.db.InTx(ctx, func(q database.Querier) error {
+		for _, section := range d.Sections {
+			for _, p := range section.Posts {
+				err := q.CreateDigestPost(ctx, database.CreateDigestPostParams{UserID: sub.UserID, PostID: p.ID})
+				if err != nil {
+					return fmt.Errorf("failed to record digest post: %w", err)
+				}
+			}
+		}
+		return m.recordRun(ctx, q, sub)
+	})
+	if err != nil {
+		// The mail is out whatever happens now. Keep the schedule moving;
+		// at worst the next digest repeats some posts.
+		if runErr := m.recordRun(ctx, m.db, sub); runErr != nil {
+			err = errors.Join(err, runErr)
+		}
+		return fmt.Errorf("%w: %w", ErrNotRecorded, err)
+	}
+	return nil
+}
+
+func (m *Mailer) recordRun(ctx context.Context, q database.Querier, sub database.DigestSubscription) error {
+	err := q.RecordDigestRun(ctx, database.RecordDigestRunParams{
+		UserID:    sub.UserID,
+		LastRunAt: sql.NullTime{Time: m.now(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record digest run: %w", err)
+	}
+	return nil
+}
+
+// SendDue sends every subscription's digest that is due. A digest with no
+// posts is skipped but still counts as a run, so digests keep to their
+// schedule. It returns how many digests were sent; a failure for one user
+// does not stop the others.
+func (m *Mailer) SendDue(ctx context.Context) (int, error) {
+	subs, err := m.db.RetrieveDigestSubscriptions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve digest subscriptions: %w", err)
+	}
+
+	now := m.now()
+	sent := 0
+	var errs []error
+	for _, sub := range subs {
+		if now.Before(NextRun(sub)) {
+			continue
+		}
+		ok, err := m.sendDue(ctx, sub, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("digest for %s: %w", sub.Email, err))
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// sendDue builds and sends sub's digest, reporting whether it had any
+// posts to send.
+func (m *Mailer) sendDue(ctx context.Context, sub database.DigestSubscription, now time.Time) (bool, error) {
+	user, err := m.db.GetUserById(ctx, sub.UserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	d, err := Build(ctx, m.db, user, sub.Frequency, Since(sub, now), MaxPosts)
+	if err != nil {
+		return false, err
+	}
+	if d.Count == 0 {
+		return false, m.recordRun(ctx, m.db, sub)
+	}
+	if err := m.Send(ctx, sub, d); err != nil {
+		return errors.Is(err, ErrNotRecorded), err
+	}
+	return true, nil
+}
@@ -0,0 +1,51 @@
+package digest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func TestNextRun(t *testing.T) {
+	created := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	lastRun := time.Date(2024, 9, 3, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		frequency string
+		lastRun   sql.NullTime
+		want      time.Time
+	}{
+		{"never run", "daily", sql.NullTime{}, created},
+		{"daily", "daily", sql.NullTime{Time: lastRun, Valid: true}, lastRun.Add(24 * time.Hour)},
+		{"weekly", "weekly", sql.NullTime{Time: lastRun, Valid: true}, lastRun.Add(7 * 24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		sub := database.DigestSubscription{Frequency: tt.frequency, LastRunAt: tt.lastRun, CreatedAt: created}
+		if got := NextRun(sub); !got.Equal(tt.want) {
+			t.Errorf("%s: NextRun = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSince(t *testing.T) {
+	now := time.Date(2024, 9, 10, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		frequency string
+		lastRun   sql.NullTime
+		want      time.Time
+	}{
+		{"never run", "daily", sql.NullTime{}, now.Add(-24 * time.Hour)},
+		{"never run weekly", "weekly", sql.NullTime{}, now.Add(-7 * 24 * time.Hour)},
+		{"ran within the period", "daily", sql.NullTime{Time: now.Add(-time.Hour), Valid: true}, now.Add(-24 * time.Hour)},
+		{"missed runs", "daily", sql.NullTime{Time: now.Add(-72 * time.Hour), Valid: true}, now.Add(-72 * time.Hour)},
+	}
+	for _, tt := range tests {
+		sub := database.DigestSubscription{Frequency: tt.frequency, LastRunAt: tt.lastRun}
+		if got := Since(sub, now); !got.Equal(tt.want) {
+			t.Errorf("%s: Since = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
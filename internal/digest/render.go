@@ -0,0 +1,73 @@
+package digest
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+// postDate is the date shown for a post: when it was published, or when
+// gator saved it if the feed gave no date.
+func postDate(p database.RetrieveDigestPostsForUserRow) time.Time {
+	if p.PublishedAt.Valid {
+		return p.PublishedAt.Time
+	}
+	return p.CreatedAt
+}
+
+// Text renders d as plain text.
+func (d Digest) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Your %s gator digest for %s: %s.\n", d.Frequency, d.User.Name, d.Summary())
+	for _, section := range d.Sections {
+		fmt.Fprintf(&b, "\n== %s (%d) ==\n", section.Feed, len(section.Posts))
+		for _, p := range section.Posts {
+			fmt.Fprintf(&b, "\n- %s\n  %s\n  %s\n", p.Title, p.Url, postDate(p).Format("Mon, 02 Jan 2006 15:04"))
+			if p.Excerpt != "" {
+				fmt.Fprintf(&b, "  %s\n", p.Excerpt)
+			}
+		}
+	}
+	return b.String()
+}
+
+var htmlTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"date": func(p database.RetrieveDigestPostsForUserRow) string {
+		return postDate(p).Format("Mon, 02 Jan 2006 15:04")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Subject}}</title>
+</head>
+<body style="font-family: sans-serif; max-width: 40em; margin: 0 auto; color: #222;">
+<p>Your {{.Frequency}} gator digest for {{.User.Name}}: {{.Summary}}.</p>
+{{range .Sections}}
+<h2 style="font-size: 1.2em; border-bottom: 1px solid #ddd;"><a href="{{.URL}}" style="color: #222; text-decoration: none;">{{.Feed}}</a> ({{len .Posts}})</h2>
+<ul style="padding-left: 1.2em;">
+{{- range .Posts}}
+<li style="margin-bottom: 0.8em;">
+<a href="{{.Url}}">{{.Title}}</a><br>
+<small style="color: #777;">{{date .}}</small>
+{{- if .Excerpt}}<br>
+{{.Excerpt}}{{end}}
+</li>
+{{- end}}
+</ul>
+{{end}}
+</body>
+</html>
+`))
+
+// HTML renders d as an HTML page.
+func (d Digest) HTML() (string, error) {
+	var b strings.Builder
+	if err := htmlTemplate.Execute(&b, d); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,271 @@
+package filters
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Expressions combine terms with AND, OR, NOT and parentheses:
+//
+//	title:sponsored OR (category:jobs AND NOT author:"Jane Doe")
+//
+// A term is a word, a "quoted phrase" or a /regular expression/, optionally
+// prefixed with a field and a colon; terms without a field match any field.
+// Terms next to each other are ANDed, and a leading - negates a term like
+// NOT does. The operators must be written in capitals so that "and", "or"
+// and "not" can still be searched for.
+
+type tokenKind int
+
+const (
+	tokTerm tokenKind = iota
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	term Matcher
+	text string
+}
+
+type not struct{ m Matcher }
+
+func (n not) Match(post Post) bool { return !n.m.Match(post) }
+
+type and []Matcher
+
+func (a and) Match(post Post) bool {
+	return !slices.ContainsFunc(a, func(m Matcher) bool { return !m.Match(post) })
+}
+
+type or []Matcher
+
+func (o or) Match(post Post) bool {
+	return slices.ContainsFunc(o, func(m Matcher) bool { return m.Match(post) })
+}
+
+func parseExpr(s string) (Matcher, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	m, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %s", t.text)
+	}
+	return m, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) next() (token, bool) {
+	t, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return t, ok
+}
+
+// or = and { OR and }
+func (p *parser) or() (Matcher, error) {
+	m, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	terms := or{m}
+	for {
+		if t, ok := p.peek(); !ok || t.kind != tokOr {
+			break
+		}
+		p.next()
+		m, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, m)
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+// and = unary { [AND] unary }
+func (p *parser) and() (Matcher, error) {
+	m, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	terms := and{m}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokOr || t.kind == tokRParen {
+			break
+		}
+		if t.kind == tokAnd {
+			p.next()
+		}
+		m, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, m)
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+// unary = NOT unary | ( or ) | term
+func (p *parser) unary() (Matcher, error) {
+	t, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	switch t.kind {
+	case tokNot:
+		m, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return not{m}, nil
+	case tokLParen:
+		m, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.next(); !ok || t.kind != tokRParen {
+			return nil, fmt.Errorf("missing )")
+		}
+		return m, nil
+	case tokTerm:
+		return t.term, nil
+	}
+	return nil, fmt.Errorf("unexpected %s", t.text)
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	r := []rune(s)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "("})
+			i++
+			continue
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")"})
+			i++
+			continue
+		case c == '-' && i+1 < len(r) && !unicode.IsSpace(r[i+1]):
+			tokens = append(tokens, token{kind: tokNot, text: "-"})
+			i++
+			continue
+		}
+
+		field := FieldAny
+		start := i
+		for i < len(r) && isWordRune(r[i]) && r[i] != ':' {
+			i++
+		}
+		word := string(r[start:i])
+		if i < len(r) && r[i] == ':' && slices.Contains(Fields, word) {
+			field = word
+			i++
+		} else {
+			switch word {
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, text: word})
+				continue
+			case "OR":
+				tokens = append(tokens, token{kind: tokOr, text: word})
+				continue
+			case "NOT":
+				tokens = append(tokens, token{kind: tokNot, text: word})
+				continue
+			}
+			i = start
+		}
+
+		t, n, err := lexTerm(field, r[i:])
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+		i += n
+	}
+	return tokens, nil
+}
+
+// lexTerm reads the value of a term from the start of r, returning it and
+// the number of runes it took up.
+func lexTerm(field string, r []rune) (token, int, error) {
+	if len(r) == 0 {
+		return token{}, 0, fmt.Errorf("missing value after %s:", field)
+	}
+
+	switch r[0] {
+	case '"', '/':
+		quote := r[0]
+		var b strings.Builder
+		for i := 1; i < len(r); i++ {
+			switch {
+			case r[i] == '\\' && i+1 < len(r) && r[i+1] == quote:
+				b.WriteRune(quote)
+				i++
+			case r[i] == quote:
+				text := string(r[:i+1])
+				if quote == '"' {
+					return token{kind: tokTerm, term: containsTerm(field, b.String()), text: text}, i + 1, nil
+				}
+				m, err := regexTerm(field, b.String())
+				if err != nil {
+					return token{}, 0, err
+				}
+				return token{kind: tokTerm, term: m, text: text}, i + 1, nil
+			default:
+				b.WriteRune(r[i])
+			}
+		}
+		return token{}, 0, fmt.Errorf("unterminated %c", quote)
+	}
+
+	n := 0
+	for n < len(r) && isWordRune(r[n]) {
+		n++
+	}
+	if n == 0 {
+		return token{}, 0, fmt.Errorf("unexpected %c", r[0])
+	}
+	text := string(r[:n])
+	return token{kind: tokTerm, term: containsTerm(field, text), text: text}, n, nil
+}
+
+func isWordRune(c rune) bool {
+	return !unicode.IsSpace(c) && c != '(' && c != ')' && c != '"'
+}
@@ -0,0 +1,121 @@
+package filters
+
+import "testing"
+
+func TestExprMatch(t *testing.T) {
+	post := Post{
+		Title:       "Sponsored: The Go Release Party",
+		Description: "<p>Join <b>Jane Doe</b> for cake &amp; talks</p>",
+		Author:      "Jane Doe",
+		Categories:  []string{"Events", "golang"},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"sponsored", true},
+		{"SPONSORED", true},
+		{"missing", false},
+		{"title:sponsored", true},
+		{"author:sponsored", false},
+		{"description:cake", true},
+		{"description:<p>", false},
+		{"category:golang", true},
+		{"category:jobs", false},
+		{`author:"jane doe"`, true},
+		{`"release party"`, true},
+		{`"party release"`, false},
+		{"go release", true},
+		{"go missing", false},
+		{"go AND missing", false},
+		{"go OR missing", true},
+		{"missing OR nothing", false},
+		{"NOT missing", true},
+		{"-missing", true},
+		{"-sponsored", false},
+		{"NOT NOT sponsored", true},
+		{"go-lang", false},
+		{"title:/^Sponsored:/", true},
+		{"title:/^sponsored:/", false},
+		{"title:/(?i)^sponsored:/", true},
+		{`/Release\s+Party/`, true},
+		{"title:sponsored OR (category:jobs AND NOT author:\"Jane Doe\")", true},
+		{"title:missing OR (category:events AND NOT author:\"John Roe\")", true},
+		{"title:missing OR (category:events AND NOT author:\"Jane Doe\")", false},
+		{"(go OR rust) (cake OR pie)", true},
+		{"(go OR rust) (bread OR pie)", false},
+		{"go OR missing AND nothing", true},
+		{"missing OR go AND nothing", false},
+		{"and or not", false},
+		{"title:party:time", false},
+		{"unknown:field", false},
+	}
+	for _, tt := range tests {
+		m, err := Compile(FieldAny, MatchExpr, tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got := m.Match(post); got != tt.want {
+			t.Errorf("%q matched %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	for _, expr := range []string{
+		"(go",
+		"go)",
+		"go OR",
+		"AND go",
+		"NOT",
+		"()",
+		`"unterminated`,
+		"/unterminated",
+		"title:/[/",
+		"title:",
+	} {
+		if _, err := Compile(FieldAny, MatchExpr, expr); err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	post := Post{Title: "Hello World", Author: "Ann", Categories: []string{"News"}}
+
+	tests := []struct {
+		field, matchType, pattern string
+		want                      bool
+	}{
+		{FieldAny, MatchContains, "world", true},
+		{FieldTitle, MatchContains, "WORLD", true},
+		{FieldAuthor, MatchContains, "world", false},
+		{FieldCategory, MatchContains, "news", true},
+		{FieldTitle, MatchRegex, "^Hello", true},
+		{FieldTitle, MatchRegex, "^hello", false},
+		{FieldAny, MatchRegex, "^Ann$", true},
+	}
+	for _, tt := range tests {
+		m, err := Compile(tt.field, tt.matchType, tt.pattern)
+		if err != nil {
+			t.Errorf("Compile(%s, %s, %q) failed: %v", tt.field, tt.matchType, tt.pattern, err)
+			continue
+		}
+		if got := m.Match(post); got != tt.want {
+			t.Errorf("Compile(%s, %s, %q) matched %v, want %v", tt.field, tt.matchType, tt.pattern, got, tt.want)
+		}
+	}
+
+	for _, bad := range [][3]string{
+		{"body", MatchContains, "x"},
+		{FieldAny, "glob", "x"},
+		{FieldAny, MatchContains, ""},
+		{FieldAny, MatchRegex, "("},
+	} {
+		if _, err := Compile(bad[0], bad[1], bad[2]); err == nil {
+			t.Errorf("Compile(%q, %q, %q) succeeded, want an error", bad[0], bad[1], bad[2])
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package filters matches posts against users' filter rules and carries out
+// the rules' actions: hiding posts, marking them read, starring or tagging
+// them.
+package filters
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/richtext"
+)
+
+// Fields a rule can match on. FieldAny matches any of the others.
+const (
+	FieldAny         = "any"
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldAuthor      = "author"
+	FieldCategory    = "category"
+)
+
+// How a rule's pattern is interpreted.
+const (
+	MatchContains = "contains"
+	MatchRegex    = "regex"
+	MatchExpr     = "expr"
+)
+
+// What a rule does to the posts it matches.
+const (
+	ActionHide = "hide"
+	ActionRead = "read"
+	ActionStar = "star"
+	ActionTag  = "tag"
+)
+
+var (
+	Fields     = []string{FieldAny, FieldTitle, FieldDescription, FieldAuthor, FieldCategory}
+	MatchTypes = []string{MatchContains, MatchRegex, MatchExpr}
+	Actions    = []string{ActionHide, ActionRead, ActionStar, ActionTag}
+)
+
+type Post struct {
+	ID          int32
+	Title       string
+	Description string
+	Author      string
+	Categories  []string
+}
+
+// values returns the text of post that field refers to. Descriptions are
+// matched as plain text so patterns don't hit markup.
+func (p Post) values(field string) []string {
+	switch field {
+	case FieldTitle:
+		return []string{p.Title}
+	case FieldDescription:
+		return []string{richtext.Text(p.Description)}
+	case FieldAuthor:
+		return []string{p.Author}
+	case FieldCategory:
+		return p.Categories
+	}
+	return append([]string{p.Title, richtext.Text(p.Description), p.Author}, p.Categories...)
+}
+
+type Matcher interface {
+	Match(post Post) bool
+}
+
+// Compile builds a matcher for pattern. Substring matches ignore case;
+// regular expressions are case-sensitive unless they start with (?i).
+// Expressions name their own fields, so field is ignored for MatchExpr.
+func Compile(field, matchType, pattern string) (Matcher, error) {
+	if !slices.Contains(Fields, field) {
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	switch matchType {
+	case MatchContains:
+		return containsTerm(field, pattern), nil
+	case MatchRegex:
+		return regexTerm(field, pattern)
+	case MatchExpr:
+		return parseExpr(pattern)
+	}
+	return nil, fmt.Errorf("unknown match type %q", matchType)
+}
+
+// term matches when any value of its field satisfies match.
+type term struct {
+	field string
+	match func(string) bool
+}
+
+func (t term) Match(post Post) bool {
+	return slices.ContainsFunc(post.values(t.field), t.match)
+}
+
+func containsTerm(field, s string) term {
+	s = strings.ToLower(s)
+	return term{field, func(v string) bool {
+		return strings.Contains(strings.ToLower(v), s)
+	}}
+}
+
+func regexTerm(field, expr string) (term, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return term{}, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return term{field, re.MatchString}, nil
+}
+
+// Rule is a filter rule ready to be matched.
+type Rule struct {
+	database.FilterRule
+	matcher Matcher
+}
+
+func CompileRule(r database.FilterRule) (Rule, error) {
+	m, err := Compile(r.Field, r.MatchType, r.Pattern)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %d: %w", r.ID, err)
+	}
+	return Rule{FilterRule: r, matcher: m}, nil
+}
+
+// Match reports whether post, from feedID, falls under the rule.
+func (r Rule) Match(feedID int32, post Post) bool {
+	if r.FeedID.Valid && r.FeedID.Int32 != feedID {
+		return false
+	}
+	return r.matcher.Match(post)
+}
+
+// Apply carries out the rule's action on postID for the rule's owner.
+func (r Rule) Apply(ctx context.Context, db database.Querier, postID int32) error {
+	switch r.Action {
+	case ActionHide:
+		return db.SetPostHidden(ctx, database.SetPostHiddenParams{
+			UserID:   r.UserID,
+			PostID:   postID,
+			IsHidden: true,
+		})
+	case ActionRead:
+		return db.SetPostRead(ctx, database.SetPostReadParams{
+			UserID: r.UserID,
+			PostID: postID,
+			IsRead: true,
+		})
+	case ActionStar:
+		return db.SetPostStarred(ctx, database.SetPostStarredParams{
+			UserID:    r.UserID,
+			PostID:    postID,
+			IsStarred: true,
+		})
+	case ActionTag:
+		return db.CreatePostTag(ctx, database.CreatePostTagParams{
+			UserID: r.UserID,
+			PostID: postID,
+			Name:   r.Tag.String,
+		})
+	}
+	return fmt.Errorf("unknown action %q", r.Action)
+}
+
+// Apply runs the rules of every user following feed against a newly saved
+// post. It returns the number of rules that matched.
+func Apply(ctx context.Context, db database.Querier, feed database.Feed, post Post) (int, error) {
+	rules, err := db.RetrieveFilterRulesForFeed(ctx, sql.NullInt32{Int32: feed.ID, Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve filter rules: %w", err)
+	}
+
+	matched := 0
+	for _, r := range rules {
+		// Patterns are checked when a rule is added; one that no longer
+		// compiles is skipped rather than holding up every new post.
+		rule, err := CompileRule(r)
+		if err != nil || !rule.Match(feed.ID, post) {
+			continue
+		}
+		if err := rule.Apply(ctx, db, post.ID); err != nil {
+			return matched, fmt.Errorf("failed to apply rule %d: %w", r.ID, err)
+		}
+		matched++
+	}
+	return matched, nil
+}
@@ -0,0 +1,125 @@
+// Package mail sends email through an SMTP relay.
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Sender sends mail through the relay at Addr. Username and Password are
+// optional; net/smtp only sends them over TLS or to localhost.
+type Sender struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+// Message is an email to send. When HTML is set the message carries both
+// versions as multipart/alternative, and mail clients show the one they
+// prefer; Text should still be filled in for those that only show plain
+// text.
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+var ErrNotConfigured = errors.New("no SMTP server configured (set smtp_addr and smtp_from)")
+
+func (s Sender) Configured() bool {
+	return s.Addr != "" && s.From != ""
+}
+
+func (s Sender) Send(msg Message) error {
+	if !s.Configured() {
+		return ErrNotConfigured
+	}
+	if len(msg.To) == 0 {
+		return errors.New("no recipients")
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address %q: %w", s.Addr, err)
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	body, err := s.render(msg, time.Now())
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(s.Addr, auth, s.From, msg.To, body)
+}
+
+// render builds the RFC 5322 message. Bodies are quoted-printable, which
+// also turns their line breaks into CRLF, so long lines and non-ASCII text
+// survive relays.
+func (s Sender) render(msg Message, date time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", name, value)
+	}
+	header("From", s.From)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		header("Content-Type", `text/plain; charset="utf-8"`)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		b.WriteString("\r\n")
+		if err := writeQuotedPrintable(&b, msg.Text); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&b)
+	header("Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()}))
+	b.WriteString("\r\n")
+	// Clients show the last alternative they understand, so HTML goes last.
+	parts := []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	}
+	for _, part := range parts {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + `; charset="utf-8"`},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, text string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
@@ -0,0 +1,115 @@
+package mail
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	s := Sender{From: "gator@example.com"}
+	date := time.Date(2024, 9, 3, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		msg       Message
+		wantTypes []string
+	}{
+		{"text only", Message{Text: "héllo\nworld"}, []string{"text/plain"}},
+		{"with HTML", Message{Text: "héllo\nworld", HTML: "<p>héllo</p>"}, []string{"text/plain", "text/html"}},
+	}
+	for _, tt := range tests {
+		tt.msg.To = []string{"ann@example.com", "bob@example.com"}
+		tt.msg.Subject = "Your daily digest: 3 posts – ünïcode"
+		raw, err := s.render(tt.msg, date)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if bytes.Contains(bytes.ReplaceAll(raw, []byte("\r\n"), nil), []byte("\n")) {
+			t.Errorf("%s: message has bare line feeds:\n%s", tt.name, raw)
+		}
+
+		m, err := netmail.ReadMessage(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+		if err != nil || subject != tt.msg.Subject {
+			t.Errorf("%s: subject %q (%v), want %q", tt.name, subject, err, tt.msg.Subject)
+		}
+		for name, want := range map[string]string{
+			"From": "gator@example.com",
+			"To":   "ann@example.com, bob@example.com",
+			"Date": "Tue, 03 Sep 2024 12:00:00 +0000",
+		} {
+			if got := m.Header.Get(name); got != want {
+				t.Errorf("%s: %s header %q, want %q", tt.name, name, got, want)
+			}
+		}
+
+		var types, bodies []string
+		mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if mediaType == "multipart/alternative" {
+			mr := multipart.NewReader(m.Body, params["boundary"])
+			for {
+				// NextRawPart keeps the transfer encoding so it can be checked.
+				p, err := mr.NextRawPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", tt.name, err)
+				}
+				types = append(types, partType(t, p.Header.Get("Content-Type")))
+				bodies = append(bodies, decode(t, p, p.Header.Get("Content-Transfer-Encoding")))
+			}
+		} else {
+			types = append(types, mediaType)
+			bodies = append(bodies, decode(t, m.Body, m.Header.Get("Content-Transfer-Encoding")))
+		}
+
+		if strings.Join(types, ",") != strings.Join(tt.wantTypes, ",") {
+			t.Errorf("%s: parts %v, want %v", tt.name, types, tt.wantTypes)
+			continue
+		}
+		if bodies[0] != "héllo\r\nworld" {
+			t.Errorf("%s: text body %q", tt.name, bodies[0])
+		}
+		if len(bodies) > 1 && bodies[1] != tt.msg.HTML {
+			t.Errorf("%s: HTML body %q, want %q", tt.name, bodies[1], tt.msg.HTML)
+		}
+	}
+}
+
+func partType(t *testing.T, value string) string {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["charset"] != "utf-8" {
+		t.Errorf("%s part has charset %q", mediaType, params["charset"])
+	}
+	return mediaType
+}
+
+func decode(t *testing.T, r io.Reader, encoding string) string {
+	t.Helper()
+	if encoding != "quoted-printable" {
+		t.Errorf("transfer encoding %q, want quoted-printable", encoding)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
@@ -0,0 +1,208 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+var alertSinks = []string{"stdout", "file", "command", "smtp"}
+
+func (s *Store) CreateAlert(ctx context.Context, arg database.CreateAlertParams) (database.Alert, error) {
+	defer s.lock()()
+
+	if !slices.Contains(alertSinks, arg.Sink) {
+		return database.Alert{}, checkViolation("alerts_sink_check")
+	}
+	if _, err := s.userByID(arg.UserID); err != nil {
+		return database.Alert{}, foreignKey("alerts_user_id_fkey")
+	}
+	if arg.FeedID.Valid {
+		if _, err := s.feedByID(arg.FeedID.Int32); err != nil {
+			return database.Alert{}, foreignKey("alerts_feed_id_fkey")
+		}
+	}
+
+	now := s.now()
+	alert := database.Alert{
+		ID:          s.seq.nextval("alerts"),
+		UserID:      arg.UserID,
+		FeedID:      arg.FeedID,
+		Pattern:     arg.Pattern,
+		Sink:        arg.Sink,
+		Target:      arg.Target,
+		MaxPerHour:  arg.MaxPerHour,
+		DigestAfter: arg.DigestAfter,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.t.alerts = append(s.t.alerts, alert)
+	return alert, nil
+}
+
+func (s *Store) RetrieveAlertsForUser(ctx context.Context, userID uuid.UUID) ([]database.Alert, error) {
+	defer s.lock()()
+
+	var items []database.Alert
+	for _, a := range s.t.alerts {
+		if a.UserID == userID {
+			items = append(items, a)
+		}
+	}
+	return items, nil
+}
+
+// deleteAlerts removes the matching alerts and their events, returning how
+// many alerts were removed.
+func (s *Store) deleteAlerts(match func(database.Alert) bool) int64 {
+	deleted := map[int32]bool{}
+	s.t.alerts = slices.DeleteFunc(s.t.alerts, func(a database.Alert) bool {
+		if match(a) {
+			deleted[a.ID] = true
+		}
+		return deleted[a.ID]
+	})
+	s.t.alertEvents = slices.DeleteFunc(s.t.alertEvents, func(e database.AlertEvent) bool {
+		return deleted[e.AlertID]
+	})
+	return int64(len(deleted))
+}
+
+func (s *Store) DeleteAlert(ctx context.Context, arg database.DeleteAlertParams) (int64, error) {
+	defer s.lock()()
+	return s.deleteAlerts(func(a database.Alert) bool {
+		return a.ID == arg.ID && a.UserID == arg.UserID
+	}), nil
+}
+
+func (s *Store) RetrieveAlertsForFeed(ctx context.Context, feedID sql.NullInt32) ([]database.Alert, error) {
+	defer s.lock()()
+
+	if !feedID.Valid {
+		return nil, nil
+	}
+	var items []database.Alert
+	for _, a := range s.t.alerts {
+		if (a.FeedID == feedID || !a.FeedID.Valid) && s.isFollowing(a.UserID, feedID.Int32) {
+			items = append(items, a)
+		}
+	}
+	return items, nil
+}
+
+func (s *Store) MoveAlertsToFeed(ctx context.Context, arg database.MoveAlertsToFeedParams) error {
+	defer s.lock()()
+
+	if !arg.FromFeedID.Valid {
+		return nil
+	}
+	now := s.now()
+	for i, a := range s.t.alerts {
+		if a.FeedID == arg.FromFeedID {
+			s.t.alerts[i].FeedID = arg.ToFeedID
+			s.t.alerts[i].UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+func (s *Store) RecordAlertWindow(ctx context.Context, arg database.RecordAlertWindowParams) error {
+	defer s.lock()()
+
+	if i := find(s.t.alerts, func(a database.Alert) bool { return a.ID == arg.ID }); i >= 0 {
+		s.t.alerts[i].WindowStart = arg.WindowStart
+		s.t.alerts[i].WindowCount = arg.WindowCount
+		s.t.alerts[i].UpdatedAt = s.now()
+	}
+	return nil
+}
+
+func (s *Store) CreateAlertEvent(ctx context.Context, arg database.CreateAlertEventParams) error {
+	defer s.lock()()
+
+	if find(s.t.alerts, func(a database.Alert) bool { return a.ID == arg.AlertID }) < 0 {
+		return foreignKey("alert_events_alert_id_fkey")
+	}
+	if _, err := s.postByID(arg.PostID); err != nil {
+		return foreignKey("alert_events_post_id_fkey")
+	}
+	if find(s.t.alertEvents, func(e database.AlertEvent) bool {
+		return e.AlertID == arg.AlertID && e.PostID == arg.PostID
+	}) >= 0 {
+		return nil
+	}
+
+	now := s.now()
+	s.t.alertEvents = append(s.t.alertEvents, database.AlertEvent{
+		ID:        s.seq.nextval("alert_events"),
+		AlertID:   arg.AlertID,
+		PostID:    arg.PostID,
+		Status:    "pending",
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	return nil
+}
+
+func (s *Store) RetrieveAlertsWithPendingEvents(ctx context.Context) ([]database.Alert, error) {
+	defer s.lock()()
+
+	var items []database.Alert
+	for _, a := range s.t.alerts {
+		if find(s.t.alertEvents, func(e database.AlertEvent) bool {
+			return e.AlertID == a.ID && e.Status == "pending"
+		}) >= 0 {
+			items = append(items, a)
+		}
+	}
+	return items, nil
+}
+
+func (s *Store) RetrievePendingAlertEvents(ctx context.Context, alertID int32) ([]database.RetrievePendingAlertEventsRow, error) {
+	defer s.lock()()
+
+	alert, err := one(s.t.alerts, func(a database.Alert) bool { return a.ID == alertID })
+	if err != nil {
+		return nil, nil
+	}
+	var items []database.RetrievePendingAlertEventsRow
+	for _, e := range s.t.alertEvents {
+		if e.AlertID != alertID || e.Status != "pending" {
+			continue
+		}
+		post, err := s.postByID(e.PostID)
+		if err != nil {
+			continue
+		}
+		feed, _ := s.feedByID(post.FeedID)
+		items = append(items, database.RetrievePendingAlertEventsRow{
+			ID:          e.ID,
+			PostID:      e.PostID,
+			Attempts:    e.Attempts,
+			Title:       post.Title,
+			Url:         post.Url,
+			PublishedAt: post.PublishedAt,
+			FeedName:    s.feedTitle(alert.UserID, feed),
+		})
+	}
+	return items, nil
+}
+
+func (s *Store) RecordAlertEvents(ctx context.Context, arg database.RecordAlertEventsParams) error {
+	defer s.lock()()
+
+	now := s.now()
+	for i, e := range s.t.alertEvents {
+		if slices.Contains(arg.Ids, e.ID) {
+			s.t.alertEvents[i].Status = arg.Status
+			s.t.alertEvents[i].Attempts++
+			s.t.alertEvents[i].LastError = arg.LastError
+			s.t.alertEvents[i].UpdatedAt = now
+		}
+	}
+	return nil
+}
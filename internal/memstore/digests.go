@@ -0,0 +1,128 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func (s *Store) UpsertDigestSubscription(ctx context.Context, arg database.UpsertDigestSubscriptionParams) (database.DigestSubscription, error) {
+	defer s.lock()()
+
+	if arg.Frequency != "daily" && arg.Frequency != "weekly" {
+		return database.DigestSubscription{}, checkViolation("digest_subscriptions_frequency_check")
+	}
+	if _, err := s.userByID(arg.UserID); err != nil {
+		return database.DigestSubscription{}, foreignKey("digest_subscriptions_user_id_fkey")
+	}
+
+	now := s.now()
+	if i := find(s.t.digests, func(d database.DigestSubscription) bool { return d.UserID == arg.UserID }); i >= 0 {
+		s.t.digests[i].Email = arg.Email
+		s.t.digests[i].Frequency = arg.Frequency
+		s.t.digests[i].UpdatedAt = now
+		return s.t.digests[i], nil
+	}
+
+	sub := database.DigestSubscription{
+		UserID:    arg.UserID,
+		Email:     arg.Email,
+		Frequency: arg.Frequency,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.t.digests = append(s.t.digests, sub)
+	return sub, nil
+}
+
+func (s *Store) RetrieveDigestSubscription(ctx context.Context, userID uuid.UUID) (database.DigestSubscription, error) {
+	defer s.lock()()
+	return one(s.t.digests, func(d database.DigestSubscription) bool { return d.UserID == userID })
+}
+
+func (s *Store) RetrieveDigestSubscriptions(ctx context.Context) ([]database.DigestSubscription, error) {
+	defer s.lock()()
+	return slices.Clone(s.t.digests), nil
+}
+
+func (s *Store) DeleteDigestSubscription(ctx context.Context, userID uuid.UUID) (int64, error) {
+	defer s.lock()()
+
+	n := len(s.t.digests)
+	s.t.digests = slices.DeleteFunc(s.t.digests, func(d database.DigestSubscription) bool {
+		return d.UserID == userID
+	})
+	return int64(n - len(s.t.digests)), nil
+}
+
+func (s *Store) RecordDigestRun(ctx context.Context, arg database.RecordDigestRunParams) error {
+	defer s.lock()()
+
+	if i := find(s.t.digests, func(d database.DigestSubscription) bool { return d.UserID == arg.UserID }); i >= 0 {
+		s.t.digests[i].LastRunAt = arg.LastRunAt
+		s.t.digests[i].UpdatedAt = s.now()
+	}
+	return nil
+}
+
+// RetrieveDigestPostsForUser returns unread posts saved after since that
+// have not been in one of the user's digests yet, leaving out muted feeds
+// and hidden posts.
+func (s *Store) RetrieveDigestPostsForUser(ctx context.Context, arg database.RetrieveDigestPostsForUserParams) ([]database.RetrieveDigestPostsForUserRow, error) {
+	defer s.lock()()
+
+	var posts []database.Post
+	for _, p := range s.followedPosts(arg.UserID) {
+		if s.isMuted(arg.UserID, p.FeedID) || !p.CreatedAt.After(arg.Since) {
+			continue
+		}
+		st := s.state(arg.UserID, p.ID)
+		if st.IsRead || st.IsHidden {
+			continue
+		}
+		if _, sent := s.t.digestPosts[stateKey{arg.UserID, p.ID}]; sent {
+			continue
+		}
+		posts = append(posts, p)
+	}
+	slices.SortFunc(posts, newestFirst(displayDate))
+
+	var items []database.RetrieveDigestPostsForUserRow
+	for _, p := range limit(posts, arg.Limit) {
+		feed, _ := s.feedByID(p.FeedID)
+		ff, _ := s.follow(arg.UserID, p.FeedID)
+		items = append(items, database.RetrieveDigestPostsForUserRow{
+			ID:          p.ID,
+			Title:       p.Title,
+			Url:         p.Url,
+			Excerpt:     p.Excerpt,
+			PublishedAt: p.PublishedAt,
+			CreatedAt:   p.CreatedAt,
+			FeedID:      p.FeedID,
+			FeedName:    s.feedTitle(arg.UserID, feed),
+			FeedUrl:     feed.Url,
+			Priority:    ff.Priority,
+		})
+	}
+	return items, nil
+}
+
+func (s *Store) CreateDigestPost(ctx context.Context, arg database.CreateDigestPostParams) error {
+	defer s.lock()()
+
+	if _, err := s.userByID(arg.UserID); err != nil {
+		return foreignKey("digest_posts_user_id_fkey")
+	}
+	if _, err := s.postByID(arg.PostID); err != nil {
+		return foreignKey("digest_posts_post_id_fkey")
+	}
+
+	key := stateKey{arg.UserID, arg.PostID}
+	if _, ok := s.t.digestPosts[key]; !ok {
+		s.t.digestPosts[key] = database.DigestPost{UserID: arg.UserID, PostID: arg.PostID, SentAt: s.now()}
+	}
+	return nil
+}
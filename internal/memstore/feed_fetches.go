@@ -0,0 +1,115 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func (s *Store) CreateFeedFetch(ctx context.Context, arg database.CreateFeedFetchParams) error {
+	defer s.lock()()
+
+	if _, err := s.feedByID(arg.FeedID); err != nil {
+		return foreignKey("feed_fetches_feed_id_fkey")
+	}
+	s.t.fetches = append(s.t.fetches, database.FeedFetch{
+		ID:           s.seq.nextval("feed_fetches"),
+		FeedID:       arg.FeedID,
+		StartedAt:    arg.StartedAt,
+		FinishedAt:   arg.FinishedAt,
+		StatusCode:   arg.StatusCode,
+		Bytes:        arg.Bytes,
+		ItemCount:    arg.ItemCount,
+		NewPostCount: arg.NewPostCount,
+		Error:        arg.Error,
+		ErrorKind:    arg.ErrorKind,
+		RedirectUrl:  arg.RedirectUrl,
+	})
+	return nil
+}
+
+// fetchesNewestFirst returns the fetches of a feed ordered by start time
+// descending, then ID descending.
+func (s *Store) fetchesNewestFirst(feedID int32) []database.FeedFetch {
+	var fetches []database.FeedFetch
+	for _, x := range s.t.fetches {
+		if x.FeedID == feedID {
+			fetches = append(fetches, x)
+		}
+	}
+	slices.SortFunc(fetches, func(a, b database.FeedFetch) int {
+		if c := b.StartedAt.Compare(a.StartedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	return fetches
+}
+
+func (s *Store) RetrieveFeedFetches(ctx context.Context, arg database.RetrieveFeedFetchesParams) ([]database.FeedFetch, error) {
+	defer s.lock()()
+	return limit(s.fetchesNewestFirst(arg.FeedID), arg.Limit), nil
+}
+
+func (s *Store) DeleteFeedFetchesBefore(ctx context.Context, arg database.DeleteFeedFetchesBeforeParams) (int64, error) {
+	defer s.lock()()
+
+	before := len(s.t.fetches)
+	s.t.fetches = slices.DeleteFunc(s.t.fetches, func(x database.FeedFetch) bool {
+		return x.FeedID == arg.FeedID && x.StartedAt.Before(arg.StartedAt)
+	})
+	return int64(before - len(s.t.fetches)), nil
+}
+
+func (s *Store) RetrieveFeedHealth(ctx context.Context) ([]database.RetrieveFeedHealthRow, error) {
+	defer s.lock()()
+
+	feeds := slices.Clone(s.t.feeds)
+	slices.SortFunc(feeds, func(a, b database.Feed) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	var items []database.RetrieveFeedHealthRow
+	for _, f := range feeds {
+		row := database.RetrieveFeedHealthRow{
+			ID:            f.ID,
+			Name:          f.Name,
+			Url:           f.Url,
+			UserID:        f.UserID,
+			CreatedAt:     f.CreatedAt,
+			LastFetchedAt: f.LastFetchedAt,
+			DeadAt:        f.DeadAt,
+			FollowerCount: int64(len(s.followers(f.ID))),
+			LastPostAt:    f.CreatedAt,
+		}
+
+		var lastPost *database.Post
+		for i, p := range s.t.posts {
+			if p.FeedID == f.ID && (lastPost == nil || p.CreatedAt.After(lastPost.CreatedAt)) {
+				lastPost = &s.t.posts[i]
+			}
+		}
+		if lastPost != nil {
+			row.LastPostAt = lastPost.CreatedAt
+		}
+
+		fetches := s.fetchesNewestFirst(f.ID)
+		if len(fetches) > 0 {
+			row.LastError = fetches[0].Error
+			row.LastErrorKind = fetches[0].ErrorKind
+			row.LastRedirectUrl = fetches[0].RedirectUrl
+		}
+		// Failures since the latest successful fetch. Fetches are sorted by
+		// start time, so this counts until the first success.
+		for _, x := range fetches {
+			if !x.Error.Valid {
+				break
+			}
+			row.ConsecutiveFailures++
+		}
+		items = append(items, row)
+	}
+	return items, nil
+}
@@ -0,0 +1,230 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func (s *Store) isFollowing(userID uuid.UUID, feedID int32) bool {
+	return find(s.t.follows, func(ff database.FeedFollow) bool {
+		return ff.UserID == userID && ff.FeedID == feedID
+	}) >= 0
+}
+
+// follow returns userID's follow of feedID.
+func (s *Store) follow(userID uuid.UUID, feedID int32) (database.FeedFollow, bool) {
+	i := find(s.t.follows, func(ff database.FeedFollow) bool {
+		return ff.UserID == userID && ff.FeedID == feedID
+	})
+	if i < 0 {
+		return database.FeedFollow{}, false
+	}
+	return s.t.follows[i], true
+}
+
+func (s *Store) isMuted(userID uuid.UUID, feedID int32) bool {
+	ff, _ := s.follow(userID, feedID)
+	return ff.Muted
+}
+
+// feedTitle returns the name userID sees for feed: their own title for it,
+// as COALESCE(ff.title, f.name) does, or else the feed's name.
+func (s *Store) feedTitle(userID uuid.UUID, feed database.Feed) string {
+	if ff, ok := s.follow(userID, feed.ID); ok && ff.Title.Valid {
+		return ff.Title.String
+	}
+	return feed.Name
+}
+
+// followers returns the IDs of the users following feedID.
+func (s *Store) followers(feedID int32) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, ff := range s.t.follows {
+		if ff.FeedID == feedID {
+			ids = append(ids, ff.UserID)
+		}
+	}
+	return ids
+}
+
+func (s *Store) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	defer s.lock()()
+
+	if s.isFollowing(arg.UserID, arg.FeedID) {
+		return database.CreateFeedFollowRow{}, duplicateKey("feed_follows_user_id_feed_id_key")
+	}
+	user, err := s.userByID(arg.UserID)
+	if err != nil {
+		return database.CreateFeedFollowRow{}, foreignKey("feed_follows_user_id_fkey")
+	}
+	feed, err := s.feedByID(arg.FeedID)
+	if err != nil {
+		return database.CreateFeedFollowRow{}, foreignKey("feed_follows_feed_id_fkey")
+	}
+
+	now := s.now()
+	ff := database.FeedFollow{
+		ID:        s.seq.nextval("feed_follows"),
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Notify:    "all",
+	}
+	s.t.follows = append(s.t.follows, ff)
+	return database.CreateFeedFollowRow{
+		ID:        ff.ID,
+		UserID:    ff.UserID,
+		FeedID:    ff.FeedID,
+		CreatedAt: ff.CreatedAt,
+		UpdatedAt: ff.UpdatedAt,
+		UserName:  user.Name,
+		FeedName:  feed.Name,
+	}, nil
+}
+
+func (s *Store) RetrieveFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.RetrieveFeedFollowsForUserRow, error) {
+	defer s.lock()()
+
+	user, err := s.userByID(userID)
+	if err != nil {
+		return nil, nil
+	}
+	var items []database.RetrieveFeedFollowsForUserRow
+	for _, ff := range s.t.follows {
+		if ff.UserID != userID {
+			continue
+		}
+		feed, err := s.feedByID(ff.FeedID)
+		if err != nil {
+			continue
+		}
+		var folderName sql.NullString
+		if ff.FolderID.Valid {
+			if folder, err := s.folderByID(ff.FolderID.Int32); err == nil {
+				folderName = sql.NullString{String: folder.Name, Valid: true}
+			}
+		}
+		items = append(items, database.RetrieveFeedFollowsForUserRow{
+			ID:         ff.ID,
+			UserID:     ff.UserID,
+			FeedID:     ff.FeedID,
+			CreatedAt:  ff.CreatedAt,
+			UpdatedAt:  ff.UpdatedAt,
+			UserName:   user.Name,
+			FeedName:   feed.Name,
+			FolderID:   ff.FolderID,
+			FolderName: folderName,
+			FeedUrl:    feed.Url,
+			Title:      ff.Title,
+			Muted:      ff.Muted,
+			Notify:     ff.Notify,
+			Priority:   ff.Priority,
+		})
+	}
+	// Unfiled feeds come first, as NULLS FIRST puts them.
+	slices.SortStableFunc(items, func(a, b database.RetrieveFeedFollowsForUserRow) int {
+		if a.FolderName.Valid != b.FolderName.Valid {
+			if a.FolderName.Valid {
+				return 1
+			}
+			return -1
+		}
+		if c := cmp.Compare(a.FolderName.String, b.FolderName.String); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(displayName(a), displayName(b))
+	})
+	return items, nil
+}
+
+func (s *Store) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) error {
+	defer s.lock()()
+	s.t.follows = slices.DeleteFunc(s.t.follows, func(ff database.FeedFollow) bool {
+		return ff.UserID == arg.UserID && ff.FeedID == arg.FeedID
+	})
+	return nil
+}
+
+func (s *Store) DeleteFeedFollowsForFeed(ctx context.Context, feedID int32) error {
+	defer s.lock()()
+	s.t.follows = slices.DeleteFunc(s.t.follows, func(ff database.FeedFollow) bool {
+		return ff.FeedID == feedID
+	})
+	return nil
+}
+
+func (s *Store) MoveFeedFollows(ctx context.Context, arg database.MoveFeedFollowsParams) error {
+	defer s.lock()()
+
+	if _, err := s.feedByID(arg.ToFeedID); err != nil {
+		if len(s.followers(arg.FromFeedID)) > 0 {
+			return foreignKey("feed_follows_feed_id_fkey")
+		}
+		return nil
+	}
+	now := s.now()
+	for _, ff := range slices.Clone(s.t.follows) {
+		if ff.FeedID != arg.FromFeedID || s.isFollowing(ff.UserID, arg.ToFeedID) {
+			continue
+		}
+		s.t.follows = append(s.t.follows, database.FeedFollow{
+			ID:        s.seq.nextval("feed_follows"),
+			UserID:    ff.UserID,
+			FeedID:    arg.ToFeedID,
+			CreatedAt: ff.CreatedAt,
+			UpdatedAt: now,
+			FolderID:  ff.FolderID,
+			Title:     ff.Title,
+			Muted:     ff.Muted,
+			Notify:    ff.Notify,
+			Priority:  ff.Priority,
+		})
+	}
+	return nil
+}
+
+func displayName(ff database.RetrieveFeedFollowsForUserRow) string {
+	if ff.Title.Valid {
+		return ff.Title.String
+	}
+	return ff.FeedName
+}
+
+func (s *Store) RetrieveFeedFollow(ctx context.Context, arg database.RetrieveFeedFollowParams) (database.FeedFollow, error) {
+	defer s.lock()()
+
+	ff, ok := s.follow(arg.UserID, arg.FeedID)
+	if !ok {
+		return database.FeedFollow{}, sql.ErrNoRows
+	}
+	return ff, nil
+}
+
+func (s *Store) UpdateFeedFollowSettings(ctx context.Context, arg database.UpdateFeedFollowSettingsParams) error {
+	defer s.lock()()
+
+	if arg.Notify != "all" && arg.Notify != "none" {
+		return checkViolation("feed_follows_notify_check")
+	}
+	now := s.now()
+	for i, ff := range s.t.follows {
+		if ff.UserID == arg.UserID && ff.FeedID == arg.FeedID {
+			s.t.follows[i].Title = arg.Title
+			s.t.follows[i].Muted = arg.Muted
+			s.t.follows[i].Notify = arg.Notify
+			s.t.follows[i].Priority = arg.Priority
+			s.t.follows[i].UpdatedAt = now
+		}
+	}
+	return nil
+}
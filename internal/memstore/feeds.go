@@ -0,0 +1,260 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func (s *Store) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	defer s.lock()()
+
+	if find(s.t.feeds, func(f database.Feed) bool { return f.Url == arg.Url }) >= 0 {
+		return database.Feed{}, duplicateKey("feeds_url_key")
+	}
+	if _, err := s.userByID(arg.UserID); err != nil {
+		return database.Feed{}, foreignKey("feeds_user_id_fkey")
+	}
+
+	feed := database.Feed{
+		ID:        s.seq.nextval("feeds"),
+		Url:       arg.Url,
+		Name:      arg.Name,
+		UserID:    arg.UserID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Kind:      "rss",
+	}
+	s.t.feeds = append(s.t.feeds, feed)
+	return feed, nil
+}
+
+func (s *Store) RetrieveFeedsWithUser(ctx context.Context) ([]database.RetrieveFeedsWithUserRow, error) {
+	defer s.lock()()
+
+	var items []database.RetrieveFeedsWithUserRow
+	for _, f := range s.t.feeds {
+		row := database.RetrieveFeedsWithUserRow{
+			ID:               f.ID,
+			Url:              f.Url,
+			Name:             f.Name,
+			UserID:           f.UserID,
+			CreatedAt:        f.CreatedAt,
+			UpdatedAt:        f.UpdatedAt,
+			LastFetchedAt:    f.LastFetchedAt,
+			DeadAt:           f.DeadAt,
+			FetchFullContent: f.FetchFullContent,
+			Kind:             f.Kind,
+			ScrapeRules:      f.ScrapeRules,
+		}
+		if u, err := s.userByID(f.UserID); err == nil {
+			row.ID_2 = uuid.NullUUID{UUID: u.ID, Valid: true}
+			row.Name_2 = sql.NullString{String: u.Name, Valid: true}
+			row.CreatedAt_2 = nullTime(u.CreatedAt)
+			row.UpdatedAt_2 = nullTime(u.UpdatedAt)
+		}
+		items = append(items, row)
+	}
+	return items, nil
+}
+
+func (s *Store) RetrieveFeedWithURL(ctx context.Context, url string) (database.Feed, error) {
+	defer s.lock()()
+	return one(s.t.feeds, func(f database.Feed) bool { return f.Url == url })
+}
+
+func (s *Store) RetrieveFeedByID(ctx context.Context, id int32) (database.Feed, error) {
+	defer s.lock()()
+	return s.feedByID(id)
+}
+
+func (s *Store) feedByID(id int32) (database.Feed, error) {
+	return one(s.t.feeds, func(f database.Feed) bool { return f.ID == id })
+}
+
+func (s *Store) RetrieveNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	defer s.lock()()
+
+	var next *database.Feed
+	for i, f := range s.t.feeds {
+		if f.DeadAt.Valid {
+			continue
+		}
+		if next == nil || fetchedBefore(f.LastFetchedAt, next.LastFetchedAt) {
+			next = &s.t.feeds[i]
+		}
+	}
+	if next == nil {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	return *next, nil
+}
+
+// fetchedBefore orders last_fetched_at ascending with NULLs first.
+func fetchedBefore(a, b sql.NullTime) bool {
+	if !a.Valid || !b.Valid {
+		return !a.Valid && b.Valid
+	}
+	return a.Time.Before(b.Time)
+}
+
+func (s *Store) RetrieveFollowedFeedsForUser(ctx context.Context, userID uuid.UUID) ([]database.Feed, error) {
+	defer s.lock()()
+
+	var items []database.Feed
+	for _, ff := range s.t.follows {
+		if ff.UserID != userID {
+			continue
+		}
+		if f, err := s.feedByID(ff.FeedID); err == nil {
+			items = append(items, f)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.Feed) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return items, nil
+}
+
+// updateFeed applies fn to the feed with the given ID, if any.
+func (s *Store) updateFeed(id int32, fn func(*database.Feed)) {
+	if i := find(s.t.feeds, func(f database.Feed) bool { return f.ID == id }); i >= 0 {
+		fn(&s.t.feeds[i])
+		s.t.feeds[i].UpdatedAt = s.now()
+	}
+}
+
+func (s *Store) MarkFeedFetched(ctx context.Context, id int32) error {
+	defer s.lock()()
+	s.updateFeed(id, func(f *database.Feed) {
+		f.LastFetchedAt = nullTime(s.now())
+	})
+	return nil
+}
+
+func (s *Store) UpdateFeedURL(ctx context.Context, arg database.UpdateFeedURLParams) error {
+	defer s.lock()()
+
+	if find(s.t.feeds, func(f database.Feed) bool { return f.Url == arg.Url && f.ID != arg.ID }) >= 0 {
+		return duplicateKey("feeds_url_key")
+	}
+	s.updateFeed(arg.ID, func(f *database.Feed) {
+		f.Url = arg.Url
+	})
+	return nil
+}
+
+func (s *Store) RenameFeed(ctx context.Context, arg database.RenameFeedParams) error {
+	defer s.lock()()
+	s.updateFeed(arg.ID, func(f *database.Feed) {
+		f.Name = arg.Name
+	})
+	return nil
+}
+
+// DeleteFeed removes a feed with its follows, posts, webhooks, filter rules,
+// alerts and fetch history, matching the foreign keys.
+func (s *Store) DeleteFeed(ctx context.Context, id int32) error {
+	defer s.lock()()
+
+	s.t.feeds = slices.DeleteFunc(s.t.feeds, func(f database.Feed) bool { return f.ID == id })
+	s.t.follows = slices.DeleteFunc(s.t.follows, func(ff database.FeedFollow) bool { return ff.FeedID == id })
+	s.deletePosts(func(p database.Post) bool { return p.FeedID == id })
+	s.t.fetches = slices.DeleteFunc(s.t.fetches, func(x database.FeedFetch) bool { return x.FeedID == id })
+	s.deleteWebhooks(func(w database.Webhook) bool {
+		return w.FeedID.Valid && w.FeedID.Int32 == id
+	})
+	s.t.rules = slices.DeleteFunc(s.t.rules, func(r database.FilterRule) bool {
+		return r.FeedID.Valid && r.FeedID.Int32 == id
+	})
+	s.deleteAlerts(func(a database.Alert) bool {
+		return a.FeedID.Valid && a.FeedID.Int32 == id
+	})
+	return nil
+}
+
+func (s *Store) CountFeedReferences(ctx context.Context, feedID int32) (database.CountFeedReferencesRow, error) {
+	defer s.lock()()
+
+	var row database.CountFeedReferencesRow
+	for _, ff := range s.t.follows {
+		if ff.FeedID == feedID {
+			row.FollowCount++
+		}
+	}
+	posts := map[int32]bool{}
+	for _, p := range s.t.posts {
+		if p.FeedID == feedID {
+			posts[p.ID] = true
+			row.PostCount++
+		}
+	}
+	for key := range s.t.postStates {
+		if posts[key.postID] {
+			row.PostStateCount++
+		}
+	}
+	for _, w := range s.t.webhooks {
+		if w.FeedID.Valid && w.FeedID.Int32 == feedID {
+			row.WebhookCount++
+		}
+	}
+	for _, x := range s.t.fetches {
+		if x.FeedID == feedID {
+			row.FetchCount++
+		}
+	}
+	for _, r := range s.t.rules {
+		if r.FeedID.Valid && r.FeedID.Int32 == feedID {
+			row.FilterRuleCount++
+		}
+	}
+	for _, t := range s.t.tags {
+		if posts[t.PostID] {
+			row.PostTagCount++
+		}
+	}
+	alerts := map[int32]bool{}
+	for _, a := range s.t.alerts {
+		if a.FeedID.Valid && a.FeedID.Int32 == feedID {
+			alerts[a.ID] = true
+			row.AlertCount++
+		}
+	}
+	for _, e := range s.t.alertEvents {
+		if alerts[e.AlertID] || posts[e.PostID] {
+			row.AlertEventCount++
+		}
+	}
+	return row, nil
+}
+
+func (s *Store) SetFeedDead(ctx context.Context, id int32) error {
+	defer s.lock()()
+	s.updateFeed(id, func(f *database.Feed) {
+		f.DeadAt = nullTime(s.now())
+	})
+	return nil
+}
+
+func (s *Store) SetFeedFullContent(ctx context.Context, arg database.SetFeedFullContentParams) error {
+	defer s.lock()()
+	s.updateFeed(arg.ID, func(f *database.Feed) {
+		f.FetchFullContent = arg.FetchFullContent
+	})
+	return nil
+}
+
+func (s *Store) SetFeedScrapeRules(ctx context.Context, arg database.SetFeedScrapeRulesParams) error {
+	defer s.lock()()
+	s.updateFeed(arg.ID, func(f *database.Feed) {
+		f.Kind = arg.Kind
+		f.ScrapeRules = arg.ScrapeRules
+	})
+	return nil
+}
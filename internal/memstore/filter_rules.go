@@ -0,0 +1,165 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+var (
+	ruleFields     = []string{"any", "title", "description", "author", "category"}
+	ruleMatchTypes = []string{"contains", "regex", "expr"}
+	ruleActions    = []string{"hide", "read", "star", "tag"}
+)
+
+func (s *Store) CreateFilterRule(ctx context.Context, arg database.CreateFilterRuleParams) (database.FilterRule, error) {
+	defer s.lock()()
+
+	switch {
+	case !slices.Contains(ruleFields, arg.Field):
+		return database.FilterRule{}, checkViolation("filter_rules_field_check")
+	case !slices.Contains(ruleMatchTypes, arg.MatchType):
+		return database.FilterRule{}, checkViolation("filter_rules_match_type_check")
+	case !slices.Contains(ruleActions, arg.Action):
+		return database.FilterRule{}, checkViolation("filter_rules_action_check")
+	}
+	if _, err := s.userByID(arg.UserID); err != nil {
+		return database.FilterRule{}, foreignKey("filter_rules_user_id_fkey")
+	}
+	if arg.FeedID.Valid {
+		if _, err := s.feedByID(arg.FeedID.Int32); err != nil {
+			return database.FilterRule{}, foreignKey("filter_rules_feed_id_fkey")
+		}
+	}
+
+	now := s.now()
+	rule := database.FilterRule{
+		ID:        s.seq.nextval("filter_rules"),
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+		Field:     arg.Field,
+		MatchType: arg.MatchType,
+		Pattern:   arg.Pattern,
+		Action:    arg.Action,
+		Tag:       arg.Tag,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.t.rules = append(s.t.rules, rule)
+	return rule, nil
+}
+
+func (s *Store) RetrieveFilterRulesForUser(ctx context.Context, userID uuid.UUID) ([]database.FilterRule, error) {
+	defer s.lock()()
+
+	var items []database.FilterRule
+	for _, r := range s.t.rules {
+		if r.UserID == userID {
+			items = append(items, r)
+		}
+	}
+	return items, nil
+}
+
+func (s *Store) DeleteFilterRule(ctx context.Context, arg database.DeleteFilterRuleParams) (int64, error) {
+	defer s.lock()()
+
+	n := len(s.t.rules)
+	s.t.rules = slices.DeleteFunc(s.t.rules, func(r database.FilterRule) bool {
+		return r.ID == arg.ID && r.UserID == arg.UserID
+	})
+	return int64(n - len(s.t.rules)), nil
+}
+
+func (s *Store) RetrieveFilterRulesForFeed(ctx context.Context, feedID sql.NullInt32) ([]database.FilterRule, error) {
+	defer s.lock()()
+
+	if !feedID.Valid {
+		return nil, nil
+	}
+	var items []database.FilterRule
+	for _, r := range s.t.rules {
+		if (r.FeedID == feedID || !r.FeedID.Valid) && s.isFollowing(r.UserID, feedID.Int32) {
+			items = append(items, r)
+		}
+	}
+	return items, nil
+}
+
+func (s *Store) MoveFilterRulesToFeed(ctx context.Context, arg database.MoveFilterRulesToFeedParams) error {
+	defer s.lock()()
+
+	if !arg.FromFeedID.Valid {
+		return nil
+	}
+	now := s.now()
+	for i, r := range s.t.rules {
+		if r.FeedID == arg.FromFeedID {
+			s.t.rules[i].FeedID = arg.ToFeedID
+			s.t.rules[i].UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+func (s *Store) RetrieveFilterablePostsForUser(ctx context.Context, arg database.RetrieveFilterablePostsForUserParams) ([]database.RetrieveFilterablePostsForUserRow, error) {
+	defer s.lock()()
+
+	var items []database.RetrieveFilterablePostsForUserRow
+	for _, p := range s.followedPosts(arg.UserID) {
+		if arg.FeedID.Valid && p.FeedID != arg.FeedID.Int32 {
+			continue
+		}
+		items = append(items, database.RetrieveFilterablePostsForUserRow{
+			ID:          p.ID,
+			FeedID:      p.FeedID,
+			Title:       p.Title,
+			Description: p.Description,
+			Author:      p.Author,
+		})
+	}
+	return items, nil
+}
+
+func (s *Store) CreatePostTag(ctx context.Context, arg database.CreatePostTagParams) error {
+	defer s.lock()()
+
+	if _, err := s.userByID(arg.UserID); err != nil {
+		return foreignKey("post_tags_user_id_fkey")
+	}
+	if _, err := s.postByID(arg.PostID); err != nil {
+		return foreignKey("post_tags_post_id_fkey")
+	}
+	tag := database.PostTag{UserID: arg.UserID, PostID: arg.PostID, Name: arg.Name}
+	if !slices.Contains(s.t.tags, tag) {
+		s.t.tags = append(s.t.tags, tag)
+	}
+	return nil
+}
+
+func (s *Store) RetrieveTagsForPosts(ctx context.Context, arg database.RetrieveTagsForPostsParams) ([]database.RetrieveTagsForPostsRow, error) {
+	defer s.lock()()
+
+	var items []database.RetrieveTagsForPostsRow
+	for _, t := range s.t.tags {
+		if t.UserID == arg.UserID && slices.Contains(arg.PostIds, t.PostID) {
+			items = append(items, database.RetrieveTagsForPostsRow{PostID: t.PostID, Name: t.Name})
+		}
+	}
+	slices.SortFunc(items, func(a, b database.RetrieveTagsForPostsRow) int {
+		if c := cmp.Compare(a.PostID, b.PostID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return items, nil
+}
+
+func (s *Store) hasTag(userID uuid.UUID, postID int32, name string) bool {
+	return slices.Contains(s.t.tags, database.PostTag{UserID: userID, PostID: postID, Name: name})
+}
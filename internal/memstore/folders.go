@@ -0,0 +1,154 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func (s *Store) folderByID(id int32) (database.Folder, error) {
+	return one(s.t.folders, func(f database.Folder) bool { return f.ID == id })
+}
+
+func (s *Store) hasFolder(userID uuid.UUID, name string) bool {
+	return find(s.t.folders, func(f database.Folder) bool {
+		return f.UserID == userID && f.Name == name
+	}) >= 0
+}
+
+func (s *Store) CreateFolder(ctx context.Context, arg database.CreateFolderParams) (database.Folder, error) {
+	defer s.lock()()
+
+	if s.hasFolder(arg.UserID, arg.Name) {
+		return database.Folder{}, duplicateKey("folders_user_id_name_key")
+	}
+	if _, err := s.userByID(arg.UserID); err != nil {
+		return database.Folder{}, foreignKey("folders_user_id_fkey")
+	}
+
+	now := s.now()
+	f := database.Folder{
+		ID:        s.seq.nextval("folders"),
+		UserID:    arg.UserID,
+		Name:      arg.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.t.folders = append(s.t.folders, f)
+	return f, nil
+}
+
+func (s *Store) RetrieveFoldersForUser(ctx context.Context, userID uuid.UUID) ([]database.Folder, error) {
+	defer s.lock()()
+
+	var items []database.Folder
+	for _, f := range s.t.folders {
+		if f.UserID == userID {
+			items = append(items, f)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.Folder) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return items, nil
+}
+
+func (s *Store) RetrieveFolderByName(ctx context.Context, arg database.RetrieveFolderByNameParams) (database.Folder, error) {
+	defer s.lock()()
+	return one(s.t.folders, func(f database.Folder) bool {
+		return f.UserID == arg.UserID && f.Name == arg.Name
+	})
+}
+
+func (s *Store) RenameFolder(ctx context.Context, arg database.RenameFolderParams) error {
+	defer s.lock()()
+
+	i := find(s.t.folders, func(f database.Folder) bool { return f.ID == arg.ID })
+	if i < 0 {
+		return nil
+	}
+	f := &s.t.folders[i]
+	if f.Name != arg.Name && s.hasFolder(f.UserID, arg.Name) {
+		return duplicateKey("folders_user_id_name_key")
+	}
+	f.Name = arg.Name
+	f.UpdatedAt = s.now()
+	return nil
+}
+
+// DeleteFolder deletes a folder and, like ON DELETE SET NULL, leaves the
+// feeds that were in it unfiled.
+func (s *Store) DeleteFolder(ctx context.Context, id int32) error {
+	defer s.lock()()
+
+	s.t.folders = slices.DeleteFunc(s.t.folders, func(f database.Folder) bool {
+		return f.ID == id
+	})
+	for i, ff := range s.t.follows {
+		if ff.FolderID.Valid && ff.FolderID.Int32 == id {
+			s.t.follows[i].FolderID = sql.NullInt32{}
+		}
+	}
+	return nil
+}
+
+func (s *Store) SetFeedFollowFolder(ctx context.Context, arg database.SetFeedFollowFolderParams) (int64, error) {
+	defer s.lock()()
+
+	if arg.FolderID.Valid {
+		if _, err := s.folderByID(arg.FolderID.Int32); err != nil {
+			return 0, foreignKey("feed_follows_folder_id_fkey")
+		}
+	}
+	var n int64
+	now := s.now()
+	for i, ff := range s.t.follows {
+		if ff.UserID == arg.UserID && ff.FeedID == arg.FeedID {
+			s.t.follows[i].FolderID = arg.FolderID
+			s.t.follows[i].UpdatedAt = now
+			n++
+		}
+	}
+	return n, nil
+}
+
+// inFolder reports whether userID files feedID under folderID. An invalid
+// folderID matches every feed.
+func (s *Store) inFolder(userID uuid.UUID, feedID int32, folderID sql.NullInt32) bool {
+	if !folderID.Valid {
+		return true
+	}
+	return find(s.t.follows, func(ff database.FeedFollow) bool {
+		return ff.UserID == userID && ff.FeedID == feedID && ff.FolderID == folderID
+	}) >= 0
+}
+
+func (s *Store) RetrievePostsInFolder(ctx context.Context, arg database.RetrievePostsInFolderParams) ([]database.Post, error) {
+	defer s.lock()()
+
+	if !arg.FolderID.Valid {
+		return nil, nil
+	}
+	folder, err := s.folderByID(arg.FolderID.Int32)
+	if err != nil {
+		return nil, nil
+	}
+	var posts []database.Post
+	for _, p := range s.t.posts {
+		ff, _ := s.follow(folder.UserID, p.FeedID)
+		st := s.state(folder.UserID, p.ID)
+		if ff.Muted || st.IsHidden || (arg.UnreadOnly && st.IsRead) {
+			continue
+		}
+		if s.inFolder(folder.UserID, p.FeedID, arg.FolderID) {
+			posts = append(posts, p)
+		}
+	}
+	slices.SortFunc(posts, newestFirst(displayDate))
+	return limit(posts, arg.Limit), nil
+}
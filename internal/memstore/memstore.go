@@ -0,0 +1,208 @@
+// Package memstore is an in-memory implementation of database.Store. It
+// mirrors the constraints and ordering of the SQL queries closely enough to
+// run handlers in tests and to try gator without setting up a database.
+// Nothing is persisted: the data lives as long as the process.
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+type stateKey struct {
+	userID uuid.UUID
+	postID int32
+}
+
+// tables holds every row. Slices are kept in insertion order, which is also
+// ID order, so queries without an ORDER BY behave like a fresh Postgres table.
+type tables struct {
+	users       []database.User
+	credentials map[uuid.UUID]database.UserCredential
+	feeds       []database.Feed
+	follows     []database.FeedFollow
+	folders     []database.Folder
+	posts       []database.Post
+	categories  []database.PostCategory
+	postStates  map[stateKey]database.PostState
+	webhooks    []database.Webhook
+	deliveries  []database.WebhookDelivery
+	fetches     []database.FeedFetch
+	rules       []database.FilterRule
+	tags        []database.PostTag
+	alerts      []database.Alert
+	alertEvents []database.AlertEvent
+	digests     []database.DigestSubscription
+	digestPosts map[stateKey]database.DigestPost
+}
+
+func newTables() *tables {
+	return &tables{
+		credentials: map[uuid.UUID]database.UserCredential{},
+		postStates:  map[stateKey]database.PostState{},
+		digestPosts: map[stateKey]database.DigestPost{},
+	}
+}
+
+func (t *tables) clone() *tables {
+	return &tables{
+		users:       slices.Clone(t.users),
+		credentials: maps.Clone(t.credentials),
+		feeds:       slices.Clone(t.feeds),
+		follows:     slices.Clone(t.follows),
+		folders:     slices.Clone(t.folders),
+		posts:       slices.Clone(t.posts),
+		categories:  slices.Clone(t.categories),
+		postStates:  maps.Clone(t.postStates),
+		webhooks:    slices.Clone(t.webhooks),
+		deliveries:  slices.Clone(t.deliveries),
+		fetches:     slices.Clone(t.fetches),
+		rules:       slices.Clone(t.rules),
+		tags:        slices.Clone(t.tags),
+		alerts:      slices.Clone(t.alerts),
+		alertEvents: slices.Clone(t.alertEvents),
+		digests:     slices.Clone(t.digests),
+		digestPosts: maps.Clone(t.digestPosts),
+	}
+}
+
+// sequences hands out SERIAL IDs. Like Postgres sequences they are shared
+// by all transactions and never roll back.
+type sequences struct {
+	mu   sync.Mutex
+	next map[string]int32
+}
+
+func (s *sequences) nextval(table string) int32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next[table]++
+	return s.next[table]
+}
+
+// Store is a database.Store kept in memory. The zero value is not usable;
+// create one with New.
+type Store struct {
+	mu  *sync.Mutex
+	tx  bool
+	t   *tables
+	seq *sequences
+	now func() time.Time
+}
+
+var _ database.Store = (*Store)(nil)
+
+func New() *Store {
+	return &Store{
+		mu:  &sync.Mutex{},
+		t:   newTables(),
+		seq: &sequences{next: map[string]int32{}},
+		now: time.Now,
+	}
+}
+
+// SetClock replaces the clock used for NOW(), for tests that depend on
+// timestamps.
+func (s *Store) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// lock serializes access to the tables. Inside a transaction the
+// surrounding InTx already holds the lock.
+func (s *Store) lock() func() {
+	if s.tx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// InTx runs fn against a copy of the data and keeps the copy only if fn
+// succeeds. Other callers wait until the transaction finishes.
+func (s *Store) InTx(ctx context.Context, fn func(database.Querier) error) error {
+	defer s.lock()()
+
+	tx := &Store{mu: s.mu, tx: true, t: s.t.clone(), seq: s.seq, now: s.now}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	s.t = tx.t
+	return nil
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+// duplicateKey, foreignKey and checkViolation return the errors Postgres
+// would, so callers can tell constraints apart the same way.
+func duplicateKey(constraint string) error {
+	return &pq.Error{
+		Code:       "23505",
+		Constraint: constraint,
+		Message:    fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+	}
+}
+
+func foreignKey(constraint string) error {
+	return &pq.Error{
+		Code:       "23503",
+		Constraint: constraint,
+		Message:    fmt.Sprintf("insert or update violates foreign key constraint %q", constraint),
+	}
+}
+
+func checkViolation(constraint string) error {
+	return &pq.Error{
+		Code:       "23514",
+		Constraint: constraint,
+		Message:    fmt.Sprintf("new row violates check constraint %q", constraint),
+	}
+}
+
+// find returns the index of the first row matching match, or -1.
+func find[T any](rows []T, match func(T) bool) int {
+	return slices.IndexFunc(rows, match)
+}
+
+// one returns the first row matching match, or sql.ErrNoRows.
+func one[T any](rows []T, match func(T) bool) (T, error) {
+	if i := find(rows, match); i >= 0 {
+		return rows[i], nil
+	}
+	var zero T
+	return zero, sql.ErrNoRows
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+// containsFold reports whether substr is within s, ignoring case, like
+// ILIKE '%substr%'.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// likeUnescaper undoes the backslash escaping of a pattern matched with
+// LIKE ... ESCAPE '\'.
+var likeUnescaper = strings.NewReplacer(`\\`, `\`, `\%`, "%", `\_`, "_")
+
+// limit truncates rows to n, treating a negative n as no limit.
+func limit[T any](rows []T, n int32) []T {
+	if n >= 0 && int(n) < len(rows) {
+		return rows[:n]
+	}
+	return rows
+}
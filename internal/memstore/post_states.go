@@ -0,0 +1,294 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+// state returns the read state of a post for a user, defaulting to unread
+// and unstarred like the COALESCEs in the queries.
+func (s *Store) state(userID uuid.UUID, postID int32) database.PostState {
+	return s.t.postStates[stateKey{userID, postID}]
+}
+
+// upsertState creates or updates a user's state for a post.
+func (s *Store) upsertState(userID uuid.UUID, postID int32, fn func(*database.PostState)) error {
+	if _, err := s.userByID(userID); err != nil {
+		return foreignKey("post_states_user_id_fkey")
+	}
+	if _, err := s.postByID(postID); err != nil {
+		return foreignKey("post_states_post_id_fkey")
+	}
+
+	now := s.now()
+	key := stateKey{userID, postID}
+	st, ok := s.t.postStates[key]
+	if !ok {
+		st = database.PostState{UserID: userID, PostID: postID, CreatedAt: now}
+	}
+	fn(&st)
+	st.UpdatedAt = now
+	s.t.postStates[key] = st
+	return nil
+}
+
+func (s *Store) SetPostRead(ctx context.Context, arg database.SetPostReadParams) error {
+	defer s.lock()()
+	return s.upsertState(arg.UserID, arg.PostID, func(st *database.PostState) {
+		st.IsRead = arg.IsRead
+	})
+}
+
+func (s *Store) SetPostStarred(ctx context.Context, arg database.SetPostStarredParams) error {
+	defer s.lock()()
+	return s.upsertState(arg.UserID, arg.PostID, func(st *database.PostState) {
+		st.IsStarred = arg.IsStarred
+	})
+}
+
+func (s *Store) SetPostHidden(ctx context.Context, arg database.SetPostHiddenParams) error {
+	defer s.lock()()
+	return s.upsertState(arg.UserID, arg.PostID, func(st *database.PostState) {
+		st.IsHidden = arg.IsHidden
+	})
+}
+
+func (s *Store) isHidden(userID uuid.UUID, postID int32) bool {
+	return s.state(userID, postID).IsHidden
+}
+
+func (s *Store) MarkPostsReadForUser(ctx context.Context, arg database.MarkPostsReadForUserParams) error {
+	defer s.lock()()
+
+	for _, p := range s.followedPosts(arg.UserID) {
+		if arg.FeedID.Valid && p.FeedID != arg.FeedID.Int32 {
+			continue
+		}
+		if !s.inFolder(arg.UserID, p.FeedID, arg.FolderID) {
+			continue
+		}
+		if p.CreatedAt.After(arg.Before) {
+			continue
+		}
+		err := s.upsertState(arg.UserID, p.ID, func(st *database.PostState) {
+			st.IsRead = true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) CountUnreadPostsForUser(ctx context.Context, userID uuid.UUID) ([]database.CountUnreadPostsForUserRow, error) {
+	defer s.lock()()
+
+	var items []database.CountUnreadPostsForUserRow
+	index := map[int32]int{}
+	for _, p := range s.followedPosts(userID) {
+		if st := s.state(userID, p.ID); st.IsRead || st.IsHidden {
+			continue
+		}
+		i, ok := index[p.FeedID]
+		if !ok {
+			i = len(items)
+			index[p.FeedID] = i
+			ff, _ := s.follow(userID, p.FeedID)
+			items = append(items, database.CountUnreadPostsForUserRow{
+				FeedID:   p.FeedID,
+				FolderID: ff.FolderID,
+				Muted:    ff.Muted,
+			})
+		}
+		items[i].UnreadCount++
+		if p.CreatedAt.After(items[i].NewestCreatedAt) {
+			items[i].NewestCreatedAt = p.CreatedAt
+		}
+	}
+	slices.SortFunc(items, func(a, b database.CountUnreadPostsForUserRow) int {
+		return cmp.Compare(a.FeedID, b.FeedID)
+	})
+	return items, nil
+}
+
+func (s *Store) RetrieveStreamPostsForUser(ctx context.Context, arg database.RetrieveStreamPostsForUserParams) ([]database.RetrieveStreamPostsForUserRow, error) {
+	defer s.lock()()
+
+	var posts []database.Post
+	for _, p := range s.followedPosts(arg.UserID) {
+		st := s.state(arg.UserID, p.ID)
+		switch {
+		case arg.FeedID.Valid && p.FeedID != arg.FeedID.Int32:
+		case !s.inFolder(arg.UserID, p.FeedID, arg.FolderID):
+		case !arg.FeedID.Valid && s.isMuted(arg.UserID, p.FeedID):
+		case arg.UnreadOnly && st.IsRead:
+		case arg.StarredOnly && !st.IsStarred:
+		case arg.NewerThan.Valid && !p.CreatedAt.After(arg.NewerThan.Time):
+		case arg.OlderThan.Valid && !p.CreatedAt.Before(arg.OlderThan.Time):
+		case st.IsHidden:
+		default:
+			posts = append(posts, p)
+		}
+	}
+	slices.SortFunc(posts, newestFirst(createdAt))
+
+	if int(arg.Offset) >= len(posts) {
+		return nil, nil
+	}
+	posts = posts[max(arg.Offset, 0):]
+
+	var items []database.RetrieveStreamPostsForUserRow
+	for _, p := range limit(posts, arg.Limit) {
+		feed, _ := s.feedByID(p.FeedID)
+		st := s.state(arg.UserID, p.ID)
+		items = append(items, database.RetrieveStreamPostsForUserRow{
+			ID:          p.ID,
+			Title:       p.Title,
+			Url:         p.Url,
+			Description: p.Description,
+			PublishedAt: p.PublishedAt,
+			FeedID:      p.FeedID,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Author:      p.Author,
+			FeedName:    s.feedTitle(arg.UserID, feed),
+			FeedUrl:     feed.Url,
+			IsRead:      st.IsRead,
+			IsStarred:   st.IsStarred,
+		})
+	}
+	return items, nil
+}
+
+func (s *Store) RetrievePostsForUserByIDs(ctx context.Context, arg database.RetrievePostsForUserByIDsParams) ([]database.RetrievePostsForUserByIDsRow, error) {
+	defer s.lock()()
+
+	var posts []database.Post
+	for _, p := range s.followedPosts(arg.UserID) {
+		if slices.Contains(arg.Ids, p.ID) && !s.isHidden(arg.UserID, p.ID) {
+			posts = append(posts, p)
+		}
+	}
+	slices.SortFunc(posts, newestFirst(createdAt))
+
+	var items []database.RetrievePostsForUserByIDsRow
+	for _, p := range posts {
+		feed, _ := s.feedByID(p.FeedID)
+		st := s.state(arg.UserID, p.ID)
+		items = append(items, database.RetrievePostsForUserByIDsRow{
+			ID:          p.ID,
+			Title:       p.Title,
+			Url:         p.Url,
+			Description: p.Description,
+			PublishedAt: p.PublishedAt,
+			FeedID:      p.FeedID,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Author:      p.Author,
+			FeedName:    s.feedTitle(arg.UserID, feed),
+			FeedUrl:     feed.Url,
+			IsRead:      st.IsRead,
+			IsStarred:   st.IsStarred,
+		})
+	}
+	return items, nil
+}
+
+func (s *Store) RetrievePostsForUserSinceID(ctx context.Context, arg database.RetrievePostsForUserSinceIDParams) ([]database.RetrievePostsForUserSinceIDRow, error) {
+	defer s.lock()()
+
+	var items []database.RetrievePostsForUserSinceIDRow
+	for _, p := range s.followedPosts(arg.UserID) {
+		if p.ID <= arg.ID || s.isMuted(arg.UserID, p.FeedID) || s.isHidden(arg.UserID, p.ID) {
+			continue
+		}
+		st := s.state(arg.UserID, p.ID)
+		items = append(items, database.RetrievePostsForUserSinceIDRow{
+			ID:          p.ID,
+			Title:       p.Title,
+			Url:         p.Url,
+			Description: p.Description,
+			PublishedAt: p.PublishedAt,
+			FeedID:      p.FeedID,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Author:      p.Author,
+			IsRead:      st.IsRead,
+			IsStarred:   st.IsStarred,
+		})
+	}
+	return limit(items, arg.Limit), nil
+}
+
+func (s *Store) RetrievePostsForUserBeforeID(ctx context.Context, arg database.RetrievePostsForUserBeforeIDParams) ([]database.RetrievePostsForUserBeforeIDRow, error) {
+	defer s.lock()()
+
+	var items []database.RetrievePostsForUserBeforeIDRow
+	posts := s.followedPosts(arg.UserID)
+	slices.Reverse(posts)
+	for _, p := range posts {
+		if p.ID >= arg.ID || s.isMuted(arg.UserID, p.FeedID) || s.isHidden(arg.UserID, p.ID) {
+			continue
+		}
+		st := s.state(arg.UserID, p.ID)
+		items = append(items, database.RetrievePostsForUserBeforeIDRow{
+			ID:          p.ID,
+			Title:       p.Title,
+			Url:         p.Url,
+			Description: p.Description,
+			PublishedAt: p.PublishedAt,
+			FeedID:      p.FeedID,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Author:      p.Author,
+			IsRead:      st.IsRead,
+			IsStarred:   st.IsStarred,
+		})
+	}
+	return limit(items, arg.Limit), nil
+}
+
+func (s *Store) CountPostsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	defer s.lock()()
+
+	var n int64
+	for _, p := range s.followedPosts(userID) {
+		if !s.isMuted(userID, p.FeedID) && !s.isHidden(userID, p.ID) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *Store) RetrieveUnreadPostIDsForUser(ctx context.Context, userID uuid.UUID) ([]int32, error) {
+	defer s.lock()()
+
+	var items []int32
+	for _, p := range s.followedPosts(userID) {
+		if s.isMuted(userID, p.FeedID) {
+			continue
+		}
+		if st := s.state(userID, p.ID); !st.IsRead && !st.IsHidden {
+			items = append(items, p.ID)
+		}
+	}
+	return items, nil
+}
+
+func (s *Store) RetrieveStarredPostIDsForUser(ctx context.Context, userID uuid.UUID) ([]int32, error) {
+	defer s.lock()()
+
+	var items []int32
+	for key, st := range s.t.postStates {
+		if key.userID == userID && st.IsStarred {
+			items = append(items, key.postID)
+		}
+	}
+	slices.Sort(items)
+	return items, nil
+}
@@ -0,0 +1,246 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func (s *Store) CreatePost(ctx context.Context, arg database.CreatePostParams) (int32, error) {
+	defer s.lock()()
+
+	if find(s.t.posts, func(p database.Post) bool { return p.Url == arg.Url }) >= 0 {
+		return 0, duplicateKey("posts_url_key")
+	}
+	if _, err := s.feedByID(arg.FeedID); err != nil {
+		return 0, foreignKey("posts_feed_id_fkey")
+	}
+
+	now := s.now()
+	post := database.Post{
+		ID:          s.seq.nextval("posts"),
+		Title:       arg.Title,
+		Url:         arg.Url,
+		Description: arg.Description,
+		PublishedAt: arg.PublishedAt,
+		FeedID:      arg.FeedID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		DateFlag:    arg.DateFlag,
+		Excerpt:     arg.Excerpt,
+		Author:      arg.Author,
+	}
+	s.t.posts = append(s.t.posts, post)
+	return post.ID, nil
+}
+
+func (s *Store) postByID(id int32) (database.Post, error) {
+	return one(s.t.posts, func(p database.Post) bool { return p.ID == id })
+}
+
+// displayDate is COALESCE(published_at, created_at).
+func displayDate(p database.Post) time.Time {
+	if p.PublishedAt.Valid {
+		return p.PublishedAt.Time
+	}
+	return p.CreatedAt
+}
+
+// newestFirst orders posts by date descending, then ID descending.
+func newestFirst(date func(database.Post) time.Time) func(a, b database.Post) int {
+	return func(a, b database.Post) int {
+		if c := date(b).Compare(date(a)); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	}
+}
+
+func createdAt(p database.Post) time.Time {
+	return p.CreatedAt
+}
+
+// followedPosts returns the posts of every feed userID follows, in ID order.
+func (s *Store) followedPosts(userID uuid.UUID) []database.Post {
+	var posts []database.Post
+	for _, p := range s.t.posts {
+		if s.isFollowing(userID, p.FeedID) {
+			posts = append(posts, p)
+		}
+	}
+	return posts
+}
+
+func (s *Store) RetrievePostsForUser(ctx context.Context, arg database.RetrievePostsForUserParams) ([]database.Post, error) {
+	defer s.lock()()
+
+	var posts []database.Post
+	for _, p := range s.followedPosts(arg.UserID) {
+		st := s.state(arg.UserID, p.ID)
+		if s.isMuted(arg.UserID, p.FeedID) || st.IsHidden || (arg.UnreadOnly && st.IsRead) {
+			continue
+		}
+		posts = append(posts, p)
+	}
+	slices.SortFunc(posts, newestFirst(displayDate))
+	return limit(posts, arg.Limit), nil
+}
+
+func (s *Store) CreatePostCategory(ctx context.Context, arg database.CreatePostCategoryParams) error {
+	defer s.lock()()
+
+	if _, err := s.postByID(arg.PostID); err != nil {
+		return foreignKey("post_categories_post_id_fkey")
+	}
+	category := database.PostCategory{PostID: arg.PostID, Name: arg.Name}
+	if !slices.Contains(s.t.categories, category) {
+		s.t.categories = append(s.t.categories, category)
+	}
+	return nil
+}
+
+func (s *Store) RetrieveCategoriesForPosts(ctx context.Context, postIds []int32) ([]database.PostCategory, error) {
+	defer s.lock()()
+
+	var items []database.PostCategory
+	for _, c := range s.t.categories {
+		if slices.Contains(postIds, c.PostID) {
+			items = append(items, c)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.PostCategory) int {
+		if c := cmp.Compare(a.PostID, b.PostID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return items, nil
+}
+
+func (s *Store) hasCategory(postID int32, name string) bool {
+	return find(s.t.categories, func(c database.PostCategory) bool {
+		return c.PostID == postID && strings.EqualFold(c.Name, name)
+	}) >= 0
+}
+
+func (s *Store) RetrieveTimelineForUser(ctx context.Context, arg database.RetrieveTimelineForUserParams) ([]database.RetrieveTimelineForUserRow, error) {
+	defer s.lock()()
+
+	var posts []database.Post
+	for _, p := range s.followedPosts(arg.UserID) {
+		if s.isMuted(arg.UserID, p.FeedID) || s.isHidden(arg.UserID, p.ID) {
+			continue
+		}
+		if arg.Category.Valid && !s.hasCategory(p.ID, arg.Category.String) {
+			continue
+		}
+		keyword := likeUnescaper.Replace(arg.Keyword.String)
+		if arg.Keyword.Valid &&
+			!containsFold(p.Title, keyword) &&
+			!containsFold(p.Description, keyword) &&
+			!(p.FullContent.Valid && containsFold(p.FullContent.String, keyword)) {
+			continue
+		}
+		if arg.Tag.Valid && !s.hasTag(arg.UserID, p.ID, arg.Tag.String) {
+			continue
+		}
+		posts = append(posts, p)
+	}
+	slices.SortFunc(posts, newestFirst(displayDate))
+
+	var items []database.RetrieveTimelineForUserRow
+	for _, p := range limit(posts, arg.Limit) {
+		feed, _ := s.feedByID(p.FeedID)
+		items = append(items, database.RetrieveTimelineForUserRow{
+			ID:          p.ID,
+			Title:       p.Title,
+			Url:         p.Url,
+			Description: p.Description,
+			PublishedAt: p.PublishedAt,
+			FeedID:      p.FeedID,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			FeedName:    s.feedTitle(arg.UserID, feed),
+			FeedUrl:     feed.Url,
+		})
+	}
+	return items, nil
+}
+
+// DeletePostsForFeed also removes the read states, categories, tags, alert
+// events, digest entries and webhook deliveries of the deleted posts, which
+// reference them ON DELETE CASCADE.
+func (s *Store) DeletePostsForFeed(ctx context.Context, feedID int32) error {
+	defer s.lock()()
+	s.deletePosts(func(p database.Post) bool { return p.FeedID == feedID })
+	return nil
+}
+
+// deletePosts removes the matching posts along with their categories,
+// tags, states, alert events and webhook deliveries.
+func (s *Store) deletePosts(match func(database.Post) bool) {
+	deleted := map[int32]bool{}
+	s.t.posts = slices.DeleteFunc(s.t.posts, func(p database.Post) bool {
+		if match(p) {
+			deleted[p.ID] = true
+		}
+		return deleted[p.ID]
+	})
+	s.t.categories = slices.DeleteFunc(s.t.categories, func(c database.PostCategory) bool {
+		return deleted[c.PostID]
+	})
+	s.t.tags = slices.DeleteFunc(s.t.tags, func(t database.PostTag) bool {
+		return deleted[t.PostID]
+	})
+	s.t.alertEvents = slices.DeleteFunc(s.t.alertEvents, func(e database.AlertEvent) bool {
+		return deleted[e.PostID]
+	})
+	s.t.deliveries = slices.DeleteFunc(s.t.deliveries, func(d database.WebhookDelivery) bool {
+		return deleted[d.PostID]
+	})
+	for key := range s.t.postStates {
+		if deleted[key.postID] {
+			delete(s.t.postStates, key)
+		}
+	}
+	for key := range s.t.digestPosts {
+		if deleted[key.postID] {
+			delete(s.t.digestPosts, key)
+		}
+	}
+}
+
+func (s *Store) MovePostsToFeed(ctx context.Context, arg database.MovePostsToFeedParams) error {
+	defer s.lock()()
+
+	if _, err := s.feedByID(arg.ToFeedID); err != nil {
+		if find(s.t.posts, func(p database.Post) bool { return p.FeedID == arg.FromFeedID }) >= 0 {
+			return foreignKey("posts_feed_id_fkey")
+		}
+		return nil
+	}
+	now := s.now()
+	for i, p := range s.t.posts {
+		if p.FeedID == arg.FromFeedID {
+			s.t.posts[i].FeedID = arg.ToFeedID
+			s.t.posts[i].UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+func (s *Store) SetPostFullContent(ctx context.Context, arg database.SetPostFullContentParams) error {
+	defer s.lock()()
+
+	if i := find(s.t.posts, func(p database.Post) bool { return p.ID == arg.ID }); i >= 0 {
+		s.t.posts[i].FullContent = arg.FullContent
+		s.t.posts[i].UpdatedAt = s.now()
+	}
+	return nil
+}
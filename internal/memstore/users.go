@@ -0,0 +1,111 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func (s *Store) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	defer s.lock()()
+
+	for _, u := range s.t.users {
+		if u.ID == arg.ID {
+			return database.User{}, duplicateKey("users_pkey")
+		}
+		if u.Name == arg.Name {
+			return database.User{}, duplicateKey("users_name_key")
+		}
+	}
+	user := database.User{
+		ID:        arg.ID,
+		Name:      arg.Name,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+	}
+	s.t.users = append(s.t.users, user)
+	return user, nil
+}
+
+func (s *Store) GetUser(ctx context.Context, name string) (database.User, error) {
+	defer s.lock()()
+	return one(s.t.users, func(u database.User) bool { return u.Name == name })
+}
+
+func (s *Store) GetUserById(ctx context.Context, id uuid.UUID) (database.User, error) {
+	defer s.lock()()
+	return s.userByID(id)
+}
+
+func (s *Store) userByID(id uuid.UUID) (database.User, error) {
+	return one(s.t.users, func(u database.User) bool { return u.ID == id })
+}
+
+func (s *Store) GetUsers(ctx context.Context) ([]database.User, error) {
+	defer s.lock()()
+
+	var items []database.User
+	items = append(items, s.t.users...)
+	return items, nil
+}
+
+// ResetAllUser empties every table, as TRUNCATE users CASCADE reaches all
+// of them through foreign keys.
+func (s *Store) ResetAllUser(ctx context.Context) error {
+	defer s.lock()()
+	s.t = newTables()
+	return nil
+}
+
+func (s *Store) SetUserPassword(ctx context.Context, arg database.SetUserPasswordParams) error {
+	defer s.lock()()
+
+	if _, err := s.userByID(arg.UserID); err != nil {
+		return foreignKey("user_credentials_user_id_fkey")
+	}
+	if arg.FeverApiKey.Valid {
+		for _, c := range s.t.credentials {
+			if c.UserID != arg.UserID && c.FeverApiKey == arg.FeverApiKey {
+				return duplicateKey("user_credentials_fever_api_key_key")
+			}
+		}
+	}
+
+	now := s.now()
+	cred, ok := s.t.credentials[arg.UserID]
+	if !ok {
+		cred = database.UserCredential{UserID: arg.UserID, CreatedAt: now}
+	}
+	cred.PasswordHash = arg.PasswordHash
+	cred.FeverApiKey = arg.FeverApiKey
+	cred.UpdatedAt = now
+	s.t.credentials[arg.UserID] = cred
+	return nil
+}
+
+func (s *Store) GetUserCredentials(ctx context.Context, userID uuid.UUID) (database.UserCredential, error) {
+	defer s.lock()()
+
+	cred, ok := s.t.credentials[userID]
+	if !ok {
+		return database.UserCredential{}, sql.ErrNoRows
+	}
+	return cred, nil
+}
+
+func (s *Store) GetUserByFeverAPIKey(ctx context.Context, feverApiKey sql.NullString) (database.User, error) {
+	defer s.lock()()
+
+	if !feverApiKey.Valid {
+		return database.User{}, sql.ErrNoRows
+	}
+	for _, c := range s.t.credentials {
+		if c.FeverApiKey == feverApiKey {
+			return s.userByID(c.UserID)
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
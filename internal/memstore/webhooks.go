@@ -0,0 +1,265 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+func (s *Store) CreateWebhook(ctx context.Context, arg database.CreateWebhookParams) (database.Webhook, error) {
+	defer s.lock()()
+
+	if _, err := s.userByID(arg.UserID); err != nil {
+		return database.Webhook{}, foreignKey("webhooks_user_id_fkey")
+	}
+	if arg.FeedID.Valid {
+		if _, err := s.feedByID(arg.FeedID.Int32); err != nil {
+			return database.Webhook{}, foreignKey("webhooks_feed_id_fkey")
+		}
+	}
+
+	now := s.now()
+	hook := database.Webhook{
+		ID:        s.seq.nextval("webhooks"),
+		UserID:    arg.UserID,
+		Url:       arg.Url,
+		Secret:    arg.Secret,
+		FeedID:    arg.FeedID,
+		Keyword:   arg.Keyword,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.t.webhooks = append(s.t.webhooks, hook)
+	return hook, nil
+}
+
+func (s *Store) webhookByID(id int32) (database.Webhook, error) {
+	return one(s.t.webhooks, func(w database.Webhook) bool { return w.ID == id })
+}
+
+// deleteWebhooks removes the matching webhooks and their deliveries,
+// returning how many webhooks were removed.
+func (s *Store) deleteWebhooks(match func(database.Webhook) bool) int64 {
+	deleted := map[int32]bool{}
+	s.t.webhooks = slices.DeleteFunc(s.t.webhooks, func(w database.Webhook) bool {
+		if match(w) {
+			deleted[w.ID] = true
+		}
+		return deleted[w.ID]
+	})
+	s.t.deliveries = slices.DeleteFunc(s.t.deliveries, func(d database.WebhookDelivery) bool {
+		return deleted[d.WebhookID]
+	})
+	return int64(len(deleted))
+}
+
+func (s *Store) RetrieveWebhooksForUser(ctx context.Context, userID uuid.UUID) ([]database.Webhook, error) {
+	defer s.lock()()
+
+	var items []database.Webhook
+	for _, w := range s.t.webhooks {
+		if w.UserID == userID {
+			items = append(items, w)
+		}
+	}
+	return items, nil
+}
+
+func (s *Store) DeleteWebhook(ctx context.Context, arg database.DeleteWebhookParams) (int64, error) {
+	defer s.lock()()
+	return s.deleteWebhooks(func(w database.Webhook) bool {
+		return w.ID == arg.ID && w.UserID == arg.UserID
+	}), nil
+}
+
+func (s *Store) RetrieveWebhooksForFeed(ctx context.Context, feedID sql.NullInt32) ([]database.Webhook, error) {
+	defer s.lock()()
+
+	if !feedID.Valid {
+		return nil, nil
+	}
+	var items []database.Webhook
+	for _, w := range s.t.webhooks {
+		ff, following := s.follow(w.UserID, feedID.Int32)
+		if w.FeedID == feedID || (!w.FeedID.Valid && following && ff.Notify == "all") {
+			items = append(items, w)
+		}
+	}
+	return items, nil
+}
+
+func (s *Store) MoveWebhooksToFeed(ctx context.Context, arg database.MoveWebhooksToFeedParams) error {
+	defer s.lock()()
+
+	if !arg.FromFeedID.Valid {
+		return nil
+	}
+	now := s.now()
+	for i, w := range s.t.webhooks {
+		if w.FeedID == arg.FromFeedID {
+			s.t.webhooks[i].FeedID = arg.ToFeedID
+			s.t.webhooks[i].UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+func (s *Store) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	defer s.lock()()
+
+	if _, err := s.webhookByID(arg.WebhookID); err != nil {
+		return database.WebhookDelivery{}, foreignKey("webhook_deliveries_webhook_id_fkey")
+	}
+	if _, err := s.postByID(arg.PostID); err != nil {
+		return database.WebhookDelivery{}, foreignKey("webhook_deliveries_post_id_fkey")
+	}
+
+	now := s.now()
+	delivery := database.WebhookDelivery{
+		ID:            s.seq.nextval("webhook_deliveries"),
+		WebhookID:     arg.WebhookID,
+		PostID:        arg.PostID,
+		Payload:       arg.Payload,
+		Status:        "pending",
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	s.t.deliveries = append(s.t.deliveries, delivery)
+	return delivery, nil
+}
+
+func (s *Store) RetrieveDueWebhookDeliveries(ctx context.Context, limitN int32) ([]database.RetrieveDueWebhookDeliveriesRow, error) {
+	defer s.lock()()
+
+	now := s.now()
+	var due []database.WebhookDelivery
+	for _, d := range s.t.deliveries {
+		if d.Status == "pending" && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	slices.SortFunc(due, func(a, b database.WebhookDelivery) int {
+		if c := a.NextAttemptAt.Compare(b.NextAttemptAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	var items []database.RetrieveDueWebhookDeliveriesRow
+	for _, d := range limit(due, limitN) {
+		hook, _ := s.webhookByID(d.WebhookID)
+		items = append(items, database.RetrieveDueWebhookDeliveriesRow{
+			ID:        d.ID,
+			WebhookID: d.WebhookID,
+			PostID:    d.PostID,
+			Payload:   d.Payload,
+			Attempts:  d.Attempts,
+			Url:       hook.Url,
+			Secret:    hook.Secret,
+		})
+	}
+	return items, nil
+}
+
+// updateDeliveries applies fn to the matching deliveries and returns how
+// many there were.
+func (s *Store) updateDeliveries(match func(database.WebhookDelivery) bool, fn func(*database.WebhookDelivery)) int64 {
+	var n int64
+	now := s.now()
+	for i, d := range s.t.deliveries {
+		if match(d) {
+			fn(&s.t.deliveries[i])
+			s.t.deliveries[i].UpdatedAt = now
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Store) RecordWebhookDeliveryAttempt(ctx context.Context, arg database.RecordWebhookDeliveryAttemptParams) error {
+	defer s.lock()()
+	s.updateDeliveries(func(d database.WebhookDelivery) bool {
+		return d.ID == arg.ID
+	}, func(d *database.WebhookDelivery) {
+		d.Status = arg.Status
+		d.Attempts++
+		d.NextAttemptAt = arg.NextAttemptAt
+		d.LastStatusCode = arg.LastStatusCode
+		d.LastError = arg.LastError
+	})
+	return nil
+}
+
+func (s *Store) RetrieveWebhookDeliveriesForUser(ctx context.Context, arg database.RetrieveWebhookDeliveriesForUserParams) ([]database.RetrieveWebhookDeliveriesForUserRow, error) {
+	defer s.lock()()
+
+	var items []database.RetrieveWebhookDeliveriesForUserRow
+	for _, d := range s.t.deliveries {
+		hook, err := s.webhookByID(d.WebhookID)
+		if err != nil || hook.UserID != arg.UserID {
+			continue
+		}
+		if arg.Status.Valid && d.Status != arg.Status.String {
+			continue
+		}
+		post, err := s.postByID(d.PostID)
+		if err != nil {
+			continue
+		}
+		items = append(items, database.RetrieveWebhookDeliveriesForUserRow{
+			ID:             d.ID,
+			WebhookID:      d.WebhookID,
+			PostID:         d.PostID,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			NextAttemptAt:  d.NextAttemptAt,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			CreatedAt:      d.CreatedAt,
+			UpdatedAt:      d.UpdatedAt,
+			WebhookUrl:     hook.Url,
+			PostTitle:      post.Title,
+		})
+	}
+	slices.SortFunc(items, func(a, b database.RetrieveWebhookDeliveriesForUserRow) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	return limit(items, arg.Limit), nil
+}
+
+// ownedBy reports whether a delivery belongs to one of userID's webhooks.
+func (s *Store) ownedBy(d database.WebhookDelivery, userID uuid.UUID) bool {
+	hook, err := s.webhookByID(d.WebhookID)
+	return err == nil && hook.UserID == userID
+}
+
+// replay queues a delivery again from scratch.
+func (s *Store) replay(d *database.WebhookDelivery) {
+	d.Status = "pending"
+	d.Attempts = 0
+	d.NextAttemptAt = s.now()
+	d.LastError = sql.NullString{}
+}
+
+func (s *Store) ReplayWebhookDelivery(ctx context.Context, arg database.ReplayWebhookDeliveryParams) (int64, error) {
+	defer s.lock()()
+	return s.updateDeliveries(func(d database.WebhookDelivery) bool {
+		return d.ID == arg.ID && s.ownedBy(d, arg.UserID)
+	}, s.replay), nil
+}
+
+func (s *Store) ReplayDeadWebhookDeliveriesForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	defer s.lock()()
+	return s.updateDeliveries(func(d database.WebhookDelivery) bool {
+		return d.Status == "dead" && s.ownedBy(d, userID)
+	}, s.replay), nil
+}
@@ -0,0 +1,88 @@
+// Package metrics defines the Prometheus collectors exported by the
+// aggregator on /metrics.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gator"
+
+var (
+	FetchAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "feed_fetch_attempts_total",
+		Help:      "Feed fetch attempts, by result (success or error).",
+	}, []string{"result"})
+
+	FetchResponses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "feed_fetch_responses_total",
+		Help:      "HTTP responses received while fetching feeds, by status code.",
+	}, []string{"code"})
+
+	FetchDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "feed_fetch_duration_seconds",
+		Help:      "Time taken to download a feed.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	BytesDownloaded = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "feed_bytes_downloaded_total",
+		Help:      "Bytes of feed content downloaded.",
+	})
+
+	ParseFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "feed_parse_failures_total",
+		Help:      "Feeds that could not be parsed.",
+	})
+
+	PostsSaved = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "posts_saved_total",
+		Help:      "New posts stored.",
+	})
+
+	DuplicatePosts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "posts_duplicate_total",
+		Help:      "Posts skipped because their URL was already stored.",
+	})
+
+	// SchedulerLag is not labelled by feed: agg never learns that a feed
+	// was deleted or merged, so per-feed series would outlive their feeds.
+	SchedulerLag = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduler_lag_seconds",
+		Help:      "Time since a feed was last fetched when it was picked for fetching again.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 16),
+	})
+)
+
+// ObserveFetch records a finished HTTP fetch.
+func ObserveFetch(statusCode int, duration time.Duration, bytes int) {
+	if statusCode != 0 {
+		FetchResponses.WithLabelValues(strconv.Itoa(statusCode)).Inc()
+	}
+	FetchDuration.Observe(duration.Seconds())
+	BytesDownloaded.Add(float64(bytes))
+}
+
+// ObserveSchedulerLag records how long a feed had waited when it was
+// scheduled.
+func ObserveSchedulerLag(lag time.Duration) {
+	SchedulerLag.Observe(lag.Seconds())
+}
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
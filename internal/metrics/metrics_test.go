@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	return w.Body.String()
+}
+
+func TestObserve(t *testing.T) {
+	ObserveFetch(http.StatusTeapot, 250*time.Millisecond, 1024)
+	ObserveFetch(0, time.Second, 0)
+	ObserveSchedulerLag(3 * time.Second)
+	ObserveSchedulerLag(time.Hour)
+
+	out := scrape(t)
+	for _, want := range []string{
+		`gator_feed_fetch_responses_total{code="418"} 1`,
+		`gator_feed_fetch_duration_seconds_count 2`,
+		`gator_feed_fetch_duration_seconds_sum 1.25`,
+		`gator_feed_bytes_downloaded_total 1024`,
+		`gator_scheduler_lag_seconds_bucket{le="2"} 0`,
+		`gator_scheduler_lag_seconds_bucket{le="4"} 1`,
+		`gator_scheduler_lag_seconds_bucket{le="+Inf"} 2`,
+		`gator_scheduler_lag_seconds_count 2`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("metrics are missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{`code="0"`, `feed_id=`} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("metrics include %s", unwanted)
+		}
+	}
+}
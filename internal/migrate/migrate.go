@@ -0,0 +1,304 @@
+// Package migrate applies the goose-style SQL migrations embedded in the
+// binary. Applied versions are tracked in goose's own goose_db_version
+// table, so databases migrated with the goose CLI carry on where they left
+// off.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration is one numbered schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes a migration and whether it has been applied. AppliedAt
+// is zero for pending migrations and for rows goose recorded without a
+// timestamp.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Dialect holds the statements that maintain the version table.
+type Dialect struct {
+	createTable string
+	selectRows  string
+	insertRow   string
+	deleteRow   string
+}
+
+var Postgres = Dialect{
+	createTable: `CREATE TABLE IF NOT EXISTS goose_db_version (
+		id SERIAL PRIMARY KEY,
+		version_id BIGINT NOT NULL,
+		is_applied BOOLEAN NOT NULL,
+		tstamp TIMESTAMP NULL DEFAULT NOW()
+	)`,
+	selectRows: `SELECT version_id, is_applied, tstamp FROM goose_db_version ORDER BY id`,
+	insertRow:  `INSERT INTO goose_db_version (version_id, is_applied, tstamp) VALUES ($1, $2, $3)`,
+	deleteRow:  `DELETE FROM goose_db_version WHERE version_id = $1`,
+}
+
+var SQLite = Dialect{
+	createTable: `CREATE TABLE IF NOT EXISTS goose_db_version (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		version_id INTEGER NOT NULL,
+		is_applied INTEGER NOT NULL,
+		tstamp TIMESTAMP NULL
+	)`,
+	selectRows: `SELECT version_id, is_applied, tstamp FROM goose_db_version ORDER BY id`,
+	insertRow:  `INSERT INTO goose_db_version (version_id, is_applied, tstamp) VALUES (?, ?, ?)`,
+	deleteRow:  `DELETE FROM goose_db_version WHERE version_id = ?`,
+}
+
+// Migrator applies a set of migrations to a database.
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+// New loads the migrations in the root of fsys. File names start with the
+// version number, e.g. 001_users.sql.
+func New(db *sql.DB, fsys fs.FS, dialect Dialect) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_.*\.sql$`)
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	seen := map[int64]string{}
+	for _, e := range entries {
+		m := fileName.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", e.Name(), err)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, e.Name(), version)
+		}
+		seen[version] = e.Name()
+
+		data, err := fs.ReadFile(fsys, path.Join(".", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		up, down, err := parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    e.Name(),
+			Up:      up,
+			Down:    down,
+		})
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return migrations, nil
+}
+
+// parse splits a goose migration into its Up and Down sections.
+func parse(data string) (up, down string, err error) {
+	var section *strings.Builder
+	var upBuf, downBuf strings.Builder
+	for _, line := range strings.SplitAfter(data, "\n") {
+		annotation, ok := strings.CutPrefix(strings.TrimSpace(line), "-- +goose ")
+		if !ok {
+			if section != nil {
+				section.WriteString(line)
+			}
+			continue
+		}
+		switch strings.TrimSpace(annotation) {
+		case "Up":
+			section = &upBuf
+		case "Down":
+			section = &downBuf
+		case "StatementBegin", "StatementEnd":
+			// Sections run as a single Exec, so statements need no
+			// extra delimiting.
+		default:
+			return "", "", fmt.Errorf("unsupported annotation %q", annotation)
+		}
+	}
+	if strings.TrimSpace(upBuf.String()) == "" {
+		return "", "", fmt.Errorf("missing -- +goose Up section")
+	}
+	return upBuf.String(), downBuf.String(), nil
+}
+
+// applied returns when each applied version was applied. Later rows
+// override earlier ones, as goose does.
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	if _, err := m.db.ExecContext(ctx, m.dialect.createTable); err != nil {
+		return nil, fmt.Errorf("failed to create version table: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, m.dialect.selectRows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version table: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var isApplied bool
+		var at sql.NullTime
+		if err := rows.Scan(&version, &isApplied, &at); err != nil {
+			return nil, err
+		}
+		if isApplied && version > 0 {
+			applied[version] = at.Time
+		} else {
+			delete(applied, version)
+		}
+	}
+	return applied, rows.Err()
+}
+
+// Status lists every known migration with when it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		at, ok := applied[mig.Version]
+		statuses[i] = Status{Migration: mig, Applied: ok, AppliedAt: at}
+	}
+	return statuses, nil
+}
+
+// Version returns the highest applied version, which may be newer than any
+// migration this binary knows about, and the latest known version.
+func (m *Migrator) Version(ctx context.Context) (current, latest int64, err error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	for v := range applied {
+		current = max(current, v)
+	}
+	if len(m.migrations) > 0 {
+		latest = m.migrations[len(m.migrations)-1].Version
+	}
+	return current, latest, nil
+}
+
+// Pending returns the migrations that have not been applied, in order.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration, each in its own transaction, and
+// returns the ones it applied. It stops at the first failure.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, mig := range pending {
+		err := m.run(ctx, mig.Up, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, m.dialect.insertRow, mig.Version, true, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("failed to apply %s: %w", mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Down rolls back the most recently applied migration and returns it.
+func (m *Migrator) Down(ctx context.Context) (Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if strings.TrimSpace(mig.Down) == "" {
+			return Migration{}, fmt.Errorf("%s has no -- +goose Down section", mig.Name)
+		}
+		err := m.run(ctx, mig.Down, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, m.dialect.deleteRow, mig.Version)
+			return err
+		})
+		if err != nil {
+			return Migration{}, fmt.Errorf("failed to roll back %s: %w", mig.Name, err)
+		}
+		return mig, nil
+	}
+	return Migration{}, fmt.Errorf("no migrations to roll back")
+}
+
+// run executes a migration section and records it in one transaction.
+func (m *Migrator) run(ctx context.Context, statements string, record func(*sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return err
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,232 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name, data, up, down string
+		wantErr              bool
+	}{
+		{
+			name: "up and down",
+			data: "-- +goose Up\nCREATE TABLE a (id INTEGER);\n\n-- +goose Down\nDROP TABLE a;\n",
+			up:   "CREATE TABLE a (id INTEGER);\n\n",
+			down: "DROP TABLE a;\n",
+		},
+		{
+			name: "up only",
+			data: "-- +goose Up\nCREATE TABLE a (id INTEGER);\n",
+			up:   "CREATE TABLE a (id INTEGER);\n",
+		},
+		{
+			name: "text before the first section is ignored",
+			data: "-- a comment\n-- +goose Up\nSELECT 1;\n",
+			up:   "SELECT 1;\n",
+		},
+		{
+			name: "statement blocks",
+			data: "-- +goose Up\n-- +goose StatementBegin\nSELECT 1;\n-- +goose StatementEnd\n-- +goose Down\nSELECT 2;\n",
+			up:   "SELECT 1;\n",
+			down: "SELECT 2;\n",
+		},
+		{
+			name:    "missing up",
+			data:    "-- +goose Down\nDROP TABLE a;\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty up",
+			data:    "-- +goose Up\n\n-- +goose Down\nDROP TABLE a;\n",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported annotation",
+			data:    "-- +goose Up\n-- +goose NO TRANSACTION\nSELECT 1;\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		up, down, err := parse(tt.data)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if up != tt.up || down != tt.down {
+			t.Errorf("%s: got up %q, down %q, want %q, %q", tt.name, up, down, tt.up, tt.down)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_b.sql":   {Data: []byte("-- +goose Up\nSELECT 10;\n")},
+		"002_a.sql":   {Data: []byte("-- +goose Up\nSELECT 2;\n")},
+		"README.md":   {Data: []byte("not a migration")},
+		"notes.sql":   {Data: []byte("-- +goose Up\nSELECT 0;\n")},
+		"003_c.sql/x": {Data: []byte("a directory, not a migration")},
+	}
+	migrations, err := load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range migrations {
+		names = append(names, m.Name)
+	}
+	if got := strings.Join(names, " "); got != "002_a.sql 010_b.sql" {
+		t.Errorf("loaded %s", got)
+	}
+
+	fsys["02_dup.sql"] = &fstest.MapFile{Data: []byte("-- +goose Up\nSELECT 2;\n")}
+	if _, err := load(fsys); err == nil {
+		t.Error("expected an error for two migrations with the same version")
+	}
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n > 0
+}
+
+var testMigrations = fstest.MapFS{
+	"001_users.sql": {Data: []byte("-- +goose Up\nCREATE TABLE users (id INTEGER PRIMARY KEY);\n\n-- +goose Down\nDROP TABLE users;\n")},
+	"002_posts.sql": {Data: []byte("-- +goose Up\nCREATE TABLE posts (id INTEGER PRIMARY KEY);\nCREATE TABLE tags (id INTEGER PRIMARY KEY);\n\n-- +goose Down\nDROP TABLE tags;\nDROP TABLE posts;\n")},
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m, err := New(db, testMigrations, SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 2 {
+		t.Fatalf("Up applied %d migrations, want 2", len(done))
+	}
+	for _, table := range []string{"users", "posts", "tags"} {
+		if !tableExists(t, db, table) {
+			t.Errorf("table %s missing after Up", table)
+		}
+	}
+	if current, latest, err := m.Version(ctx); err != nil || current != 2 || latest != 2 {
+		t.Errorf("Version() = %d, %d, %v, want 2, 2", current, latest, err)
+	}
+	if done, err := m.Up(ctx); err != nil || len(done) != 0 {
+		t.Errorf("second Up applied %d migrations, err %v", len(done), err)
+	}
+
+	mig, err := m.Down(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mig.Version != 2 {
+		t.Errorf("Down rolled back %d, want 2", mig.Version)
+	}
+	if tableExists(t, db, "posts") || tableExists(t, db, "tags") || !tableExists(t, db, "users") {
+		t.Error("Down did not roll back only the latest migration")
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !statuses[0].Applied || statuses[0].AppliedAt.IsZero() || statuses[1].Applied {
+		t.Errorf("unexpected status after Down: %+v", statuses)
+	}
+
+	if _, err := m.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Down(ctx); err == nil {
+		t.Error("expected an error rolling back with nothing applied")
+	}
+	if current, _, _ := m.Version(ctx); current != 0 {
+		t.Errorf("version %d after rolling everything back", current)
+	}
+
+	if done, err := m.Up(ctx); err != nil || len(done) != 2 {
+		t.Errorf("Up after Down applied %d migrations, err %v", len(done), err)
+	}
+}
+
+func TestUpStopsAtFailure(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	fsys := fstest.MapFS{
+		"001_users.sql": testMigrations["001_users.sql"],
+		"002_bad.sql":   {Data: []byte("-- +goose Up\nCREATE TABLE half (id INTEGER);\nNOT SQL;\n")},
+		"003_later.sql": {Data: []byte("-- +goose Up\nCREATE TABLE later (id INTEGER);\n")},
+	}
+	m, err := New(db, fsys, SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := m.Up(ctx)
+	if err == nil || !strings.Contains(err.Error(), "002_bad.sql") {
+		t.Errorf("Up error = %v, want one naming 002_bad.sql", err)
+	}
+	if len(done) != 1 {
+		t.Errorf("Up applied %d migrations before failing, want 1", len(done))
+	}
+	if tableExists(t, db, "half") || tableExists(t, db, "later") {
+		t.Error("failed migration was not rolled back")
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].Version != 2 {
+		t.Errorf("pending = %+v, want 002 and 003", pending)
+	}
+}
+
+func TestDownWithoutDownSection(t *testing.T) {
+	ctx := context.Background()
+	m, err := New(openTestDB(t), fstest.MapFS{
+		"001_a.sql": {Data: []byte("-- +goose Up\nCREATE TABLE a (id INTEGER);\n")},
+	}, SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Down(ctx); err == nil {
+		t.Error("expected an error rolling back a migration without a Down section")
+	}
+}
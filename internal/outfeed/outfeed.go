@@ -0,0 +1,145 @@
+// Package outfeed renders a user's merged timeline of followed feeds as
+// RSS 2.0 or Atom so gator can act as an aggregator of record.
+package outfeed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/richtext"
+)
+
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// likeEscaper escapes a keyword for the timeline query's LIKE patterns, so
+// that "%" and "_" match themselves.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+type Options struct {
+	Category string
+	Keyword  string
+	Tag      string
+	Limit    int32
+	// Link is the canonical URL of the generated feed itself.
+	Link string
+}
+
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Author      string
+	ID          string
+	Updated     time.Time
+	Items       []Item
+}
+
+type Item struct {
+	Title       string
+	Link        string
+	Description string
+	Published   time.Time
+	Updated     time.Time
+	Categories  []string
+	SourceTitle string
+	SourceURL   string
+}
+
+// Build loads the timeline for user from the feeds they follow.
+func Build(ctx context.Context, db database.Querier, user database.User, opts Options) (Feed, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	posts, err := db.RetrieveTimelineForUser(ctx, database.RetrieveTimelineForUserParams{
+		UserID:   user.ID,
+		Category: sql.NullString{String: opts.Category, Valid: opts.Category != ""},
+		Keyword:  sql.NullString{String: likeEscaper.Replace(opts.Keyword), Valid: opts.Keyword != ""},
+		Tag:      sql.NullString{String: opts.Tag, Valid: opts.Tag != ""},
+		Limit:    limit,
+	})
+	if err != nil {
+		return Feed{}, fmt.Errorf("failed to retrieve timeline: %w", err)
+	}
+
+	postIDs := make([]int32, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+
+	categories := map[int32][]string{}
+	if len(postIDs) > 0 {
+		rows, err := db.RetrieveCategoriesForPosts(ctx, postIDs)
+		if err != nil {
+			return Feed{}, fmt.Errorf("failed to retrieve categories: %w", err)
+		}
+		for _, row := range rows {
+			categories[row.PostID] = append(categories[row.PostID], row.Name)
+		}
+	}
+
+	feed := Feed{
+		Title:       fmt.Sprintf("%s's gator timeline", user.Name),
+		Link:        opts.Link,
+		Description: fmt.Sprintf("Posts from feeds followed by %s", user.Name),
+		Author:      user.Name,
+		ID:          "urn:uuid:" + user.ID.String(),
+		Updated:     time.Now(),
+		Items:       make([]Item, 0, len(posts)),
+	}
+
+	for i, post := range posts {
+		published := post.CreatedAt
+		if post.PublishedAt.Valid {
+			published = post.PublishedAt.Time
+		}
+		if i == 0 {
+			feed.Updated = post.UpdatedAt
+		}
+
+		feed.Items = append(feed.Items, Item{
+			Title:       post.Title,
+			Link:        post.Url,
+			Description: richtext.Sanitize(post.Description),
+			Published:   published,
+			Updated:     post.UpdatedAt,
+			Categories:  categories[post.ID],
+			SourceTitle: post.FeedName,
+			SourceURL:   post.FeedUrl,
+		})
+	}
+
+	return feed, nil
+}
+
+// Write renders feed in the given format.
+func Write(w io.Writer, format string, feed Feed) error {
+	switch format {
+	case FormatRSS:
+		return WriteRSS(w, feed)
+	case FormatAtom:
+		return WriteAtom(w, feed)
+	}
+	return fmt.Errorf("unsupported format: %s", format)
+}
+
+// ContentType returns the MIME type for format.
+func ContentType(format string) string {
+	if format == FormatAtom {
+		return "application/atom+xml; charset=utf-8"
+	}
+	return "application/rss+xml; charset=utf-8"
+}
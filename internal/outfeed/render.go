@@ -0,0 +1,184 @@
+package outfeed
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      *atomLink `xml:"atom:link,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Generator     string    `xml:"generator"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string     `xml:"title"`
+	Link        string     `xml:"link"`
+	Description string     `xml:"description"`
+	GUID        rssGUID    `xml:"guid"`
+	PubDate     string     `xml:"pubDate"`
+	Categories  []string   `xml:"category"`
+	Source      *rssSource `xml:"source,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssSource struct {
+	URL   string `xml:"url,attr"`
+	Title string `xml:",chardata"`
+}
+
+type atomDocument struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomSource struct {
+	Title string    `xml:"title"`
+	ID    string    `xml:"id"`
+	Link  *atomLink `xml:"link,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    atomText       `xml:"summary"`
+	Categories []atomCategory `xml:"category"`
+	Source     *atomSource    `xml:"source,omitempty"`
+}
+
+// WriteRSS renders feed as an RSS 2.0 document.
+func WriteRSS(w io.Writer, feed Feed) error {
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.Link,
+			Description:   feed.Description,
+			LastBuildDate: feed.Updated.UTC().Format(time.RFC1123Z),
+			Generator:     "gator",
+			Items:         make([]rssItem, 0, len(feed.Items)),
+		},
+	}
+
+	if feed.Link != "" {
+		doc.Channel.AtomLink = &atomLink{Href: feed.Link, Rel: "self", Type: "application/rss+xml"}
+	}
+
+	for _, item := range feed.Items {
+		ri := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			GUID:        rssGUID{IsPermaLink: true, Value: item.Link},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Categories:  item.Categories,
+		}
+		if item.SourceURL != "" {
+			ri.Source = &rssSource{URL: item.SourceURL, Title: item.SourceTitle}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, ri)
+	}
+
+	return encode(w, doc)
+}
+
+// WriteAtom renders feed as an Atom 1.0 document.
+func WriteAtom(w io.Writer, feed Feed) error {
+	doc := atomDocument{
+		Title:   feed.Title,
+		ID:      feed.ID,
+		Updated: feed.Updated.UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: feed.Author},
+		Entries: make([]atomEntry, 0, len(feed.Items)),
+	}
+
+	if feed.Link != "" {
+		doc.Links = append(doc.Links, atomLink{Href: feed.Link, Rel: "self", Type: "application/atom+xml"})
+	}
+
+	for _, item := range feed.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.Link,
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate"}},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Summary:   atomText{Type: "html", Value: item.Description},
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		if item.SourceURL != "" {
+			entry.Source = &atomSource{
+				Title: item.SourceTitle,
+				ID:    item.SourceURL,
+				Link:  &atomLink{Href: item.SourceURL, Rel: "self"},
+			}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return encode(w, doc)
+}
+
+func encode(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,103 @@
+package outfeed
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sanntintdev/gator/internal/auth"
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+type Server struct {
+	db     database.Querier
+	logger *slog.Logger
+}
+
+func NewServer(db database.Querier, logger *slog.Logger) *Server {
+	return &Server{db: db, logger: logger.With("api", "outfeed")}
+}
+
+// Register mounts /outfeed/{user}/{rss|atom} on mux. The token query
+// parameter must hold the user's auth.FeedToken; other supported
+// parameters are category, q (keyword), tag and limit.
+func (s *Server) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /outfeed/{user}/{format}", s.handleFeed)
+}
+
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	format := r.PathValue("format")
+	if format != FormatRSS && format != FormatAtom {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Unknown users, users without a password and wrong tokens all get the
+	// same 404, so the endpoint can't be used to probe for usernames.
+	ctx := r.Context()
+	user, err := s.db.GetUser(ctx, r.PathValue("user"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	creds, err := s.db.GetUserCredentials(ctx, user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.logger.Error("failed to retrieve credentials", "user", user.Name, "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if err != nil || !auth.CheckFeedToken(r.URL.Query().Get("token"), user.Name, creds.PasswordHash) {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	opts := Options{
+		Category: query.Get("category"),
+		Keyword:  query.Get("q"),
+		Tag:      query.Get("tag"),
+		Link:     requestURL(r),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 32)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		opts.Limit = int32(n)
+	}
+
+	feed, err := Build(ctx, s.db, user, opts)
+	if err != nil {
+		s.logger.Error("failed to build feed", "user", user.Name, "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, format, feed); err != nil {
+		s.logger.Error("failed to render feed", "user", user.Name, "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType(format))
+	w.Write(buf.Bytes())
+}
+
+// requestURL is the feed's own URL, minus the token so the secret isn't
+// republished to everyone the feed is shared with.
+func requestURL(r *http.Request) string {
+	u := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path}
+	if r.TLS != nil {
+		u.Scheme = "https"
+	}
+	query := r.URL.Query()
+	query.Del("token")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,193 @@
+package outfeed
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/auth"
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/memstore"
+)
+
+type testServer struct {
+	t   *testing.T
+	ctx context.Context
+	db  *memstore.Store
+	mux *http.ServeMux
+	now time.Time
+}
+
+func newTestServer(t *testing.T) *testServer {
+	db := memstore.New()
+	mux := http.NewServeMux()
+	NewServer(db, slog.New(slog.DiscardHandler)).Register(mux)
+	return &testServer{
+		t:   t,
+		ctx: context.Background(),
+		db:  db,
+		mux: mux,
+		now: time.Date(2024, 9, 3, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// addUser creates a user with password and returns them with their feed
+// token.
+func (ts *testServer) addUser(name, password string) (database.User, string) {
+	ts.t.Helper()
+	user, err := ts.db.CreateUser(ts.ctx, database.CreateUserParams{
+		ID: uuid.New(), CreatedAt: ts.now, UpdatedAt: ts.now, Name: name,
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	err = ts.db.SetUserPassword(ts.ctx, database.SetUserPasswordParams{UserID: user.ID, PasswordHash: hash})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return user, auth.FeedToken(name, hash)
+}
+
+func (ts *testServer) addFeed(owner database.User, name string) database.Feed {
+	ts.t.Helper()
+	feed, err := ts.db.CreateFeed(ts.ctx, database.CreateFeedParams{
+		Url: "https://example.com/" + name, Name: name, UserID: owner.ID, CreatedAt: ts.now, UpdatedAt: ts.now,
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	if _, err := ts.db.CreateFeedFollow(ts.ctx, database.CreateFeedFollowParams{UserID: owner.ID, FeedID: feed.ID}); err != nil {
+		ts.t.Fatal(err)
+	}
+	return feed
+}
+
+func (ts *testServer) addPost(feed database.Feed, title, description string) {
+	ts.t.Helper()
+	ts.now = ts.now.Add(time.Hour)
+	_, err := ts.db.CreatePost(ts.ctx, database.CreatePostParams{
+		Title:       title,
+		Url:         "https://example.com/" + url.PathEscape(title),
+		Description: description,
+		PublishedAt: sql.NullTime{Time: ts.now, Valid: true},
+		FeedID:      feed.ID,
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+}
+
+func (ts *testServer) get(path string) *httptest.ResponseRecorder {
+	ts.t.Helper()
+	w := httptest.NewRecorder()
+	ts.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+type rssDoc struct {
+	Items []struct {
+		Title string `xml:"title"`
+	} `xml:"channel>item"`
+}
+
+func rssTitles(t *testing.T, w *httptest.ResponseRecorder) []string {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var doc rssDoc
+	if err := xml.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, it := range doc.Items {
+		titles = append(titles, it.Title)
+	}
+	slices.Sort(titles)
+	return titles
+}
+
+func TestFeedToken(t *testing.T) {
+	ts := newTestServer(t)
+	ann, annToken := ts.addUser("ann", "ann-password")
+	_, bobToken := ts.addUser("bob", "bob-password")
+	ts.addPost(ts.addFeed(ann, "news"), "post", "")
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"valid token", "/outfeed/ann/rss?token=" + annToken, http.StatusOK},
+		{"atom", "/outfeed/ann/atom?token=" + annToken, http.StatusOK},
+		{"no token", "/outfeed/ann/rss", http.StatusNotFound},
+		{"another user's token", "/outfeed/ann/rss?token=" + bobToken, http.StatusNotFound},
+		{"unknown user", "/outfeed/carol/rss?token=" + annToken, http.StatusNotFound},
+		{"unknown format", "/outfeed/ann/json?token=" + annToken, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := ts.get(tt.path); w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+}
+
+func TestSelfLinkOmitsToken(t *testing.T) {
+	ts := newTestServer(t)
+	ann, token := ts.addUser("ann", "ann-password")
+	ts.addPost(ts.addFeed(ann, "news"), "post", "")
+
+	for _, format := range []string{FormatRSS, FormatAtom} {
+		w := ts.get("/outfeed/ann/" + format + "?q=post&token=" + token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", format, w.Code, w.Body)
+		}
+		if strings.Contains(w.Body.String(), token) {
+			t.Errorf("%s: the feed token is published in the feed", format)
+		}
+		if !strings.Contains(w.Body.String(), "http://example.com/outfeed/ann/"+format+"?q=post") {
+			t.Errorf("%s: feed doesn't link to itself: %s", format, w.Body)
+		}
+	}
+}
+
+func TestKeywordMatchesLiterally(t *testing.T) {
+	ts := newTestServer(t)
+	ann, token := ts.addUser("ann", "ann-password")
+	feed := ts.addFeed(ann, "news")
+	ts.addPost(feed, "100% organic", "")
+	ts.addPost(feed, "1000 organic", "")
+	ts.addPost(feed, "snake_case", "")
+	ts.addPost(feed, "snakeycase", "")
+	ts.addPost(feed, "untitled", "<p>Half OFF everything</p>")
+
+	tests := []struct {
+		keyword string
+		want    []string
+	}{
+		{"0%", []string{"100% organic"}},
+		{"e_c", []string{"snake_case"}},
+		{"off", []string{"untitled"}},
+		{`\`, nil},
+	}
+	for _, tt := range tests {
+		path := "/outfeed/ann/rss?" + url.Values{"token": {token}, "q": {tt.keyword}}.Encode()
+		if got := rssTitles(t, ts.get(path)); !slices.Equal(got, tt.want) {
+			t.Errorf("q=%s: got %v, want %v", tt.keyword, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Package readability pulls the main article out of a web page, for feeds
+// that only publish a teaser.
+package readability
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/sanntintdev/gator/internal/richtext"
+)
+
+// ErrNoContent is returned when no part of the page looks like an article.
+var ErrNoContent = errors.New("no article content found")
+
+// minParagraphLength is the shortest paragraph counted towards a
+// container's score.
+const minParagraphLength = 25
+
+var (
+	positiveNames = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|text|blog|story`)
+	negativeNames = regexp.MustCompile(`(?i)comment|meta|footer|footnote|sidebar|sponsor|share|social|related|nav|menu|promo|widget|banner|masthead|breadcrumb|popup|subscribe|newsletter|\bads?\b`)
+)
+
+// unwanted elements are removed before scoring.
+var unwanted = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Nav:      true,
+	atom.Aside:    true,
+	atom.Form:     true,
+	atom.Header:   true,
+	atom.Footer:   true,
+	atom.Iframe:   true,
+	atom.Button:   true,
+	atom.Select:   true,
+}
+
+// Extract finds the main content of the HTML page read from r and returns it
+// as sanitized HTML, with relative links resolved against base.
+func Extract(r io.Reader, base *url.URL) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", err
+	}
+	removeUnwanted(doc)
+
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+	walk(doc, func(n *html.Node) {
+		if n.Type != html.ElementNode || (n.DataAtom != atom.P && n.DataAtom != atom.Pre && n.DataAtom != atom.Td) {
+			return
+		}
+		text := textOf(n)
+		if len(text) < minParagraphLength {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+		parent := n.Parent
+		if parent == nil {
+			return
+		}
+		for i, ancestor := range []*html.Node{parent, parent.Parent} {
+			if ancestor == nil || ancestor.Type != html.ElementNode {
+				continue
+			}
+			if _, ok := scores[ancestor]; !ok {
+				scores[ancestor] = initialScore(ancestor)
+				candidates = append(candidates, ancestor)
+			}
+			if i == 0 {
+				scores[ancestor] += score
+			} else {
+				scores[ancestor] += score / 2
+			}
+		}
+	})
+
+	var best *html.Node
+	var bestScore float64
+	for _, c := range candidates {
+		score := scores[c] * (1 - linkDensity(c))
+		if best == nil || score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	if best == nil {
+		return "", ErrNoContent
+	}
+
+	resolveURLs(best, base)
+
+	var buf bytes.Buffer
+	for c := best.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&buf, c); err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(richtext.Sanitize(buf.String())), nil
+}
+
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.DataAtom {
+	case atom.Article:
+		score = 10
+	case atom.Div, atom.Main, atom.Section:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+
+	for _, name := range []string{attr(n, "class"), attr(n, "id")} {
+		if name == "" {
+			continue
+		}
+		if negativeNames.MatchString(name) {
+			score -= 25
+		}
+		if positiveNames.MatchString(name) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// linkDensity is the share of n's text that sits inside links.
+func linkDensity(n *html.Node) float64 {
+	total := len(textOf(n))
+	if total == 0 {
+		return 0
+	}
+	var linked int
+	walk(n, func(c *html.Node) {
+		if c.Type == html.ElementNode && c.DataAtom == atom.A {
+			linked += len(textOf(c))
+		}
+	})
+	return min(float64(linked)/float64(total), 1)
+}
+
+func removeUnwanted(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode ||
+			(c.Type == html.ElementNode && (unwanted[c.DataAtom] || isHidden(c))) {
+			n.RemoveChild(c)
+		} else {
+			removeUnwanted(c)
+		}
+		c = next
+	}
+}
+
+func isHidden(n *html.Node) bool {
+	if _, ok := attrOK(n, "hidden"); ok {
+		return true
+	}
+	style := strings.ReplaceAll(strings.ToLower(attr(n, "style")), " ", "")
+	return strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden")
+}
+
+func resolveURLs(n *html.Node, base *url.URL) {
+	if base == nil {
+		return
+	}
+	walk(n, func(c *html.Node) {
+		if c.Type != html.ElementNode {
+			return
+		}
+		for i, a := range c.Attr {
+			if a.Key != "href" && a.Key != "src" {
+				continue
+			}
+			if ref, err := url.Parse(strings.TrimSpace(a.Val)); err == nil {
+				c.Attr[i].Val = base.ResolveReference(ref).String()
+			}
+		}
+	})
+}
+
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(c *html.Node) {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func walk(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := attrOK(n, key)
+	return v
+}
+
+func attrOK(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
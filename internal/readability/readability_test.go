@@ -0,0 +1,53 @@
+package readability
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	f, err := os.Open("testdata/article.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	base, _ := url.Parse("https://blog.example.com/2024/09/gopher")
+	got, err := Extract(f, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"<h1>Why the gopher crossed the road</h1>",
+		"the other side had better tunnels.",
+		"Tunnels, as any gopher will tell you",
+		`<img src="https://blog.example.com/2024/09/images/road.jpg" alt="A road with a gopher on it">`,
+		`<a href="https://blog.example.com/2024/09/field-notes" rel="nofollow noopener noreferrer">full field notes</a>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("article is missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"Popular posts", "Subscribe", "Great post", "hidden from readers", "Copyright", "track(", "font-family"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("article includes %q:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestExtractNoContent(t *testing.T) {
+	pages := []string{
+		"",
+		"<html><body><p>Too short.</p></body></html>",
+		`<html><body><nav><p>Only navigation, which is removed before anything is scored at all.</p></nav></body></html>`,
+	}
+	for _, page := range pages {
+		if got, err := Extract(strings.NewReader(page), nil); !errors.Is(err, ErrNoContent) {
+			t.Errorf("Extract(%q) = %q, %v, want ErrNoContent", page, got, err)
+		}
+	}
+}
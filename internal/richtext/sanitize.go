@@ -0,0 +1,230 @@
+// Package richtext cleans up and renders the HTML found in feed items.
+package richtext
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowedTags maps each tag kept by Sanitize to the attributes it may carry.
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": {"cite"},
+	"br":         nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"ins":        nil,
+	"li":         nil,
+	"ol":         nil,
+	"p":          nil,
+	"pre":        nil,
+	"q":          {"cite"},
+	"s":          nil,
+	"small":      nil,
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan", "rowspan"},
+	"tfoot":      nil,
+	"th":         {"colspan", "rowspan"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+// droppedTags are removed together with everything inside them.
+var droppedTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"noscript": true,
+	"template": true,
+	"form":     true,
+	"svg":      true,
+	"math":     true,
+}
+
+var voidTags = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"img": true,
+}
+
+// impliedEnds lists the tags whose start ends an open sibling, as a new li
+// ends the previous one. The search for the sibling stops at scope, so a
+// nested list doesn't close its parent item.
+var impliedEnds = map[string]struct{ closes, scope []string }{
+	"li": {[]string{"li"}, []string{"ul", "ol"}},
+	"p":  {[]string{"p"}, []string{"blockquote", "dd", "div", "dt", "figure", "li", "td", "th"}},
+	"td": {[]string{"td", "th"}, []string{"table", "tr"}},
+	"th": {[]string{"td", "th"}, []string{"table", "tr"}},
+	"tr": {[]string{"tr"}, []string{"table", "tbody", "tfoot", "thead"}},
+}
+
+// Sanitize returns s with every tag and attribute outside a small allowlist
+// removed, unsafe URLs dropped and unclosed tags closed. Text is kept.
+func Sanitize(s string) string {
+	var b strings.Builder
+	var open []string
+	var skipping string
+	skipDepth := 0
+
+	// closeFrom closes open[i] and everything opened after it.
+	closeFrom := func(i int) {
+		for j := len(open) - 1; j >= i; j-- {
+			b.WriteString("</" + open[j] + ">")
+		}
+		open = open[:i]
+	}
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		token := z.Token()
+
+		if skipping != "" {
+			switch {
+			case tt == html.StartTagToken && token.Data == skipping:
+				skipDepth++
+			case tt == html.EndTagToken && token.Data == skipping:
+				skipDepth--
+				if skipDepth == 0 {
+					skipping = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			b.WriteString(html.EscapeString(token.Data))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedTags[token.Data] {
+				if tt == html.StartTagToken {
+					skipping = token.Data
+					skipDepth = 1
+				}
+				continue
+			}
+			allowed, ok := allowedTags[token.Data]
+			if !ok {
+				continue
+			}
+			if token.Data == "img" && safeURL(attr(token, "src")) == "" {
+				continue
+			}
+			if i := impliedEnd(open, token.Data); i >= 0 {
+				closeFrom(i)
+			}
+			writeStartTag(&b, token, allowed)
+			if !voidTags[token.Data] && tt == html.StartTagToken {
+				open = append(open, token.Data)
+			}
+
+		case html.EndTagToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == token.Data {
+					closeFrom(i)
+					break
+				}
+			}
+		}
+	}
+
+	closeFrom(0)
+	return b.String()
+}
+
+// impliedEnd returns the index in open of the element that a start tag
+// ends implicitly, or -1 if there is none.
+func impliedEnd(open []string, tag string) int {
+	rule, ok := impliedEnds[tag]
+	if !ok {
+		return -1
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		switch {
+		case slices.Contains(rule.closes, open[i]):
+			return i
+		case slices.Contains(rule.scope, open[i]):
+			return -1
+		}
+	}
+	return -1
+}
+
+func writeStartTag(b *strings.Builder, token html.Token, allowed []string) {
+	b.WriteString("<" + token.Data)
+	for _, a := range token.Attr {
+		if a.Namespace != "" || !slices.Contains(allowed, a.Key) {
+			continue
+		}
+		value := a.Val
+		if a.Key == "href" || a.Key == "src" || a.Key == "cite" {
+			value = safeURL(value)
+			if value == "" {
+				continue
+			}
+		}
+		b.WriteString(" " + a.Key + `="` + html.EscapeString(value) + `"`)
+	}
+	if token.Data == "a" {
+		b.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+	b.WriteString(">")
+}
+
+// safeURL returns u if it is relative or uses http, https or mailto, and ""
+// otherwise.
+func safeURL(u string) string {
+	u = strings.TrimSpace(u)
+	parsed, err := url.Parse(u)
+	if err != nil || u == "" {
+		return ""
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "http", "https", "mailto":
+		return u
+	}
+	return ""
+}
+
+func attr(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package richtext
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain text", "Hello & goodbye", "Hello &amp; goodbye"},
+		{"allowed tags", "<p>Hi <b>there</b><br/>you</p>", "<p>Hi <b>there</b><br>you</p>"},
+		{"unknown tags keep their text", "<font color=red>red</font> <center>mid</center>", "red mid"},
+		{"script dropped with contents", "a<script>alert(1)</script>b", "ab"},
+		{"style dropped with contents", "<style>p { color: red }</style><p>x</p>", "<p>x</p>"},
+		{"nested dropped tags", "<svg><svg><text>x</text></svg>y</svg>z", "z"},
+		{"event handlers removed", `<p onclick="evil()" class="c">x</p>`, "<p>x</p>"},
+		{"link attributes", `<a href="https://example.com/?a=1&b=2" target="_blank">x</a>`, `<a href="https://example.com/?a=1&amp;b=2" rel="nofollow noopener noreferrer">x</a>`},
+		{"relative link", `<a href="/post">x</a>`, `<a href="/post" rel="nofollow noopener noreferrer">x</a>`},
+		{"mailto link", `<a href="mailto:me@example.com">x</a>`, `<a href="mailto:me@example.com" rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript link", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript link with spaces and case", `<a href=" JavaScript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"image", `<img src="https://example.com/a.png" alt="A" onerror="x">`, `<img src="https://example.com/a.png" alt="A">`},
+		{"image with data URL", `<img src="data:image/png;base64,AAAA">`, ""},
+		{"image without src", `<img alt="A">`, ""},
+		{"unclosed tags closed", "<ul><li>one<li>two", "<ul><li>one</li><li>two</li></ul>"},
+		{"nested list keeps its item open", "<ul><li>a<ul><li>b<li>c</ul><li>d</ul>", "<ul><li>a<ul><li>b</li><li>c</li></ul></li><li>d</li></ul>"},
+		{"paragraphs", "<p>one<p><b>two<p>three", "<p>one</p><p><b>two</b></p><p>three</p>"},
+		{"table cells and rows", "<table><tr><th>a<td>b<tr><td>c</table>", "<table><tr><th>a</th><td>b</td></tr><tr><td>c</td></tr></table>"},
+		{"misnested tags", "<b><i>x</b>y</i>", "<b><i>x</i></b>y"},
+		{"stray end tag", "x</p>y", "xy"},
+		{"escaped text stays escaped", "&lt;script&gt;", "&lt;script&gt;"},
+		{"attribute quotes escaped", `<abbr title="a &quot;b&quot;">x</abbr>`, `<abbr title="a &#34;b&#34;">x</abbr>`},
+		{"table", `<table><tr><td colspan="2" style="x">c</td></tr></table>`, `<table><tr><td colspan="2">c</td></tr></table>`},
+	}
+	for _, tt := range tests {
+		if got := Sanitize(tt.in); got != tt.want {
+			t.Errorf("%s: Sanitize(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package richtext
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Text renders an HTML fragment as plain text for the terminal. Paragraphs
+// and headings are separated by blank lines, list items are bulleted or
+// numbered, and links are numbered with their URLs listed at the end.
+func Text(s string) string {
+	r := &textRenderer{footnotes: true}
+	r.render(s)
+
+	out := r.String()
+	if len(r.links) > 0 {
+		var b strings.Builder
+		b.WriteString(out)
+		b.WriteString("\n\n")
+		for i, link := range r.links {
+			fmt.Fprintf(&b, "[%d] %s\n", i+1, link)
+		}
+		out = strings.TrimRight(b.String(), "\n")
+	}
+	return out
+}
+
+// Excerpt renders s as a single line of plain text cut at a word boundary
+// to at most limit characters.
+func Excerpt(s string, limit int) string {
+	r := &textRenderer{plain: true}
+	r.render(s)
+	text := strings.Join(strings.Fields(r.String()), " ")
+
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	cut := runes[:limit]
+	// Back up to the last space, unless the cut already ends a word.
+	if runes[limit] != ' ' {
+		for i := len(cut) - 1; i > limit/2; i-- {
+			if cut[i] == ' ' {
+				cut = cut[:i]
+				break
+			}
+		}
+	}
+	return strings.TrimRight(string(cut), " ,.;:") + "…"
+}
+
+type textRenderer struct {
+	b         strings.Builder
+	footnotes bool
+	// plain drops list markers, quote prefixes and heading marks.
+	plain bool
+	links []string
+	pre   int
+	lists []listState
+}
+
+type listState struct {
+	ordered bool
+	next    int
+}
+
+func (r *textRenderer) render(s string) {
+	nodes, err := html.ParseFragment(strings.NewReader(s), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		r.text(s)
+		return
+	}
+	for _, n := range nodes {
+		r.node(n)
+	}
+}
+
+func (r *textRenderer) String() string {
+	lines := strings.Split(r.b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+func (r *textRenderer) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		r.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Iframe, atom.Object, atom.Svg:
+		return
+
+	case atom.Br:
+		r.newlines(1)
+
+	case atom.Hr:
+		r.newlines(2)
+		if !r.plain {
+			r.b.WriteString("----")
+			r.newlines(2)
+		}
+
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Figure, atom.Figcaption,
+		atom.Table, atom.Dl, atom.Header, atom.Footer:
+		r.newlines(2)
+		r.children(n)
+		r.newlines(2)
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		r.newlines(2)
+		if !r.plain {
+			r.b.WriteString(strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		}
+		r.children(n)
+		r.newlines(2)
+
+	case atom.Tr, atom.Dt, atom.Dd:
+		r.newlines(1)
+		r.children(n)
+		r.newlines(1)
+
+	case atom.Td, atom.Th:
+		r.children(n)
+		r.b.WriteString("  ")
+
+	case atom.Ul, atom.Ol:
+		gap := 2
+		if len(r.lists) > 0 {
+			gap = 1
+		}
+		r.newlines(gap)
+		r.lists = append(r.lists, listState{ordered: n.DataAtom == atom.Ol, next: 1})
+		r.children(n)
+		r.lists = r.lists[:len(r.lists)-1]
+		r.newlines(gap)
+
+	case atom.Li:
+		r.newlines(1)
+		if r.plain {
+			r.children(n)
+			r.newlines(1)
+			return
+		}
+		r.b.WriteString(strings.Repeat("  ", max(len(r.lists)-1, 0)))
+		if len(r.lists) > 0 && r.lists[len(r.lists)-1].ordered {
+			l := &r.lists[len(r.lists)-1]
+			fmt.Fprintf(&r.b, "%d. ", l.next)
+			l.next++
+		} else {
+			r.b.WriteString("- ")
+		}
+		r.children(n)
+		r.newlines(1)
+
+	case atom.Pre:
+		r.newlines(2)
+		r.pre++
+		r.children(n)
+		r.pre--
+		r.newlines(2)
+
+	case atom.Blockquote:
+		r.newlines(2)
+		if r.plain {
+			r.children(n)
+			r.newlines(2)
+			return
+		}
+		inner := &textRenderer{footnotes: r.footnotes, links: r.links}
+		inner.children(n)
+		r.links = inner.links
+		for _, line := range strings.Split(inner.String(), "\n") {
+			r.b.WriteString("> " + line + "\n")
+		}
+		r.newlines(2)
+
+	case atom.Img:
+		if alt := strings.TrimSpace(attrValue(n, "alt")); alt != "" {
+			r.text(" [image: " + alt + "] ")
+		}
+
+	case atom.A:
+		href := strings.TrimSpace(attrValue(n, "href"))
+		start := r.b.Len()
+		r.children(n)
+		if !r.footnotes || safeURL(href) == "" || strings.HasPrefix(href, "#") {
+			return
+		}
+		label := strings.TrimSpace(r.b.String()[start:])
+		if label == href {
+			return
+		}
+		r.links = append(r.links, href)
+		fmt.Fprintf(&r.b, "[%d]", len(r.links))
+
+	default:
+		r.children(n)
+	}
+}
+
+func (r *textRenderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.node(c)
+	}
+}
+
+// text writes s, collapsing whitespace outside of <pre>.
+func (r *textRenderer) text(s string) {
+	if r.pre > 0 {
+		r.b.WriteString(s)
+		return
+	}
+
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		if s != "" && !r.atLineStart() {
+			r.space()
+		}
+		return
+	}
+	if startsWithSpace(s) && !r.atLineStart() {
+		r.space()
+	}
+	r.b.WriteString(strings.Join(words, " "))
+	if endsWithSpace(s) {
+		r.space()
+	}
+}
+
+func (r *textRenderer) space() {
+	if out := r.b.String(); out != "" && !strings.HasSuffix(out, " ") {
+		r.b.WriteByte(' ')
+	}
+}
+
+func (r *textRenderer) atLineStart() bool {
+	out := r.b.String()
+	return out == "" || strings.HasSuffix(out, "\n")
+}
+
+// newlines ends the current line and makes sure the output ends with at
+// least n line breaks, unless nothing has been written yet.
+func (r *textRenderer) newlines(n int) {
+	out := strings.TrimRight(r.b.String(), " ")
+	if out == "" {
+		return
+	}
+	have := len(out) - len(strings.TrimRight(out, "\n"))
+	r.b.Reset()
+	r.b.WriteString(out)
+	for ; have < n; have++ {
+		r.b.WriteByte('\n')
+	}
+}
+
+func startsWithSpace(s string) bool {
+	return s != "" && strings.TrimLeft(s, " \t\r\n") != s
+}
+
+func endsWithSpace(s string) bool {
+	return s != "" && strings.TrimRight(s, " \t\r\n") != s
+}
+
+func attrValue(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package richtext
+
+import "testing"
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain text", "Hello   world", "Hello world"},
+		{"paragraphs", "<p>One</p><p>Two <b>bold</b></p>", "One\n\nTwo bold"},
+		{"line breaks", "a<br>b", "a\nb"},
+		{"heading", "<h2>Title</h2><p>Body</p>", "## Title\n\nBody"},
+		{"unordered list", "<ul><li>one</li><li>two</li></ul>", "- one\n- two"},
+		{"ordered list", "<ol><li>one</li><li>two</li></ol>", "1. one\n2. two"},
+		{"nested list", "<ul><li>a<ol><li>b</li></ol></li></ul>", "- a\n  1. b"},
+		{"quote", "<blockquote><p>Said</p><p>twice</p></blockquote>", "> Said\n>\n> twice"},
+		{"preformatted", "<pre>a  b\n  c</pre>", "a  b\n  c"},
+		{"scripts dropped", "a<script>alert(1)</script>b", "ab"},
+		{"image alt", `<img src="x.png" alt="A cat">`, "[image: A cat]"},
+		{"entities decoded", "Tom &amp; Jerry", "Tom & Jerry"},
+		{"links are footnoted", `See <a href="https://a.example">this</a> and <a href="https://b.example">that</a>.`,
+			"See this[1] and that[2].\n\n[1] https://a.example\n[2] https://b.example"},
+		{"link to its own text", `<a href="https://a.example">https://a.example</a>`, "https://a.example"},
+		{"anchor and unsafe links", `<a href="#top">top</a> <a href="javascript:x()">x</a>`, "top x"},
+		{"link in a quote", `<blockquote><a href="https://a.example">quoted</a></blockquote><a href="https://b.example">after</a>`,
+			"> quoted[1]\n\nafter[2]\n\n[1] https://a.example\n[2] https://b.example"},
+	}
+	for _, tt := range tests {
+		if got := Text(tt.in); got != tt.want {
+			t.Errorf("%s: Text(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		limit int
+		want  string
+	}{
+		{"short text unchanged", "<p>Hello <b>world</b></p>", 20, "Hello world"},
+		{"blocks joined on one line", "<h1>Title</h1><ul><li>one</li><li>two</li></ul>", 50, "Title one two"},
+		{"no footnotes", `<a href="https://a.example">link</a>`, 20, "link"},
+		{"cut at a word", "The quick brown fox jumps", 18, "The quick brown…"},
+		{"cut at the end of a word", "The quick brown fox jumps", 15, "The quick brown…"},
+		{"trailing punctuation dropped", "One two, three four", 10, "One two…"},
+		{"long word cut mid-word", "Supercalifragilistic", 5, "Super…"},
+		{"counts characters, not bytes", "héllo wörld ünïcode", 11, "héllo wörld…"},
+	}
+	for _, tt := range tests {
+		if got := Excerpt(tt.in, tt.limit); got != tt.want {
+			t.Errorf("%s: Excerpt(%q, %d) = %q, want %q", tt.name, tt.in, tt.limit, got, tt.want)
+		}
+	}
+}
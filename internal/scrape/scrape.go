@@ -0,0 +1,233 @@
+// Package scrape turns plain HTML pages into feed items using CSS selector
+// rules, for sites that do not publish a feed.
+package scrape
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/sanntintdev/gator/internal/dates"
+)
+
+// Rules describe how to find items on a page. Item selects one element per
+// post; the other selectors are matched inside it. An empty Title uses the
+// item's text and an empty Link uses the first link in the item. Dates are
+// read from a datetime attribute when present and parsed with DateLayout,
+// or leniently when it is empty.
+type Rules struct {
+	Item        string `json:"item"`
+	Title       string `json:"title,omitempty"`
+	Link        string `json:"link,omitempty"`
+	Description string `json:"description,omitempty"`
+	Date        string `json:"date,omitempty"`
+	DateLayout  string `json:"date_layout,omitempty"`
+}
+
+// Item is a post found on a page. PubDate is RFC 3339 when a date was
+// found and parsed, the raw text when it could not be parsed, and empty
+// otherwise.
+type Item struct {
+	Title       string
+	Link        string
+	Description string
+	PubDate     string
+}
+
+var anyLink = cascadia.MustCompile("a[href]")
+
+type compiled struct {
+	item, title, link, description, date cascadia.Matcher
+}
+
+// ParseRules decodes rules stored as JSON and validates them.
+func ParseRules(data string) (Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal([]byte(data), &rules); err != nil {
+		return Rules{}, fmt.Errorf("invalid scrape rules: %w", err)
+	}
+	return rules, rules.Validate()
+}
+
+// JSON encodes the rules for storage.
+func (r Rules) JSON() string {
+	data, _ := json.Marshal(r)
+	return string(data)
+}
+
+// Validate checks that every selector compiles.
+func (r Rules) Validate() error {
+	_, err := r.compile()
+	return err
+}
+
+func (r Rules) compile() (compiled, error) {
+	var c compiled
+	if strings.TrimSpace(r.Item) == "" {
+		return c, errors.New("an item selector is required")
+	}
+
+	for _, s := range []struct {
+		name string
+		expr string
+		dst  *cascadia.Matcher
+	}{
+		{"item", r.Item, &c.item},
+		{"title", r.Title, &c.title},
+		{"link", r.Link, &c.link},
+		{"description", r.Description, &c.description},
+		{"date", r.Date, &c.date},
+	} {
+		if s.expr == "" {
+			continue
+		}
+		sel, err := cascadia.ParseGroup(s.expr)
+		if err != nil {
+			return c, fmt.Errorf("invalid %s selector %q: %w", s.name, s.expr, err)
+		}
+		*s.dst = sel
+	}
+	return c, nil
+}
+
+// Extract applies rules to the HTML page read from r. Relative links are
+// resolved against base. Items without a link are skipped, since posts are
+// keyed by URL.
+func Extract(r io.Reader, base *url.URL, rules Rules) ([]Item, error) {
+	c, err := rules.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var items []Item
+	for _, node := range cascadia.QueryAll(doc, c.item) {
+		item := Item{
+			Title: textOf(first(node, c.title)),
+			Link:  linkOf(node, c.link, base),
+		}
+		if item.Link == "" {
+			continue
+		}
+		if c.description != nil {
+			if n := cascadia.Query(node, c.description); n != nil {
+				item.Description = innerHTML(n)
+			}
+		}
+		if c.date != nil {
+			if n := cascadia.Query(node, c.date); n != nil {
+				item.PubDate = parseDate(n, rules.DateLayout)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// first returns the first match of sel in n, or n itself when sel is nil.
+func first(n *html.Node, sel cascadia.Matcher) *html.Node {
+	if sel == nil {
+		return n
+	}
+	return cascadia.Query(n, sel)
+}
+
+func linkOf(item *html.Node, sel cascadia.Matcher, base *url.URL) string {
+	n := first(item, sel)
+	if n == nil {
+		return ""
+	}
+
+	href, ok := attr(n, "href")
+	if !ok {
+		if a := cascadia.Query(n, anyLink); a != nil {
+			href, _ = attr(a, "href")
+		}
+	}
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	return ref.String()
+}
+
+func parseDate(n *html.Node, layout string) string {
+	value, ok := attr(n, "datetime")
+	if !ok {
+		value = textOf(n)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+
+	var t time.Time
+	var err error
+	if layout != "" {
+		t, err = time.Parse(layout, value)
+	} else {
+		t, err = dates.Parse(value)
+	}
+	if err != nil {
+		return value
+	}
+	return t.Format(time.RFC3339)
+}
+
+func textOf(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		if n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style) {
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func innerHTML(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		html.Render(&b, c)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,98 @@
+package scrape
+
+import (
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	base, _ := url.Parse("https://town.example.com/news/")
+	rules := Rules{
+		Item:        "article.news-item",
+		Title:       ".title",
+		Description: ".summary",
+		Date:        "time, .date",
+	}
+
+	f, err := os.Open("testdata/listing.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := Extract(f, base, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Item{
+		{
+			Title:       "Bridge reopens",
+			Link:        "https://town.example.com/news/bridge",
+			Description: "<p>The old bridge is open again.</p>",
+			PubDate:     "2024-09-03T10:00:00+02:00",
+		},
+		{
+			Title:       "Market moves to Saturday",
+			Link:        "https://other.example.com/market?day=sat",
+			Description: "<p>From October on.</p>",
+			PubDate:     "2024-09-01T00:00:00Z",
+		},
+		{
+			Title:   "Festival",
+			Link:    "https://town.example.com/news/festival.html",
+			PubDate: "soon",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestExtractDefaults(t *testing.T) {
+	page := `<ul><li><a href="/a">First post</a> <script>x()</script></li><li>No link</li></ul>`
+	got, err := Extract(strings.NewReader(page), nil, Rules{Item: "li"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{{Title: "First post", Link: "/a"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract = %+v, want %+v", got, want)
+	}
+}
+
+func TestExtractDateLayout(t *testing.T) {
+	page := `<div class="post"><a href="/p">P</a><span>03.09.2024</span></div>`
+	rules := Rules{Item: ".post", Date: "span", DateLayout: "02.01.2006"}
+	got, err := Extract(strings.NewReader(page), nil, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].PubDate != "2024-09-03T00:00:00Z" {
+		t.Errorf("Extract = %+v, want a date of 2024-09-03", got)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules := Rules{Item: "article", Title: "h2", DateLayout: "2006-01-02"}
+	got, err := ParseRules(rules.JSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != rules {
+		t.Errorf("round trip gave %+v, want %+v", got, rules)
+	}
+
+	for _, data := range []string{
+		`not json`,
+		`{}`,
+		`{"item": "   "}`,
+		`{"item": "article", "title": "h2["}`,
+	} {
+		if _, err := ParseRules(data); err == nil {
+			t.Errorf("ParseRules(%s) succeeded", data)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+-- SQLite version of the schema built by sql/schema/001 to 016, applied as a
+-- single migration. Later migrations keep the version numbers of their
+-- Postgres counterparts. Column order matches Postgres so that SELECT *
+-- scans into the same structs. Timestamps are stored as UTC text, which
+-- sorts chronologically.
+--
+-- Tables are created only if missing, so files created before migrations
+-- were tracked are adopted as they are.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS feeds (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT UNIQUE NOT NULL,
+    name TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_fetched_at TIMESTAMP NULL,
+    dead_at TIMESTAMP NULL,
+    fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE,
+    kind TEXT NOT NULL DEFAULT 'rss',
+    scrape_rules TEXT NULL
+);
+
+CREATE TABLE IF NOT EXISTS feed_follows (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users (id),
+    feed_id INTEGER NOT NULL REFERENCES feeds (id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, feed_id)
+);
+
+CREATE TABLE IF NOT EXISTS posts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL,
+    published_at TIMESTAMP,
+    feed_id INTEGER NOT NULL REFERENCES feeds (id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    date_flag TEXT NULL,
+    excerpt TEXT NOT NULL DEFAULT '',
+    full_content TEXT NULL
+);
+
+CREATE TABLE IF NOT EXISTS user_credentials (
+    user_id TEXT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    fever_api_key TEXT UNIQUE NULL
+);
+
+CREATE TABLE IF NOT EXISTS post_states (
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    is_starred BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE TABLE IF NOT EXISTS post_categories (
+    post_id INTEGER NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    PRIMARY KEY (post_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    feed_id INTEGER NULL REFERENCES feeds (id) ON DELETE CASCADE,
+    keyword TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER NULL,
+    last_error TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS feed_fetches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    feed_id INTEGER NOT NULL REFERENCES feeds (id) ON DELETE CASCADE,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    status_code INTEGER NULL,
+    bytes INTEGER NOT NULL DEFAULT 0,
+    item_count INTEGER NOT NULL DEFAULT 0,
+    new_post_count INTEGER NOT NULL DEFAULT 0,
+    error TEXT NULL,
+    error_kind TEXT NULL,
+    redirect_url TEXT NULL
+);
+
+CREATE INDEX IF NOT EXISTS feed_fetches_feed_id_started_at_idx ON feed_fetches (feed_id, started_at);
+
+-- +goose Down
+DROP TABLE feed_fetches;
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
+DROP TABLE post_categories;
+DROP TABLE post_states;
+DROP TABLE user_credentials;
+DROP TABLE posts;
+DROP TABLE feed_follows;
+DROP TABLE feeds;
+DROP TABLE users;
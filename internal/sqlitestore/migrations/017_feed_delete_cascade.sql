@@ -0,0 +1,153 @@
+-- SQLite cannot change a foreign key in place, so feed_follows and posts
+-- are rebuilt with ON DELETE CASCADE on feed_id. The tables that point at
+-- posts are rebuilt too: dropping posts would otherwise cascade into them.
+-- Renaming posts_new updates their references to it, and copying the
+-- sqlite_sequence rows keeps the IDs of deleted rows from being reused.
+
+-- +goose Up
+CREATE TABLE feed_follows_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users (id),
+    feed_id INTEGER NOT NULL REFERENCES feeds (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, feed_id)
+);
+INSERT INTO feed_follows_new SELECT * FROM feed_follows;
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'feed_follows') WHERE name = 'feed_follows_new';
+DROP TABLE feed_follows;
+ALTER TABLE feed_follows_new RENAME TO feed_follows;
+
+CREATE TABLE posts_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL,
+    published_at TIMESTAMP,
+    feed_id INTEGER NOT NULL REFERENCES feeds (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    date_flag TEXT NULL,
+    excerpt TEXT NOT NULL DEFAULT '',
+    full_content TEXT NULL
+);
+INSERT INTO posts_new SELECT * FROM posts;
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'posts') WHERE name = 'posts_new';
+
+CREATE TABLE post_states_new (
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts_new (id) ON DELETE CASCADE,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    is_starred BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, post_id)
+);
+INSERT INTO post_states_new SELECT * FROM post_states;
+
+CREATE TABLE post_categories_new (
+    post_id INTEGER NOT NULL REFERENCES posts_new (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    PRIMARY KEY (post_id, name)
+);
+INSERT INTO post_categories_new SELECT * FROM post_categories;
+
+CREATE TABLE webhook_deliveries_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts_new (id) ON DELETE CASCADE,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER NULL,
+    last_error TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO webhook_deliveries_new SELECT * FROM webhook_deliveries;
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'webhook_deliveries') WHERE name = 'webhook_deliveries_new';
+
+DROP TABLE webhook_deliveries;
+DROP TABLE post_categories;
+DROP TABLE post_states;
+DROP TABLE posts;
+ALTER TABLE posts_new RENAME TO posts;
+ALTER TABLE post_states_new RENAME TO post_states;
+ALTER TABLE post_categories_new RENAME TO post_categories;
+ALTER TABLE webhook_deliveries_new RENAME TO webhook_deliveries;
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (status, next_attempt_at);
+
+-- +goose Down
+CREATE TABLE feed_follows_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users (id),
+    feed_id INTEGER NOT NULL REFERENCES feeds (id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, feed_id)
+);
+INSERT INTO feed_follows_new SELECT * FROM feed_follows;
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'feed_follows') WHERE name = 'feed_follows_new';
+DROP TABLE feed_follows;
+ALTER TABLE feed_follows_new RENAME TO feed_follows;
+
+CREATE TABLE posts_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL,
+    published_at TIMESTAMP,
+    feed_id INTEGER NOT NULL REFERENCES feeds (id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    date_flag TEXT NULL,
+    excerpt TEXT NOT NULL DEFAULT '',
+    full_content TEXT NULL
+);
+INSERT INTO posts_new SELECT * FROM posts;
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'posts') WHERE name = 'posts_new';
+
+CREATE TABLE post_states_new (
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts_new (id) ON DELETE CASCADE,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    is_starred BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, post_id)
+);
+INSERT INTO post_states_new SELECT * FROM post_states;
+
+CREATE TABLE post_categories_new (
+    post_id INTEGER NOT NULL REFERENCES posts_new (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    PRIMARY KEY (post_id, name)
+);
+INSERT INTO post_categories_new SELECT * FROM post_categories;
+
+CREATE TABLE webhook_deliveries_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts_new (id) ON DELETE CASCADE,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER NULL,
+    last_error TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO webhook_deliveries_new SELECT * FROM webhook_deliveries;
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'webhook_deliveries') WHERE name = 'webhook_deliveries_new';
+
+DROP TABLE webhook_deliveries;
+DROP TABLE post_categories;
+DROP TABLE post_states;
+DROP TABLE posts;
+ALTER TABLE posts_new RENAME TO posts;
+ALTER TABLE post_states_new RENAME TO post_states;
+ALTER TABLE post_categories_new RENAME TO post_categories;
+ALTER TABLE webhook_deliveries_new RENAME TO webhook_deliveries;
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (status, next_attempt_at);
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE feed_follows ADD COLUMN title TEXT NULL;
+ALTER TABLE feed_follows ADD COLUMN muted BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE feed_follows ADD COLUMN notify TEXT NOT NULL DEFAULT 'all' CHECK (notify IN ('all', 'none'));
+ALTER TABLE feed_follows ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE feed_follows DROP COLUMN priority;
+ALTER TABLE feed_follows DROP COLUMN notify;
+ALTER TABLE feed_follows DROP COLUMN muted;
+ALTER TABLE feed_follows DROP COLUMN title;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    feed_id INTEGER NULL REFERENCES feeds (id) ON DELETE CASCADE,
+    pattern TEXT NOT NULL,
+    sink TEXT NOT NULL CHECK (sink IN ('stdout', 'file', 'command', 'smtp')),
+    target TEXT NOT NULL DEFAULT '',
+    max_per_hour INTEGER NOT NULL DEFAULT 10,
+    digest_after INTEGER NOT NULL DEFAULT 5,
+    window_start TIMESTAMP NULL,
+    window_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE alert_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    alert_id INTEGER NOT NULL REFERENCES alerts (id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (alert_id, post_id)
+);
+
+CREATE INDEX alert_events_status_idx ON alert_events (status, alert_id);
+
+-- +goose Down
+DROP TABLE alert_events;
+DROP TABLE alerts;
@@ -0,0 +1,70 @@
+-- name: CreateAlert :one
+INSERT INTO alerts (user_id, feed_id, pattern, sink, target, max_per_hour, digest_after, created_at, updated_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, NOW(), NOW())
+RETURNING *;
+
+-- name: RetrieveAlertsForUser :many
+SELECT * FROM alerts
+WHERE user_id = ?1
+ORDER BY id;
+
+-- name: DeleteAlert :execrows
+DELETE FROM alerts
+WHERE id = ?1 AND user_id = ?2;
+
+-- name: RetrieveAlertsForFeed :many
+SELECT a.* FROM alerts a
+WHERE (a.feed_id = ?1 OR a.feed_id IS NULL)
+  AND EXISTS (
+      SELECT 1 FROM feed_follows ff
+      WHERE ff.user_id = a.user_id AND ff.feed_id = ?1
+  )
+ORDER BY a.id;
+
+-- name: MoveAlertsToFeed :exec
+UPDATE alerts
+SET feed_id = ?1, updated_at = NOW()
+WHERE feed_id = ?2;
+
+-- name: RecordAlertWindow :exec
+UPDATE alerts
+SET window_start = ?2, window_count = ?3, updated_at = NOW()
+WHERE id = ?1;
+
+-- name: CreateAlertEvent :exec
+INSERT INTO alert_events (alert_id, post_id, status, attempts, created_at, updated_at)
+VALUES (?1, ?2, 'pending', 0, NOW(), NOW())
+ON CONFLICT (alert_id, post_id) DO NOTHING;
+
+-- name: RetrieveAlertsWithPendingEvents :many
+SELECT a.* FROM alerts a
+WHERE EXISTS (
+    SELECT 1 FROM alert_events e
+    WHERE e.alert_id = a.id AND e.status = 'pending'
+)
+ORDER BY a.id;
+
+-- name: RetrievePendingAlertEvents :many
+SELECT
+    e.id,
+    e.post_id,
+    e.attempts,
+    p.title,
+    p.url,
+    p.published_at,
+    COALESCE(ff.title, f.name) AS feed_name
+FROM alert_events e
+INNER JOIN alerts a ON e.alert_id = a.id
+INNER JOIN posts p ON e.post_id = p.id
+INNER JOIN feeds f ON p.feed_id = f.id
+LEFT JOIN feed_follows ff ON ff.feed_id = p.feed_id AND ff.user_id = a.user_id
+WHERE e.alert_id = ?1 AND e.status = 'pending'
+ORDER BY e.id;
+
+-- name: RecordAlertEvents :exec
+UPDATE alert_events
+SET status = ?1,
+    attempts = attempts + 1,
+    last_error = ?2,
+    updated_at = NOW()
+WHERE id IN (SELECT value FROM json_each(?3));
@@ -0,0 +1,61 @@
+-- name: CreateFeedFetch :exec
+INSERT INTO feed_fetches (feed_id, started_at, finished_at, status_code, bytes, item_count, new_post_count, error, error_kind, redirect_url)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10);
+
+-- name: RetrieveFeedFetches :many
+SELECT * FROM feed_fetches
+WHERE feed_id = ?1
+ORDER BY started_at DESC, id DESC
+LIMIT ?2;
+
+-- name: DeleteFeedFetchesBefore :execrows
+DELETE FROM feed_fetches
+WHERE feed_id = ?1 AND started_at < ?2;
+
+-- name: RetrieveFeedHealth :many
+-- SQLite has no LATERAL joins, and only plain columns keep their TIMESTAMP
+-- type, so the newest post comes from a grouped union with the feed's own
+-- creation time as the fallback.
+SELECT
+    f.id,
+    f.name,
+    f.url,
+    f.user_id,
+    f.created_at,
+    f.last_fetched_at,
+    f.dead_at,
+    (SELECT COUNT(*) FROM feed_follows ff WHERE ff.feed_id = f.id) AS follower_count,
+    last_post.created_at AS last_post_at,
+    (
+        SELECT COUNT(*) FROM feed_fetches x
+        WHERE x.feed_id = f.id
+          AND x.error IS NOT NULL
+          AND x.started_at > COALESCE(
+              (SELECT MAX(ok.started_at) FROM feed_fetches ok WHERE ok.feed_id = f.id AND ok.error IS NULL),
+              ''
+          )
+    ) AS consecutive_failures,
+    (
+        SELECT error FROM feed_fetches WHERE feed_id = f.id
+        ORDER BY started_at DESC, id DESC LIMIT 1
+    ) AS last_error,
+    (
+        SELECT error_kind FROM feed_fetches WHERE feed_id = f.id
+        ORDER BY started_at DESC, id DESC LIMIT 1
+    ) AS last_error_kind,
+    (
+        SELECT redirect_url FROM feed_fetches WHERE feed_id = f.id
+        ORDER BY started_at DESC, id DESC LIMIT 1
+    ) AS last_redirect_url
+FROM feeds f
+INNER JOIN (
+    SELECT feed_id, created_at, MAX(created_at)
+    FROM (
+        SELECT feed_id, created_at FROM posts
+        UNION ALL
+        SELECT id, created_at FROM feeds
+        WHERE id NOT IN (SELECT feed_id FROM posts)
+    )
+    GROUP BY feed_id
+) last_post ON last_post.feed_id = f.id
+ORDER BY f.id;
@@ -0,0 +1,63 @@
+-- name: CreateFeedFollow :one
+INSERT INTO feed_follows (user_id, feed_id, created_at, updated_at)
+VALUES (?1, ?2, NOW(), NOW())
+RETURNING
+    id,
+    user_id,
+    feed_id,
+    created_at,
+    updated_at,
+    (SELECT u.name FROM users u WHERE u.id = feed_follows.user_id) AS user_name,
+    (SELECT f.name FROM feeds f WHERE f.id = feed_follows.feed_id) AS feed_name;
+
+-- name: RetrieveFeedFollowsForUser :many
+SELECT
+    ff.id,
+    ff.user_id,
+    ff.feed_id,
+    ff.created_at,
+    ff.updated_at,
+    u.name AS user_name,
+    f.name AS feed_name,
+    ff.folder_id,
+    fo.name AS folder_name,
+    f.url AS feed_url,
+    ff.title,
+    ff.muted,
+    ff.notify,
+    ff.priority
+FROM feed_follows ff
+INNER JOIN users u ON ff.user_id = u.id
+INNER JOIN feeds f ON ff.feed_id = f.id
+LEFT JOIN folders fo ON ff.folder_id = fo.id
+WHERE ff.user_id = ?1
+ORDER BY fo.name NULLS FIRST, ff.priority DESC, COALESCE(ff.title, f.name);
+
+-- name: RetrieveFeedFollow :one
+SELECT id, user_id, feed_id, created_at, updated_at, folder_id, title, muted, notify, priority FROM feed_follows
+WHERE user_id = ?1 AND feed_id = ?2;
+
+-- name: DeleteFeedFollow :exec
+DELETE FROM feed_follows
+WHERE user_id = ?1 AND feed_id = ?2;
+
+-- name: DeleteFeedFollowsForFeed :exec
+DELETE FROM feed_follows
+WHERE feed_id = ?1;
+
+-- name: MoveFeedFollows :exec
+INSERT INTO feed_follows (user_id, feed_id, folder_id, title, muted, notify, priority, created_at, updated_at)
+SELECT user_id, ?1, folder_id, title, muted, notify, priority, created_at, NOW()
+FROM feed_follows
+WHERE feed_id = ?2
+ON CONFLICT (user_id, feed_id) DO NOTHING;
+
+-- name: SetFeedFollowFolder :execrows
+UPDATE feed_follows
+SET folder_id = ?3, updated_at = NOW()
+WHERE user_id = ?1 AND feed_id = ?2;
+
+-- name: UpdateFeedFollowSettings :exec
+UPDATE feed_follows
+SET title = ?3, muted = ?4, notify = ?5, priority = ?6, updated_at = NOW()
+WHERE user_id = ?1 AND feed_id = ?2;
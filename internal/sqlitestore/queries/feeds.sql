@@ -0,0 +1,87 @@
+-- name: CreateFeed :one
+INSERT INTO feeds (url, name, user_id, created_at, updated_at)
+VALUES (
+    ?1,
+    ?2,
+    ?3,
+    ?4,
+    ?5
+)
+RETURNING *;
+
+-- name: RetrieveFeedsWithUser :many
+SELECT * FROM feeds
+LEFT JOIN users ON feeds.user_id = users.id;
+
+-- name: RetrieveFeedWithURL :one
+SELECT * FROM feeds
+WHERE  url = ?1;
+
+-- name: MarkFeedFetched :exec
+UPDATE feeds
+SET last_fetched_at = NOW(), updated_at = NOW()
+WHERE id = ?1;
+
+-- name: RetrieveNextFeedToFetch :one
+SELECT * FROM feeds
+WHERE dead_at IS NULL
+ORDER BY last_fetched_at ASC NULLS FIRST
+LIMIT 1;
+
+-- name: RetrieveFollowedFeedsForUser :many
+SELECT f.* FROM feeds f
+INNER JOIN feed_follows ff ON ff.feed_id = f.id
+WHERE ff.user_id = ?1
+ORDER BY f.name;
+
+-- name: RetrieveFeedByID :one
+SELECT * FROM feeds
+WHERE id = ?1;
+
+-- name: UpdateFeedURL :exec
+UPDATE feeds
+SET url = ?2, updated_at = NOW()
+WHERE id = ?1;
+
+-- name: RenameFeed :exec
+UPDATE feeds
+SET name = ?2, updated_at = NOW()
+WHERE id = ?1;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = ?1;
+
+-- name: CountFeedReferences :one
+SELECT
+    (SELECT COUNT(*) FROM feed_follows ff WHERE ff.feed_id = ?1) AS follow_count,
+    (SELECT COUNT(*) FROM posts p WHERE p.feed_id = ?1) AS post_count,
+    (SELECT COUNT(*) FROM post_states ps
+        INNER JOIN posts p ON p.id = ps.post_id
+        WHERE p.feed_id = ?1) AS post_state_count,
+    (SELECT COUNT(*) FROM webhooks w WHERE w.feed_id = ?1) AS webhook_count,
+    (SELECT COUNT(*) FROM feed_fetches x WHERE x.feed_id = ?1) AS fetch_count,
+    (SELECT COUNT(*) FROM filter_rules r WHERE r.feed_id = ?1) AS filter_rule_count,
+    (SELECT COUNT(*) FROM post_tags pt
+        INNER JOIN posts p ON p.id = pt.post_id
+        WHERE p.feed_id = ?1) AS post_tag_count,
+    (SELECT COUNT(*) FROM alerts a WHERE a.feed_id = ?1) AS alert_count,
+    (SELECT COUNT(*) FROM alert_events e
+        INNER JOIN alerts a ON a.id = e.alert_id
+        INNER JOIN posts p ON p.id = e.post_id
+        WHERE a.feed_id = ?1 OR p.feed_id = ?1) AS alert_event_count;
+
+-- name: SetFeedDead :exec
+UPDATE feeds
+SET dead_at = NOW(), updated_at = NOW()
+WHERE id = ?1;
+
+-- name: SetFeedFullContent :exec
+UPDATE feeds
+SET fetch_full_content = ?2, updated_at = NOW()
+WHERE id = ?1;
+
+-- name: SetFeedScrapeRules :exec
+UPDATE feeds
+SET kind = ?2, scrape_rules = ?3, updated_at = NOW()
+WHERE id = ?1;
@@ -0,0 +1,22 @@
+-- name: CreateFolder :one
+INSERT INTO folders (user_id, name, created_at, updated_at)
+VALUES (?1, ?2, NOW(), NOW())
+RETURNING *;
+
+-- name: RetrieveFoldersForUser :many
+SELECT * FROM folders
+WHERE user_id = ?1
+ORDER BY name;
+
+-- name: RetrieveFolderByName :one
+SELECT * FROM folders
+WHERE user_id = ?1 AND name = ?2;
+
+-- name: RenameFolder :exec
+UPDATE folders
+SET name = ?2, updated_at = NOW()
+WHERE id = ?1;
+
+-- name: DeleteFolder :exec
+DELETE FROM folders
+WHERE id = ?1;
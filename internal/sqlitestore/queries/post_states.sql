@@ -0,0 +1,164 @@
+-- name: SetPostRead :exec
+INSERT INTO post_states (user_id, post_id, is_read, created_at, updated_at)
+VALUES (?1, ?2, ?3, NOW(), NOW())
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET is_read = EXCLUDED.is_read, updated_at = NOW();
+
+-- name: SetPostStarred :exec
+INSERT INTO post_states (user_id, post_id, is_starred, created_at, updated_at)
+VALUES (?1, ?2, ?3, NOW(), NOW())
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET is_starred = EXCLUDED.is_starred, updated_at = NOW();
+
+-- name: SetPostHidden :exec
+INSERT INTO post_states (user_id, post_id, is_hidden, created_at, updated_at)
+VALUES (?1, ?2, ?3, NOW(), NOW())
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET is_hidden = EXCLUDED.is_hidden, updated_at = NOW();
+
+-- name: MarkPostsReadForUser :exec
+INSERT INTO post_states (user_id, post_id, is_read, created_at, updated_at)
+SELECT ff.user_id, p.id, TRUE, NOW(), NOW()
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = ?1
+  AND (?2 IS NULL OR p.feed_id = ?2)
+  AND (?3 IS NULL OR ff.folder_id = ?3)
+  AND p.created_at <= ?4
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET is_read = TRUE, updated_at = NOW();
+
+-- name: CountUnreadPostsForUser :many
+-- created_at is a bare column taken from the row with the MAX, which keeps
+-- its TIMESTAMP type for the driver.
+SELECT
+    p.feed_id,
+    ff.folder_id,
+    ff.muted,
+    COUNT(*) AS unread_count,
+    p.created_at AS newest_created_at
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = ?1
+  AND COALESCE(ps.is_read, FALSE) = FALSE
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+GROUP BY p.feed_id, ff.folder_id, ff.muted
+HAVING MAX(p.created_at) IS NOT NULL;
+
+-- name: RetrieveStreamPostsForUser :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.created_at,
+    p.updated_at,
+    p.author,
+    COALESCE(ff.title, f.name) AS feed_name,
+    f.url AS feed_url,
+    COALESCE(ps.is_read, FALSE) AS is_read,
+    COALESCE(ps.is_starred, FALSE) AS is_starred
+FROM posts p
+INNER JOIN feeds f ON p.feed_id = f.id
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = ?1
+  AND (?2 IS NULL OR p.feed_id = ?2)
+  AND (?3 IS NULL OR ff.folder_id = ?3)
+  AND (?2 IS NOT NULL OR NOT ff.muted)
+  AND (NOT ?4 OR COALESCE(ps.is_read, FALSE) = FALSE)
+  AND (NOT ?5 OR COALESCE(ps.is_starred, FALSE) = TRUE)
+  AND (?6 IS NULL OR p.created_at > ?6)
+  AND (?7 IS NULL OR p.created_at < ?7)
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT ?8
+OFFSET ?9;
+
+-- name: RetrievePostsForUserByIDs :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.created_at,
+    p.updated_at,
+    p.author,
+    COALESCE(ff.title, f.name) AS feed_name,
+    f.url AS feed_url,
+    COALESCE(ps.is_read, FALSE) AS is_read,
+    COALESCE(ps.is_starred, FALSE) AS is_starred
+FROM posts p
+INNER JOIN feeds f ON p.feed_id = f.id
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id AND ff.user_id = ?1
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ?1
+WHERE p.id IN (SELECT value FROM json_each(?2))
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+ORDER BY p.created_at DESC, p.id DESC;
+
+-- name: RetrievePostsForUserSinceID :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.created_at,
+    p.updated_at,
+    p.author,
+    COALESCE(ps.is_read, FALSE) AS is_read,
+    COALESCE(ps.is_starred, FALSE) AS is_starred
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = ?1 AND p.id > ?2 AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+ORDER BY p.id ASC
+LIMIT ?3;
+
+-- name: RetrievePostsForUserBeforeID :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.created_at,
+    p.updated_at,
+    p.author,
+    COALESCE(ps.is_read, FALSE) AS is_read,
+    COALESCE(ps.is_starred, FALSE) AS is_starred
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = ?1 AND p.id < ?2 AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+ORDER BY p.id DESC
+LIMIT ?3;
+
+-- name: CountPostsForUser :one
+SELECT COUNT(*) FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = ?1 AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE;
+
+-- name: RetrieveUnreadPostIDsForUser :many
+SELECT p.id FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = ?1 AND NOT ff.muted AND COALESCE(ps.is_read, FALSE) = FALSE
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+ORDER BY p.id;
+
+-- name: RetrieveStarredPostIDsForUser :many
+SELECT post_id FROM post_states
+WHERE user_id = ?1 AND is_starred = TRUE
+ORDER BY post_id;
@@ -0,0 +1,86 @@
+-- name: CreatePost :one
+INSERT INTO posts (title, url, description, published_at, feed_id, date_flag, excerpt, author, created_at, updated_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, Now(), Now())
+RETURNING id;
+
+-- name: RetrievePostsForUser :many
+SELECT p.id, p.title, p.url, p.description, p.published_at, p.feed_id, p.created_at, p.updated_at, p.date_flag, p.excerpt, p.full_content, p.author
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = ?1 AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+  AND (NOT ?2 OR COALESCE(ps.is_read, FALSE) = FALSE)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT ?3;
+
+-- name: RetrievePostsInFolder :many
+SELECT p.id, p.title, p.url, p.description, p.published_at, p.feed_id, p.created_at, p.updated_at, p.date_flag, p.excerpt, p.full_content, p.author
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.folder_id = ?1 AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+  AND (NOT ?2 OR COALESCE(ps.is_read, FALSE) = FALSE)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT ?3;
+
+-- name: CreatePostCategory :exec
+INSERT INTO post_categories (post_id, name)
+VALUES (?1, ?2)
+ON CONFLICT DO NOTHING;
+
+-- name: RetrieveCategoriesForPosts :many
+SELECT post_id, name FROM post_categories
+WHERE post_id IN (SELECT value FROM json_each(?1))
+ORDER BY post_id, name;
+
+-- name: RetrieveTimelineForUser :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.created_at,
+    p.updated_at,
+    COALESCE(ff.title, f.name) AS feed_name,
+    f.url AS feed_url
+FROM posts p
+INNER JOIN feeds f ON p.feed_id = f.id
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = ?1
+  AND NOT ff.muted
+  AND (?2 IS NULL OR EXISTS (
+      SELECT 1 FROM post_categories pc
+      WHERE pc.post_id = p.id AND LOWER(pc.name) = LOWER(?2)
+  ))
+  AND (?3 IS NULL
+      OR p.title LIKE '%' || ?3 || '%' ESCAPE '\'
+      OR p.description LIKE '%' || ?3 || '%' ESCAPE '\'
+      OR p.full_content LIKE '%' || ?3 || '%' ESCAPE '\')
+  AND (?4 IS NULL OR EXISTS (
+      SELECT 1 FROM post_tags pt
+      WHERE pt.post_id = p.id AND pt.user_id = ff.user_id AND pt.name = ?4
+  ))
+  AND NOT EXISTS (
+      SELECT 1 FROM post_states ps
+      WHERE ps.post_id = p.id AND ps.user_id = ff.user_id AND ps.is_hidden
+  )
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT ?5;
+
+-- name: DeletePostsForFeed :exec
+DELETE FROM posts
+WHERE feed_id = ?1;
+
+-- name: MovePostsToFeed :exec
+UPDATE posts
+SET feed_id = ?1, updated_at = NOW()
+WHERE feed_id = ?2;
+
+-- name: SetPostFullContent :exec
+UPDATE posts
+SET full_content = ?2, updated_at = NOW()
+WHERE id = ?1;
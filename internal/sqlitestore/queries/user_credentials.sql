@@ -0,0 +1,15 @@
+-- name: SetUserPassword :exec
+INSERT INTO user_credentials (user_id, password_hash, fever_api_key, created_at, updated_at)
+VALUES (?1, ?2, ?3, NOW(), NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET password_hash = EXCLUDED.password_hash,
+    fever_api_key = EXCLUDED.fever_api_key,
+    updated_at = NOW();
+
+-- name: GetUserCredentials :one
+SELECT * FROM user_credentials WHERE user_id = ?1;
+
+-- name: GetUserByFeverAPIKey :one
+SELECT u.* FROM users u
+INNER JOIN user_credentials uc ON uc.user_id = u.id
+WHERE uc.fever_api_key = ?1;
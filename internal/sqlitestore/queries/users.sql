@@ -0,0 +1,30 @@
+-- name: CreateUser :one
+INSERT INTO users (id, created_at, updated_at, name)
+VALUES (
+    ?1,
+    ?2,
+    ?3,
+    ?4
+)
+RETURNING *;
+
+-- name: GetUser :one
+SELECT * FROM users WHERE name = ?1;
+
+-- name: GetUserById :one
+SELECT * FROM users WHERE id = ?1;
+
+-- name: GetUsers :many
+SELECT * FROM users;
+
+-- name: ResetAllUser :exec
+DELETE FROM webhook_deliveries;
+DELETE FROM webhooks;
+DELETE FROM post_categories;
+DELETE FROM post_states;
+DELETE FROM feed_fetches;
+DELETE FROM posts;
+DELETE FROM feed_follows;
+DELETE FROM feeds;
+DELETE FROM user_credentials;
+DELETE FROM users;
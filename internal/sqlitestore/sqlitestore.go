@@ -0,0 +1,239 @@
+// Package sqlitestore runs gator on a SQLite file instead of Postgres.
+//
+// It reuses the sqlc-generated database.Queries through a DBTX that swaps
+// each Postgres statement for the query of the same name in queries/*.sql.
+// Those files mirror sql/queries: every query keeps its name, parameter
+// order and result columns, so only the SQL changes. Open refuses to start
+// if a query has no SQLite version.
+//
+// The schema is built by the goose migrations in Migrations, which share
+// their version numbers with sql/schema.
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations holds the SQLite migrations at its root.
+var Migrations, _ = fs.Sub(migrationFiles, "migrations")
+
+//go:embed queries/*.sql
+var queryFiles embed.FS
+
+// timeFormat is how timestamps are stored. It matches the driver's
+// "sqlite" write format, and values in UTC compare correctly as text.
+const timeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+var registerNow sync.Once
+
+// Store is a database.Store backed by a SQLite file.
+type Store struct {
+	*database.Queries
+	db      *sql.DB
+	queries map[string]string
+}
+
+var _ database.Store = (*Store)(nil)
+
+// Open opens or creates the SQLite database at path. It does not create
+// any tables; apply Migrations to it first.
+func Open(path string) (*Store, error) {
+	queries, err := loadQueries()
+	if err != nil {
+		return nil, err
+	}
+
+	// Postgres queries call NOW(); give SQLite a version that writes the
+	// same format as bound time.Time values.
+	registerNow.Do(func() {
+		sqlite.MustRegisterScalarFunction("now", 0, func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+			return time.Now().UTC().Format(timeFormat), nil
+		})
+	})
+
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// A single connection serializes writers, which SQLite requires anyway,
+	// and keeps in-memory databases from splitting across connections.
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db, queries: queries}
+	s.Queries = database.New(dialect{db, queries})
+	return s, nil
+}
+
+// DB returns the underlying connection, for running migrations.
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) InTx(ctx context.Context, fn func(database.Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(database.New(dialect{tx, s.queries})); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+var queryName = regexp.MustCompile(`^-- name: (\w+)`)
+
+// loadQueries reads the SQLite queries by name and checks that every
+// method of database.Querier has one.
+func loadQueries() (map[string]string, error) {
+	files, err := queryFiles.ReadDir("queries")
+	if err != nil {
+		return nil, err
+	}
+
+	queries := map[string]string{}
+	for _, f := range files {
+		data, err := queryFiles.ReadFile("queries/" + f.Name())
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range strings.Split(string(data), "\n-- name: ") {
+			if !strings.HasPrefix(q, "-- name: ") {
+				q = "-- name: " + q
+			}
+			m := queryName.FindStringSubmatch(q)
+			if m == nil {
+				continue
+			}
+			queries[m[1]] = strings.TrimSpace(q)
+		}
+	}
+
+	querier := reflect.TypeOf((*database.Querier)(nil)).Elem()
+	for i := range querier.NumMethod() {
+		if name := querier.Method(i).Name; queries[name] == "" {
+			return nil, fmt.Errorf("no SQLite version of query %s", name)
+		}
+	}
+	return queries, nil
+}
+
+// dialect is a database.DBTX that runs the SQLite version of each query.
+type dialect struct {
+	db      database.DBTX
+	queries map[string]string
+}
+
+func (d dialect) translate(query string, args []interface{}) (string, []interface{}, error) {
+	m := queryName.FindStringSubmatch(query)
+	if m == nil {
+		return "", nil, fmt.Errorf("sqlitestore: unnamed query")
+	}
+	translated, ok := d.queries[m[1]]
+	if !ok {
+		return "", nil, fmt.Errorf("sqlitestore: no SQLite version of query %s", m[1])
+	}
+
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		v, err := convertArg(arg)
+		if err != nil {
+			return "", nil, fmt.Errorf("sqlitestore: %s argument %d: %w", m[1], i+1, err)
+		}
+		converted[i] = v
+	}
+	return translated, converted, nil
+}
+
+// convertArg adapts arguments built for lib/pq: times are stored in UTC and
+// arrays are passed as JSON for json_each.
+func convertArg(arg interface{}) (interface{}, error) {
+	switch v := arg.(type) {
+	case time.Time:
+		return v.UTC(), nil
+	case sql.NullTime:
+		if v.Valid {
+			v.Time = v.Time.UTC()
+		}
+		return v, nil
+	case pq.GenericArray:
+		return jsonArray(v.A)
+	case *pq.BoolArray, *pq.Float64Array, *pq.Float32Array, *pq.Int64Array, *pq.Int32Array, *pq.StringArray:
+		return jsonArray(v)
+	}
+	return arg, nil
+}
+
+func jsonArray(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	if string(data) == "null" {
+		return "[]", nil
+	}
+	return string(data), nil
+}
+
+func (d dialect) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args, err := d.translate(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return d.db.ExecContext(ctx, query, args...)
+}
+
+func (d dialect) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	query, _, err := d.translate(query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.db.PrepareContext(ctx, query)
+}
+
+func (d dialect) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args, err := d.translate(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return d.db.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext cannot return an error of its own, so a query that fails
+// to translate is run as-is and fails in SQLite instead.
+func (d dialect) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	translated, converted, err := d.translate(query, args)
+	if err != nil {
+		return d.db.QueryRowContext(ctx, query, args...)
+	}
+	return d.db.QueryRowContext(ctx, translated, converted...)
+}
@@ -0,0 +1,187 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/migrate"
+	"github.com/sanntintdev/gator/sql/schema"
+)
+
+func TestLoadQueries(t *testing.T) {
+	queries, err := loadQueries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, q := range queries {
+		if !strings.HasPrefix(q, "-- name: "+name+" ") {
+			t.Errorf("query %s starts %q", name, q[:min(len(q), 40)])
+		}
+		if strings.Contains(q, "$1") || strings.Contains(q, "sqlc.arg") || strings.Contains(q, "::") {
+			t.Errorf("query %s still has Postgres syntax", name)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	d := dialect{queries: map[string]string{
+		"GetThing": "-- name: GetThing :one\nSELECT ?1",
+	}}
+	berlin := time.FixedZone("CEST", 2*60*60)
+	at := time.Date(2024, 9, 3, 12, 0, 0, 0, berlin)
+	id := uuid.New()
+
+	tests := []struct {
+		name string
+		arg  interface{}
+		want interface{}
+	}{
+		{"string", "x", "x"},
+		{"uuid", id, id},
+		{"time in UTC", at, at.UTC()},
+		{"null time in UTC", sql.NullTime{Time: at, Valid: true}, sql.NullTime{Time: at.UTC(), Valid: true}},
+		{"invalid null time", sql.NullTime{}, sql.NullTime{}},
+		{"int32 array", pq.Array([]int32{1, 2, 3}), "[1,2,3]"},
+		{"string array", pq.Array([]string{"a", `b"c`}), `["a","b\"c"]`},
+		{"nil array", pq.Array([]int32(nil)), "[]"},
+	}
+	for _, tt := range tests {
+		query, args, err := d.translate("-- name: GetThing :one\nSELECT $1::text", []interface{}{tt.arg})
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if query != "-- name: GetThing :one\nSELECT ?1" {
+			t.Errorf("%s: translated to %q", tt.name, query)
+		}
+		if !reflect.DeepEqual(args, []interface{}{tt.want}) {
+			t.Errorf("%s: args %#v, want %#v", tt.name, args, tt.want)
+		}
+	}
+
+	if _, _, err := d.translate("SELECT 1", nil); err == nil {
+		t.Error("expected an error for an unnamed query")
+	}
+	if _, _, err := d.translate("-- name: Other :one\nSELECT 1", nil); err == nil {
+		t.Error("expected an error for a query without a SQLite version")
+	}
+}
+
+// openMigrated returns a new in-memory store with every migration applied.
+func openMigrated(t *testing.T) (*Store, *migrate.Migrator) {
+	t.Helper()
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	m, err := migrate.New(store.DB(), Migrations, migrate.SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return store, m
+}
+
+func TestMigrationsUpDown(t *testing.T) {
+	ctx := context.Background()
+	store, m := openMigrated(t)
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range statuses {
+		if _, err := m.Down(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var tables int
+	err = store.DB().QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('goose_db_version', 'sqlite_sequence')`).Scan(&tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Errorf("%d tables left after rolling back every migration", tables)
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("migrating up again: %v", err)
+	}
+}
+
+// TestMigrationVersions checks that the SQLite migrations after the
+// baseline match the Postgres ones, so both report the same version.
+func TestMigrationVersions(t *testing.T) {
+	pg, err := fs.Glob(schema.Migrations, "*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lite, err := fs.Glob(Migrations, "*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseline := lite[0][:3]
+	var after []string
+	for _, name := range pg {
+		if name[:3] > baseline {
+			after = append(after, name)
+		}
+	}
+	if got, want := strings.Join(lite[1:], " "), strings.Join(after, " "); got != want {
+		t.Errorf("SQLite migrations after %s are %s, want %s", lite[0], got, want)
+	}
+	if lite[len(lite)-1] != pg[len(pg)-1] {
+		t.Errorf("latest SQLite migration is %s, Postgres has %s", lite[len(lite)-1], pg[len(pg)-1])
+	}
+}
+
+func TestQueries(t *testing.T) {
+	ctx := context.Background()
+	store, _ := openMigrated(t)
+
+	now := time.Now()
+	user, err := store.CreateUser(ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      "ann",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetUser(ctx, "ann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != user.ID || !got.CreatedAt.Equal(now) {
+		t.Errorf("GetUser returned %+v, want %+v", got, user)
+	}
+
+	err = store.InTx(ctx, func(q database.Querier) error {
+		if _, err := q.CreateFeed(ctx, database.CreateFeedParams{
+			Url: "https://example.com/feed", Name: "Example", UserID: user.ID, CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			return err
+		}
+		return sql.ErrTxDone
+	})
+	if err != sql.ErrTxDone {
+		t.Fatalf("InTx returned %v", err)
+	}
+	if _, err := store.RetrieveFeedWithURL(ctx, "https://example.com/feed"); err != sql.ErrNoRows {
+		t.Errorf("feed created in a rolled back transaction: %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+// Package webhooks queues and delivers signed notifications about newly
+// saved posts to user-configured HTTP endpoints.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sanntintdev/gator/internal/database"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+
+	EventPostCreated = "post.created"
+
+	// MaxAttempts is how many times a delivery is tried before it is
+	// dead-lettered and only a manual replay will send it again.
+	MaxAttempts = 8
+
+	SignatureHeader = "X-Gator-Signature"
+
+	batchSize  = 20
+	maxBackoff = time.Hour
+)
+
+type Post struct {
+	ID          int32
+	Title       string
+	URL         string
+	Description string
+	PublishedAt *time.Time
+}
+
+type Payload struct {
+	Event     string      `json:"event"`
+	WebhookID int32       `json:"webhook_id"`
+	Post      PostPayload `json:"post"`
+	Feed      FeedPayload `json:"feed"`
+}
+
+type PostPayload struct {
+	ID          int32      `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+type FeedPayload struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// GenerateSecret returns a random secret for signing payloads.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the value of the signature header for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Enqueue stores a pending delivery for every webhook interested in post.
+// It returns the number of deliveries queued.
+func Enqueue(ctx context.Context, db database.Querier, feed database.Feed, post Post) (int, error) {
+	hooks, err := db.RetrieveWebhooksForFeed(ctx, sql.NullInt32{Int32: feed.ID, Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve webhooks: %w", err)
+	}
+
+	queued := 0
+	for _, hook := range hooks {
+		if !Matches(hook, post) {
+			continue
+		}
+
+		body, err := json.Marshal(Payload{
+			Event:     EventPostCreated,
+			WebhookID: hook.ID,
+			Post: PostPayload{
+				ID:          post.ID,
+				Title:       post.Title,
+				URL:         post.URL,
+				Description: post.Description,
+				PublishedAt: post.PublishedAt,
+			},
+			Feed: FeedPayload{
+				ID:   feed.ID,
+				Name: feed.Name,
+				URL:  feed.Url,
+			},
+		})
+		if err != nil {
+			return queued, err
+		}
+
+		_, err = db.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+			WebhookID: hook.ID,
+			PostID:    post.ID,
+			Payload:   string(body),
+		})
+		if err != nil {
+			return queued, fmt.Errorf("failed to queue delivery for webhook %d: %w", hook.ID, err)
+		}
+		queued++
+	}
+
+	return queued, nil
+}
+
+// Matches reports whether hook's keyword filter accepts post. Feed filters
+// are applied by the query that loads the hooks.
+func Matches(hook database.Webhook, post Post) bool {
+	if !hook.Keyword.Valid || hook.Keyword.String == "" {
+		return true
+	}
+	keyword := strings.ToLower(hook.Keyword.String)
+	return strings.Contains(strings.ToLower(post.Title), keyword) ||
+		strings.Contains(strings.ToLower(post.Description), keyword)
+}
+
+type Dispatcher struct {
+	db     database.Querier
+	client *http.Client
+}
+
+func NewDispatcher(db database.Querier) *Dispatcher {
+	return &Dispatcher{
+		db: db,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// DeliverDue sends every pending delivery whose retry time has come and
+// records the outcome. It returns how many were delivered and how many failed.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (delivered, failed int, err error) {
+	for {
+		due, err := d.db.RetrieveDueWebhookDeliveries(ctx, batchSize)
+		if err != nil {
+			return delivered, failed, fmt.Errorf("failed to retrieve due deliveries: %w", err)
+		}
+		if len(due) == 0 {
+			return delivered, failed, nil
+		}
+
+		for _, delivery := range due {
+			statusCode, sendErr := d.send(ctx, delivery)
+
+			params := database.RecordWebhookDeliveryAttemptParams{
+				ID:            delivery.ID,
+				Status:        StatusDelivered,
+				NextAttemptAt: time.Now(),
+			}
+			if statusCode != 0 {
+				params.LastStatusCode = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+			}
+
+			if sendErr != nil {
+				failed++
+				params.Status = StatusPending
+				params.NextAttemptAt = time.Now().Add(backoff(delivery.Attempts))
+				params.LastError = sql.NullString{String: sendErr.Error(), Valid: true}
+				if delivery.Attempts+1 >= MaxAttempts {
+					params.Status = StatusDead
+				}
+			} else {
+				delivered++
+			}
+
+			if err := d.db.RecordWebhookDeliveryAttempt(ctx, params); err != nil {
+				return delivered, failed, fmt.Errorf("failed to record delivery %d: %w", delivery.ID, err)
+			}
+		}
+
+		if len(due) < batchSize {
+			return delivered, failed, nil
+		}
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery database.RetrieveDueWebhookDeliveriesRow) (int, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gator")
+	req.Header.Set("X-Gator-Event", EventPostCreated)
+	req.Header.Set("X-Gator-Delivery", strconv.Itoa(int(delivery.ID)))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// backoff doubles the wait after every failed attempt, starting at a minute.
+func backoff(attempts int32) time.Duration {
+	wait := time.Minute << attempts
+	if attempts >= 16 || wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
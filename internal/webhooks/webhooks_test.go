@@ -0,0 +1,219 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/memstore"
+)
+
+func TestSign(t *testing.T) {
+	got := Sign("key", []byte("The quick brown fox jumps over the lazy dog"))
+	want := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{0, time.Minute},
+		{1, 2 * time.Minute},
+		{5, 32 * time.Minute},
+		{6, time.Hour},
+		{16, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	post := Post{Title: "Go 1.24 released", Description: "With generic type aliases"}
+	tests := []struct {
+		keyword sql.NullString
+		want    bool
+	}{
+		{sql.NullString{}, true},
+		{sql.NullString{Valid: true}, true},
+		{sql.NullString{String: "go 1.24", Valid: true}, true},
+		{sql.NullString{String: "ALIASES", Valid: true}, true},
+		{sql.NullString{String: "rust", Valid: true}, false},
+	}
+	for _, tt := range tests {
+		if got := Matches(database.Webhook{Keyword: tt.keyword}, post); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.keyword.String, got, tt.want)
+		}
+	}
+}
+
+// receiver records the webhook requests it gets and answers each with the
+// next status in statuses, or 200 once they run out.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+// setup queues one delivery of a new post to a webhook pointing at rc.
+func setup(t *testing.T, rc *receiver) (*memstore.Store, uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	db := memstore.New()
+	user, err := db.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), Name: "ann"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, err := db.CreateFeed(ctx, database.CreateFeedParams{Url: "https://example.com/feed", Name: "News", UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.CreateWebhook(ctx, database.CreateWebhookParams{
+		UserID: user.ID,
+		Url:    srv.URL,
+		Secret: "s3cret",
+		FeedID: sql.NullInt32{Int32: feed.ID, Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	postID, err := db.CreatePost(ctx, database.CreatePostParams{Title: "Hello", Url: "https://example.com/hello", FeedID: feed.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queued, err := Enqueue(ctx, db, feed, Post{ID: postID, Title: "Hello", URL: "https://example.com/hello"})
+	if err != nil || queued != 1 {
+		t.Fatalf("Enqueue queued %d: %v", queued, err)
+	}
+	return db, user.ID
+}
+
+func deliveries(t *testing.T, db *memstore.Store, userID uuid.UUID) []database.RetrieveWebhookDeliveriesForUserRow {
+	t.Helper()
+	rows, err := db.RetrieveWebhookDeliveriesForUser(context.Background(), database.RetrieveWebhookDeliveriesForUserParams{UserID: userID, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestDeliverDueSigns(t *testing.T) {
+	rc := &receiver{}
+	db, userID := setup(t, rc)
+
+	delivered, failed, err := NewDispatcher(db).DeliverDue(context.Background())
+	if err != nil || delivered != 1 || failed != 0 {
+		t.Fatalf("DeliverDue = %d delivered, %d failed, %v", delivered, failed, err)
+	}
+	if len(rc.requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(rc.requests))
+	}
+
+	req, body := rc.requests[0], rc.bodies[0]
+	if got, want := req.Header.Get(SignatureHeader), Sign("s3cret", body); got != want {
+		t.Errorf("signature %q, want %q", got, want)
+	}
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Event != EventPostCreated || payload.Post.Title != "Hello" || payload.Feed.Name != "News" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	if rows := deliveries(t, db, userID); rows[0].Status != StatusDelivered {
+		t.Errorf("delivery status %s, want %s", rows[0].Status, StatusDelivered)
+	}
+}
+
+func TestDeliverDueRetries(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusInternalServerError}}
+	db, userID := setup(t, rc)
+	d := NewDispatcher(db)
+	ctx := context.Background()
+
+	start := time.Now()
+	if delivered, failed, err := d.DeliverDue(ctx); err != nil || delivered != 0 || failed != 1 {
+		t.Fatalf("first DeliverDue = %d delivered, %d failed, %v", delivered, failed, err)
+	}
+	row := deliveries(t, db, userID)[0]
+	if row.Status != StatusPending || row.Attempts != 1 || row.LastStatusCode.Int32 != http.StatusInternalServerError {
+		t.Errorf("after a failure: %+v", row)
+	}
+	if wait := row.NextAttemptAt.Sub(start); wait < time.Minute || wait > time.Minute+time.Second {
+		t.Errorf("retry scheduled %v later, want a minute", wait)
+	}
+
+	// Not due yet.
+	if delivered, failed, _ := d.DeliverDue(ctx); delivered+failed != 0 {
+		t.Errorf("delivery retried before its backoff was up")
+	}
+
+	db.SetClock(func() time.Time { return start.Add(2 * time.Minute) })
+	if delivered, failed, err := d.DeliverDue(ctx); err != nil || delivered != 1 || failed != 0 {
+		t.Fatalf("retry = %d delivered, %d failed, %v", delivered, failed, err)
+	}
+	if row := deliveries(t, db, userID)[0]; row.Status != StatusDelivered || row.Attempts != 2 {
+		t.Errorf("after the retry: %+v", row)
+	}
+}
+
+func TestDeliverDueDeadLetters(t *testing.T) {
+	rc := &receiver{}
+	for range MaxAttempts {
+		rc.statuses = append(rc.statuses, http.StatusBadGateway)
+	}
+	db, userID := setup(t, rc)
+	d := NewDispatcher(db)
+
+	now := time.Now()
+	for i := range MaxAttempts {
+		db.SetClock(func() time.Time { return now })
+		if _, failed, err := d.DeliverDue(context.Background()); err != nil || failed != 1 {
+			t.Fatalf("attempt %d: %d failed, %v", i+1, failed, err)
+		}
+		now = now.Add(maxBackoff + time.Minute)
+	}
+
+	row := deliveries(t, db, userID)[0]
+	if row.Status != StatusDead || row.Attempts != MaxAttempts {
+		t.Errorf("after %d failures: %+v", MaxAttempts, row)
+	}
+	db.SetClock(func() time.Time { return now })
+	if delivered, failed, _ := d.DeliverDue(context.Background()); delivered+failed != 0 {
+		t.Error("a dead delivery was retried")
+	}
+}
@@ -1,16 +1,15 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
 	"github.com/sanntintdev/gator/internal/commands"
 	"github.com/sanntintdev/gator/internal/config"
-	"github.com/sanntintdev/gator/internal/database"
-
-	_ "github.com/lib/pq"
+	"github.com/sanntintdev/gator/internal/logging"
 )
 
 func main() {
@@ -19,27 +18,53 @@ func main() {
 		log.Fatalf("Failed to read config: %v", err)
 	}
 
-	db, err := sql.Open("postgres", cfg.Db_url)
+	globalFlags := flag.NewFlagSet("gator", flag.ExitOnError)
+	logLevel := globalFlags.String("log-level", cfg.LogLevel, "log level: debug, info, warn or error")
+	logFormat := globalFlags.String("log-format", cfg.LogFormat, "log format: text or json")
+	demo := globalFlags.Bool("demo", false, "use a throwaway in-memory database with a demo user and sample feeds")
+	globalFlags.Parse(os.Args[1:])
+
+	logger, err := logging.New(os.Stderr, *logLevel, *logFormat)
 	if err != nil {
-		log.Fatalf("Failed to open database connection: %v", err)
+		log.Fatalf("Failed to configure logging: %v", err)
 	}
-	defer db.Close()
 
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+	if *demo {
+		cfg = cfg.ReadOnly()
+		cfg.Db_url = memoryURL
 	}
 
-	dbQueries := database.New(db)
+	store, migrator, closeStore, err := openStore(cfg.Db_url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeStore()
+
+	// The migrate command inspects and changes the schema itself, so it
+	// must run against a database in any state.
+	if migrator != nil && globalFlags.Arg(0) != "migrate" {
+		if err := checkSchema(context.Background(), migrator, cfg.AutoMigrate, logger); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *demo {
+		if err := seedDemo(context.Background(), store, &cfg); err != nil {
+			log.Fatalf("Failed to set up demo data: %v", err)
+		}
+	}
 
 	appState := &commands.State{
-		Db:  dbQueries,
-		Cfg: &cfg,
+		Db:       store,
+		Migrator: migrator,
+		Cfg:      &cfg,
+		Logger:   logger,
 	}
 
 	cmds := commands.NewCommands()
 	cmds.RegisterDefaultCommands()
 
-	err = parsedArgsAndExecute(cmds, appState)
+	err = parsedArgsAndExecute(cmds, appState, globalFlags.Args())
 
 	if err != nil {
 		log.Fatalf("Failed to execute command: %v", err)
@@ -51,19 +76,18 @@ func main() {
 	}
 }
 
-func parsedArgsAndExecute(cmds *commands.Commands, state *commands.State) error {
-	args := os.Args
-	if len(args) < 2 {
+func parsedArgsAndExecute(cmds *commands.Commands, state *commands.State, args []string) error {
+	if len(args) < 1 {
 		return fmt.Errorf("No command provided")
 	}
 
 	cmd := commands.Command{
-		Name: args[1],
+		Name: args[0],
 		Args: []string{},
 	}
 
-	if len(args) > 2 {
-		cmd.Args = args[2:]
+	if len(args) > 1 {
+		cmd.Args = args[1:]
 	}
 
 	return cmds.Run(state, cmd)
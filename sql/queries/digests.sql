@@ -0,0 +1,56 @@
+-- name: UpsertDigestSubscription :one
+INSERT INTO digest_subscriptions (user_id, email, frequency, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET email = EXCLUDED.email, frequency = EXCLUDED.frequency, updated_at = NOW()
+RETURNING *;
+
+-- name: RetrieveDigestSubscription :one
+SELECT * FROM digest_subscriptions
+WHERE user_id = $1;
+
+-- name: RetrieveDigestSubscriptions :many
+SELECT * FROM digest_subscriptions
+ORDER BY created_at;
+
+-- name: DeleteDigestSubscription :execrows
+DELETE FROM digest_subscriptions
+WHERE user_id = $1;
+
+-- name: RecordDigestRun :exec
+UPDATE digest_subscriptions
+SET last_run_at = $2, updated_at = NOW()
+WHERE user_id = $1;
+
+-- name: RetrieveDigestPostsForUser :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.excerpt,
+    p.published_at,
+    p.created_at,
+    p.feed_id,
+    COALESCE(ff.title, f.name) AS feed_name,
+    f.url AS feed_url,
+    ff.priority
+FROM posts p
+INNER JOIN feeds f ON p.feed_id = f.id
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = sqlc.arg('user_id')
+  AND NOT ff.muted
+  AND p.created_at > sqlc.arg('since')
+  AND COALESCE(ps.is_read, FALSE) = FALSE
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+  AND NOT EXISTS (
+      SELECT 1 FROM digest_posts dp
+      WHERE dp.user_id = ff.user_id AND dp.post_id = p.id
+  )
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CreateDigestPost :exec
+INSERT INTO digest_posts (user_id, post_id, sent_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (user_id, post_id) DO NOTHING;
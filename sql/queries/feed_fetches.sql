@@ -0,0 +1,45 @@
+-- name: CreateFeedFetch :exec
+INSERT INTO feed_fetches (feed_id, started_at, finished_at, status_code, bytes, item_count, new_post_count, error, error_kind, redirect_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: RetrieveFeedFetches :many
+SELECT * FROM feed_fetches
+WHERE feed_id = $1
+ORDER BY started_at DESC, id DESC
+LIMIT $2;
+
+-- name: DeleteFeedFetchesBefore :execrows
+DELETE FROM feed_fetches
+WHERE feed_id = $1 AND started_at < $2;
+
+-- name: RetrieveFeedHealth :many
+SELECT
+    f.id,
+    f.name,
+    f.url,
+    f.user_id,
+    f.created_at,
+    f.last_fetched_at,
+    f.dead_at,
+    (SELECT COUNT(*) FROM feed_follows ff WHERE ff.feed_id = f.id)::bigint AS follower_count,
+    COALESCE((SELECT MAX(p.created_at) FROM posts p WHERE p.feed_id = f.id), f.created_at)::timestamp AS last_post_at,
+    (
+        SELECT COUNT(*) FROM feed_fetches x
+        WHERE x.feed_id = f.id
+          AND x.error IS NOT NULL
+          AND x.started_at > COALESCE(
+              (SELECT MAX(ok.started_at) FROM feed_fetches ok WHERE ok.feed_id = f.id AND ok.error IS NULL),
+              '-infinity'::timestamp
+          )
+    )::bigint AS consecutive_failures,
+    last_fetch.error AS last_error,
+    last_fetch.error_kind AS last_error_kind,
+    last_fetch.redirect_url AS last_redirect_url
+FROM feeds f
+LEFT JOIN LATERAL (
+    SELECT error, error_kind, redirect_url FROM feed_fetches
+    WHERE feed_id = f.id
+    ORDER BY started_at DESC, id DESC
+    LIMIT 1
+) last_fetch ON TRUE
+ORDER BY f.id;
@@ -24,12 +24,46 @@ SELECT
     ff.created_at,
     ff.updated_at,
     u.name AS user_name,
-    f.name AS feed_name
+    f.name AS feed_name,
+    ff.folder_id,
+    fo.name AS folder_name,
+    f.url AS feed_url,
+    ff.title,
+    ff.muted,
+    ff.notify,
+    ff.priority
 FROM feed_follows ff
 INNER JOIN users u ON ff.user_id = u.id
 INNER JOIN feeds f ON ff.feed_id = f.id
-WHERE ff.user_id = $1;
+LEFT JOIN folders fo ON ff.folder_id = fo.id
+WHERE ff.user_id = $1
+ORDER BY fo.name NULLS FIRST, ff.priority DESC, COALESCE(ff.title, f.name);
+
+-- name: RetrieveFeedFollow :one
+SELECT * FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2;
 
 -- name: DeleteFeedFollow :exec
 DELETE FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2;
+
+-- name: DeleteFeedFollowsForFeed :exec
+DELETE FROM feed_follows
+WHERE feed_id = $1;
+
+-- name: MoveFeedFollows :exec
+INSERT INTO feed_follows (user_id, feed_id, folder_id, title, muted, notify, priority, created_at, updated_at)
+SELECT user_id, sqlc.arg('to_feed_id'), folder_id, title, muted, notify, priority, created_at, NOW()
+FROM feed_follows
+WHERE feed_id = sqlc.arg('from_feed_id')
+ON CONFLICT (user_id, feed_id) DO NOTHING;
+
+-- name: SetFeedFollowFolder :execrows
+UPDATE feed_follows
+SET folder_id = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: UpdateFeedFollowSettings :exec
+UPDATE feed_follows
+SET title = $3, muted = $4, notify = $5, priority = $6, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2;
@@ -0,0 +1,45 @@
+-- name: CreateFilterRule :one
+INSERT INTO filter_rules (user_id, feed_id, field, match_type, pattern, action, tag, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+RETURNING *;
+
+-- name: RetrieveFilterRulesForUser :many
+SELECT * FROM filter_rules
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: DeleteFilterRule :execrows
+DELETE FROM filter_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: RetrieveFilterRulesForFeed :many
+SELECT r.* FROM filter_rules r
+WHERE (r.feed_id = $1 OR r.feed_id IS NULL)
+  AND EXISTS (
+      SELECT 1 FROM feed_follows ff
+      WHERE ff.user_id = r.user_id AND ff.feed_id = $1
+  )
+ORDER BY r.id;
+
+-- name: MoveFilterRulesToFeed :exec
+UPDATE filter_rules
+SET feed_id = sqlc.arg('to_feed_id'), updated_at = NOW()
+WHERE feed_id = sqlc.arg('from_feed_id');
+
+-- name: RetrieveFilterablePostsForUser :many
+SELECT p.id, p.feed_id, p.title, p.description, p.author
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('feed_id')::integer IS NULL OR p.feed_id = sqlc.narg('feed_id'))
+ORDER BY p.id;
+
+-- name: CreatePostTag :exec
+INSERT INTO post_tags (user_id, post_id, name)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: RetrieveTagsForPosts :many
+SELECT post_id, name FROM post_tags
+WHERE user_id = sqlc.arg('user_id') AND post_id = ANY(sqlc.arg('post_ids')::integer[])
+ORDER BY post_id, name;
@@ -0,0 +1,161 @@
+-- name: SetPostRead :exec
+INSERT INTO post_states (user_id, post_id, is_read, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET is_read = EXCLUDED.is_read, updated_at = NOW();
+
+-- name: SetPostStarred :exec
+INSERT INTO post_states (user_id, post_id, is_starred, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET is_starred = EXCLUDED.is_starred, updated_at = NOW();
+
+-- name: SetPostHidden :exec
+INSERT INTO post_states (user_id, post_id, is_hidden, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET is_hidden = EXCLUDED.is_hidden, updated_at = NOW();
+
+-- name: MarkPostsReadForUser :exec
+INSERT INTO post_states (user_id, post_id, is_read, created_at, updated_at)
+SELECT ff.user_id, p.id, TRUE, NOW(), NOW()
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('feed_id')::integer IS NULL OR p.feed_id = sqlc.narg('feed_id'))
+  AND (sqlc.narg('folder_id')::integer IS NULL OR ff.folder_id = sqlc.narg('folder_id'))
+  AND p.created_at <= sqlc.arg('before')
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET is_read = TRUE, updated_at = NOW();
+
+-- name: CountUnreadPostsForUser :many
+SELECT
+    p.feed_id,
+    ff.folder_id,
+    ff.muted,
+    COUNT(*) AS unread_count,
+    MAX(p.created_at)::timestamp AS newest_created_at
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = $1
+  AND COALESCE(ps.is_read, FALSE) = FALSE
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+GROUP BY p.feed_id, ff.folder_id, ff.muted;
+
+-- name: RetrieveStreamPostsForUser :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.created_at,
+    p.updated_at,
+    p.author,
+    COALESCE(ff.title, f.name) AS feed_name,
+    f.url AS feed_url,
+    COALESCE(ps.is_read, FALSE)::boolean AS is_read,
+    COALESCE(ps.is_starred, FALSE)::boolean AS is_starred
+FROM posts p
+INNER JOIN feeds f ON p.feed_id = f.id
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('feed_id')::integer IS NULL OR p.feed_id = sqlc.narg('feed_id'))
+  AND (sqlc.narg('folder_id')::integer IS NULL OR ff.folder_id = sqlc.narg('folder_id'))
+  AND (sqlc.narg('feed_id')::integer IS NOT NULL OR NOT ff.muted)
+  AND (NOT sqlc.arg('unread_only')::boolean OR COALESCE(ps.is_read, FALSE) = FALSE)
+  AND (NOT sqlc.arg('starred_only')::boolean OR COALESCE(ps.is_starred, FALSE) = TRUE)
+  AND (sqlc.narg('newer_than')::timestamp IS NULL OR p.created_at > sqlc.narg('newer_than'))
+  AND (sqlc.narg('older_than')::timestamp IS NULL OR p.created_at < sqlc.narg('older_than'))
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: RetrievePostsForUserByIDs :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.created_at,
+    p.updated_at,
+    p.author,
+    COALESCE(ff.title, f.name) AS feed_name,
+    f.url AS feed_url,
+    COALESCE(ps.is_read, FALSE)::boolean AS is_read,
+    COALESCE(ps.is_starred, FALSE)::boolean AS is_starred
+FROM posts p
+INNER JOIN feeds f ON p.feed_id = f.id
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id AND ff.user_id = sqlc.arg('user_id')
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = sqlc.arg('user_id')
+WHERE p.id = ANY(sqlc.arg('ids')::integer[])
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+ORDER BY p.created_at DESC, p.id DESC;
+
+-- name: RetrievePostsForUserSinceID :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.created_at,
+    p.updated_at,
+    p.author,
+    COALESCE(ps.is_read, FALSE)::boolean AS is_read,
+    COALESCE(ps.is_starred, FALSE)::boolean AS is_starred
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = $1 AND p.id > $2 AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+ORDER BY p.id ASC
+LIMIT $3;
+
+-- name: RetrievePostsForUserBeforeID :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.created_at,
+    p.updated_at,
+    p.author,
+    COALESCE(ps.is_read, FALSE)::boolean AS is_read,
+    COALESCE(ps.is_starred, FALSE)::boolean AS is_starred
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = $1 AND p.id < $2 AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+ORDER BY p.id DESC
+LIMIT $3;
+
+-- name: CountPostsForUser :one
+SELECT COUNT(*) FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = $1 AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE;
+
+-- name: RetrieveUnreadPostIDsForUser :many
+SELECT p.id FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = $1 AND NOT ff.muted AND COALESCE(ps.is_read, FALSE) = FALSE
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+ORDER BY p.id;
+
+-- name: RetrieveStarredPostIDsForUser :many
+SELECT post_id FROM post_states
+WHERE user_id = $1 AND is_starred = TRUE
+ORDER BY post_id;
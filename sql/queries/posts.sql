@@ -1,10 +1,86 @@
 -- name: CreatePost :one
-INSERT INTO posts (title, url, description, published_at, feed_id, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, Now(), Now())
+INSERT INTO posts (title, url, description, published_at, feed_id, date_flag, excerpt, author, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, Now(), Now())
 RETURNING id;
 
 -- name: RetrievePostsForUser :many
-SELECT id, title, url, description, published_at, feed_id, created_at, updated_at
-FROM posts
-ORDER BY published_at DESC
-LIMIT $1;
+SELECT p.id, p.title, p.url, p.description, p.published_at, p.feed_id, p.created_at, p.updated_at, p.date_flag, p.excerpt, p.full_content, p.author
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.user_id = sqlc.arg('user_id') AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+  AND (NOT sqlc.arg('unread_only')::boolean OR COALESCE(ps.is_read, FALSE) = FALSE)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: RetrievePostsInFolder :many
+SELECT p.id, p.title, p.url, p.description, p.published_at, p.feed_id, p.created_at, p.updated_at, p.date_flag, p.excerpt, p.full_content, p.author
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+LEFT JOIN post_states ps ON ps.post_id = p.id AND ps.user_id = ff.user_id
+WHERE ff.folder_id = sqlc.arg('folder_id') AND NOT ff.muted
+  AND COALESCE(ps.is_hidden, FALSE) = FALSE
+  AND (NOT sqlc.arg('unread_only')::boolean OR COALESCE(ps.is_read, FALSE) = FALSE)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CreatePostCategory :exec
+INSERT INTO post_categories (post_id, name)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RetrieveCategoriesForPosts :many
+SELECT post_id, name FROM post_categories
+WHERE post_id = ANY(sqlc.arg('post_ids')::integer[])
+ORDER BY post_id, name;
+
+-- name: RetrieveTimelineForUser :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.created_at,
+    p.updated_at,
+    COALESCE(ff.title, f.name) AS feed_name,
+    f.url AS feed_url
+FROM posts p
+INNER JOIN feeds f ON p.feed_id = f.id
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = sqlc.arg('user_id')
+  AND NOT ff.muted
+  AND (sqlc.narg('category')::text IS NULL OR EXISTS (
+      SELECT 1 FROM post_categories pc
+      WHERE pc.post_id = p.id AND LOWER(pc.name) = LOWER(sqlc.narg('category'))
+  ))
+  AND (sqlc.narg('keyword')::text IS NULL
+      OR p.title ILIKE '%' || sqlc.narg('keyword') || '%' ESCAPE '\'
+      OR p.description ILIKE '%' || sqlc.narg('keyword') || '%' ESCAPE '\'
+      OR p.full_content ILIKE '%' || sqlc.narg('keyword') || '%' ESCAPE '\')
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+      SELECT 1 FROM post_tags pt
+      WHERE pt.post_id = p.id AND pt.user_id = ff.user_id AND pt.name = sqlc.narg('tag')
+  ))
+  AND NOT EXISTS (
+      SELECT 1 FROM post_states ps
+      WHERE ps.post_id = p.id AND ps.user_id = ff.user_id AND ps.is_hidden
+  )
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: DeletePostsForFeed :exec
+DELETE FROM posts
+WHERE feed_id = $1;
+
+-- name: MovePostsToFeed :exec
+UPDATE posts
+SET feed_id = sqlc.arg('to_feed_id'), updated_at = NOW()
+WHERE feed_id = sqlc.arg('from_feed_id');
+
+-- name: SetPostFullContent :exec
+UPDATE posts
+SET full_content = $2, updated_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,91 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (user_id, url, secret, feed_id, keyword, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+RETURNING *;
+
+-- name: RetrieveWebhooksForUser :many
+SELECT * FROM webhooks
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+-- name: RetrieveWebhooksForFeed :many
+SELECT w.* FROM webhooks w
+WHERE w.feed_id = $1
+   OR (w.feed_id IS NULL AND EXISTS (
+       SELECT 1 FROM feed_follows ff
+       WHERE ff.user_id = w.user_id AND ff.feed_id = $1 AND ff.notify = 'all'
+   ))
+ORDER BY w.id;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (webhook_id, post_id, payload, status, attempts, next_attempt_at, created_at, updated_at)
+VALUES ($1, $2, $3, 'pending', 0, NOW(), NOW(), NOW())
+RETURNING *;
+
+-- name: RetrieveDueWebhookDeliveries :many
+SELECT
+    d.id,
+    d.webhook_id,
+    d.post_id,
+    d.payload,
+    d.attempts,
+    w.url,
+    w.secret
+FROM webhook_deliveries d
+INNER JOIN webhooks w ON d.webhook_id = w.id
+WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+ORDER BY d.next_attempt_at, d.id
+LIMIT $1;
+
+-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    next_attempt_at = $3,
+    last_status_code = $4,
+    last_error = $5,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: RetrieveWebhookDeliveriesForUser :many
+SELECT
+    d.id,
+    d.webhook_id,
+    d.post_id,
+    d.status,
+    d.attempts,
+    d.next_attempt_at,
+    d.last_status_code,
+    d.last_error,
+    d.created_at,
+    d.updated_at,
+    w.url AS webhook_url,
+    p.title AS post_title
+FROM webhook_deliveries d
+INNER JOIN webhooks w ON d.webhook_id = w.id
+INNER JOIN posts p ON d.post_id = p.id
+WHERE w.user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('status')::text IS NULL OR d.status = sqlc.narg('status'))
+ORDER BY d.created_at DESC, d.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ReplayWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = NOW(), last_error = NULL, updated_at = NOW()
+WHERE id = $1
+  AND webhook_id IN (SELECT id FROM webhooks WHERE user_id = $2);
+
+-- name: ReplayDeadWebhookDeliveriesForUser :execrows
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = NOW(), last_error = NULL, updated_at = NOW()
+WHERE status = 'dead'
+  AND webhook_id IN (SELECT id FROM webhooks WHERE user_id = $1);
+
+-- name: MoveWebhooksToFeed :exec
+UPDATE webhooks
+SET feed_id = sqlc.arg('to_feed_id'), updated_at = NOW()
+WHERE feed_id = sqlc.arg('from_feed_id');
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE user_credentials ADD COLUMN fever_api_key TEXT UNIQUE NULL;
+
+-- +goose Down
+ALTER TABLE user_credentials DROP COLUMN fever_api_key;
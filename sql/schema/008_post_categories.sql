@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE post_categories (
+    post_id INTEGER NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    PRIMARY KEY (post_id, name)
+);
+
+-- +goose Down
+DROP TABLE post_categories;
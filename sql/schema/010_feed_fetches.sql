@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE feed_fetches (
+    id SERIAL PRIMARY KEY,
+    feed_id INTEGER NOT NULL REFERENCES feeds (id) ON DELETE CASCADE,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    status_code INTEGER NULL,
+    bytes INTEGER NOT NULL DEFAULT 0,
+    item_count INTEGER NOT NULL DEFAULT 0,
+    new_post_count INTEGER NOT NULL DEFAULT 0,
+    error TEXT NULL
+);
+
+CREATE INDEX feed_fetches_feed_id_started_at_idx ON feed_fetches (feed_id, started_at);
+
+-- +goose Down
+DROP TABLE feed_fetches;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feed_fetches ADD COLUMN error_kind TEXT NULL;
+ALTER TABLE feed_fetches ADD COLUMN redirect_url TEXT NULL;
+
+-- +goose Down
+ALTER TABLE feed_fetches DROP COLUMN redirect_url;
+ALTER TABLE feed_fetches DROP COLUMN error_kind;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN date_flag TEXT NULL;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN date_flag;
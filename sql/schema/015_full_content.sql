@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE posts ADD COLUMN full_content TEXT NULL;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN full_content;
+ALTER TABLE feeds DROP COLUMN fetch_full_content;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN kind TEXT NOT NULL DEFAULT 'rss';
+ALTER TABLE feeds ADD COLUMN scrape_rules TEXT NULL;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN scrape_rules;
+ALTER TABLE feeds DROP COLUMN kind;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE feed_follows
+    DROP CONSTRAINT feed_follows_feed_id_fkey,
+    ADD CONSTRAINT feed_follows_feed_id_fkey FOREIGN KEY (feed_id) REFERENCES feeds (id) ON DELETE CASCADE;
+ALTER TABLE posts
+    DROP CONSTRAINT posts_feed_id_fkey,
+    ADD CONSTRAINT posts_feed_id_fkey FOREIGN KEY (feed_id) REFERENCES feeds (id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE posts
+    DROP CONSTRAINT posts_feed_id_fkey,
+    ADD CONSTRAINT posts_feed_id_fkey FOREIGN KEY (feed_id) REFERENCES feeds (id);
+ALTER TABLE feed_follows
+    DROP CONSTRAINT feed_follows_feed_id_fkey,
+    ADD CONSTRAINT feed_follows_feed_id_fkey FOREIGN KEY (feed_id) REFERENCES feeds (id);
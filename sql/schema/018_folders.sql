@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE folders (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name)
+);
+
+ALTER TABLE feed_follows ADD COLUMN folder_id INTEGER NULL REFERENCES folders (id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE feed_follows DROP COLUMN folder_id;
+DROP TABLE folders;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feed_follows
+    ADD COLUMN title TEXT NULL,
+    ADD COLUMN muted BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN notify TEXT NOT NULL DEFAULT 'all' CHECK (notify IN ('all', 'none')),
+    ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE feed_follows
+    DROP COLUMN priority,
+    DROP COLUMN notify,
+    DROP COLUMN muted,
+    DROP COLUMN title;
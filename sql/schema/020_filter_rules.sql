@@ -0,0 +1,30 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN author TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE post_states ADD COLUMN is_hidden BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE filter_rules (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    feed_id INTEGER NULL REFERENCES feeds (id) ON DELETE CASCADE,
+    field TEXT NOT NULL CHECK (field IN ('any', 'title', 'description', 'author', 'category')),
+    match_type TEXT NOT NULL CHECK (match_type IN ('contains', 'regex', 'expr')),
+    pattern TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('hide', 'read', 'star', 'tag')),
+    tag TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE post_tags (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    PRIMARY KEY (user_id, post_id, name)
+);
+
+-- +goose Down
+DROP TABLE post_tags;
+DROP TABLE filter_rules;
+ALTER TABLE post_states DROP COLUMN is_hidden;
+ALTER TABLE posts DROP COLUMN author;
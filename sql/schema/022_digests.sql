@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE digest_subscriptions (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    frequency TEXT NOT NULL DEFAULT 'daily' CHECK (frequency IN ('daily', 'weekly')),
+    last_run_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE digest_posts (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- +goose Down
+DROP TABLE digest_posts;
+DROP TABLE digest_subscriptions;
@@ -0,0 +1,7 @@
+// Package schema embeds the goose migrations that build the Postgres schema.
+package schema
+
+import "embed"
+
+//go:embed *.sql
+var Migrations embed.FS
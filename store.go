@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sanntintdev/gator/internal/config"
+	"github.com/sanntintdev/gator/internal/database"
+	"github.com/sanntintdev/gator/internal/memstore"
+	"github.com/sanntintdev/gator/internal/migrate"
+	"github.com/sanntintdev/gator/internal/sqlitestore"
+	"github.com/sanntintdev/gator/sql/schema"
+
+	_ "github.com/lib/pq"
+)
+
+// memoryURL selects the in-memory store, which keeps nothing once the
+// process exits.
+const memoryURL = "memory://"
+
+// openStore connects to the database named by dbURL and returns it with its
+// migrator and a function that closes it. The scheme picks the backend:
+// memory: for the in-memory store, which has no migrator, sqlite: followed
+// by a file path for SQLite, and anything else is treated as a Postgres
+// connection string.
+func openStore(dbURL string) (database.Store, *migrate.Migrator, func() error, error) {
+	switch {
+	case strings.HasPrefix(dbURL, "memory:"):
+		return memstore.New(), nil, func() error { return nil }, nil
+	case strings.HasPrefix(dbURL, "sqlite:"):
+		path := strings.TrimPrefix(strings.TrimPrefix(dbURL, "sqlite:"), "//")
+		if path == "" {
+			return nil, nil, nil, fmt.Errorf("Missing database file in %q", dbURL)
+		}
+		store, err := sqlitestore.Open(path)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Failed to open SQLite database: %w", err)
+		}
+		migrator, err := migrate.New(store.DB(), sqlitestore.Migrations, migrate.SQLite)
+		if err != nil {
+			store.Close()
+			return nil, nil, nil, fmt.Errorf("Failed to load migrations: %w", err)
+		}
+		return store, migrator, store.Close, nil
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Failed to open database connection: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, nil, nil, fmt.Errorf("Failed to ping database: %w", err)
+	}
+	migrator, err := migrate.New(db, schema.Migrations, migrate.Postgres)
+	if err != nil {
+		db.Close()
+		return nil, nil, nil, fmt.Errorf("Failed to load migrations: %w", err)
+	}
+	return database.NewSQLStore(db), migrator, db.Close, nil
+}
+
+// checkSchema makes sure the database has every migration this binary
+// knows about. A database without any is set up from scratch; otherwise
+// pending migrations are applied only when autoMigrate is set, so that
+// upgrading gator never changes a shared database unannounced.
+func checkSchema(ctx context.Context, migrator *migrate.Migrator, autoMigrate bool, logger *slog.Logger) error {
+	current, latest, err := migrator.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to read schema version: %w", err)
+	}
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to read schema version: %w", err)
+	}
+
+	if current > latest {
+		logger.Warn("database schema is newer than this version of gator", "version", current, "latest_known", latest)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if current != 0 && !autoMigrate {
+		return fmt.Errorf("Database schema is at version %d but this gator needs version %d. Run `gator migrate up`, or set \"auto_migrate\": true in the config file", current, latest)
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		logger.Info("applied migration", "version", m.Version, "name", m.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+// demoFeeds are followed by the demo user.
+var demoFeeds = []struct {
+	name string
+	url  string
+}{
+	{"Hacker News", "https://news.ycombinator.com/rss"},
+	{"Boot.dev Blog", "https://blog.boot.dev/index.xml"},
+	{"The Go Blog", "https://go.dev/blog/feed.atom"},
+}
+
+// seedDemo creates a demo user following a few feeds and logs it in for
+// this run only.
+func seedDemo(ctx context.Context, store database.Store, cfg *config.Config) error {
+	return store.InTx(ctx, func(q database.Querier) error {
+		now := time.Now()
+		user, err := q.CreateUser(ctx, database.CreateUserParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Name:      "demo",
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, f := range demoFeeds {
+			feed, err := q.CreateFeed(ctx, database.CreateFeedParams{
+				Url:       f.url,
+				Name:      f.name,
+				UserID:    user.ID,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+			if err != nil {
+				return err
+			}
+			_, err = q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+				UserID: user.ID,
+				FeedID: feed.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return cfg.SetUser(user.Name)
+	})
+}